@@ -0,0 +1,323 @@
+package acltest
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// evaluator returns an evaluator over the tuples of a request, with the
+// read lock held by the caller.
+func (s *Server) evaluator(storeID, modelID string, contextual []*openfga.TupleKey, reqContext *structpb.Struct) (*evaluator, error) {
+	st, model, err := s.lookup(storeID, modelID)
+	if err != nil {
+		return nil, err
+	}
+	return newEvaluator(model, st.tuples, contextual, reqContext), nil
+}
+
+// Check implements openfga.OpenFGAServiceClient.
+func (s *Server) Check(_ context.Context, in *openfga.CheckRequest, _ ...grpc.CallOption) (*openfga.CheckResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, err := s.evaluator(in.GetStoreId(), in.GetAuthorizationModelId(), in.GetContextualTuples().GetTupleKeys(), in.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	key := in.GetTupleKey()
+	allowed, err := e.check(key.GetUser(), key.GetObject(), key.GetRelation(), 0)
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.CheckResponse{Allowed: allowed}, nil
+}
+
+// BatchCheck implements openfga.OpenFGAServiceClient. Errors of single
+// checks are reported in their result.
+func (s *Server) BatchCheck(_ context.Context, in *openfga.BatchCheckRequest, _ ...grpc.CallOption) (*openfga.BatchCheckResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, model, err := s.lookup(in.GetStoreId(), in.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*openfga.BatchCheckSingleResult, len(in.GetChecks()))
+	for _, item := range in.GetChecks() {
+		if _, dup := result[item.GetCorrelationId()]; dup {
+			return nil, fgaError(openfga.ErrorCode_validation_error, "duplicate correlation ID %s", item.GetCorrelationId())
+		}
+
+		e := newEvaluator(model, st.tuples, item.GetContextualTuples().GetTupleKeys(), item.GetContext())
+		key := item.GetTupleKey()
+		allowed, err := e.check(key.GetUser(), key.GetObject(), key.GetRelation(), 0)
+		if err != nil {
+			result[item.GetCorrelationId()] = &openfga.BatchCheckSingleResult{CheckResult: &openfga.BatchCheckSingleResult_Error{Error: &openfga.CheckError{
+				Code:    &openfga.CheckError_InputError{InputError: openfga.ErrorCode(status.Code(err))},
+				Message: status.Convert(err).Message(),
+			}}}
+			continue
+		}
+		result[item.GetCorrelationId()] = &openfga.BatchCheckSingleResult{CheckResult: &openfga.BatchCheckSingleResult_Allowed{Allowed: allowed}}
+	}
+	return &openfga.BatchCheckResponse{Result: result}, nil
+}
+
+// ListObjects implements openfga.OpenFGAServiceClient.
+func (s *Server) ListObjects(_ context.Context, in *openfga.ListObjectsRequest, _ ...grpc.CallOption) (*openfga.ListObjectsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, err := s.evaluator(in.GetStoreId(), in.GetAuthorizationModelId(), in.GetContextualTuples().GetTupleKeys(), in.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	objects, err := e.listObjects(in.GetType(), in.GetRelation(), in.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.ListObjectsResponse{Objects: objects}, nil
+}
+
+// StreamedListObjects implements openfga.OpenFGAServiceClient. The
+// objects are resolved when the stream is opened.
+func (s *Server) StreamedListObjects(ctx context.Context, in *openfga.StreamedListObjectsRequest, _ ...grpc.CallOption) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, err := s.evaluator(in.GetStoreId(), in.GetAuthorizationModelId(), in.GetContextualTuples().GetTupleKeys(), in.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	objects, err := e.listObjects(in.GetType(), in.GetRelation(), in.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	return &listObjectsStream{ctx: ctx, objects: objects}, nil
+}
+
+// listObjects checks every object of the type found in the tuples.
+func (e *evaluator) listObjects(objectType, relation, user string) ([]string, error) {
+	if _, err := e.rewrite(objectType, relation); err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, tuples := range e.tuples {
+		for _, t := range tuples {
+			for _, object := range []string{t.GetObject(), t.GetUser()} {
+				object, _, _ = strings.Cut(object, "#")
+				if typeOf(object) == objectType && !isWildcard(object) {
+					candidates = append(candidates, object)
+				}
+			}
+		}
+	}
+	slices.Sort(candidates)
+	candidates = slices.Compact(candidates)
+
+	var objects []string
+	for _, object := range candidates {
+		ok, err := e.check(user, object, relation, 0)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// listObjectsStream serves the objects of StreamedListObjects one by
+// one, then io.EOF.
+type listObjectsStream struct {
+	ctx     context.Context
+	objects []string
+}
+
+func (l *listObjectsStream) Recv() (*openfga.StreamedListObjectsResponse, error) {
+	if err := l.ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	if len(l.objects) == 0 {
+		return nil, io.EOF
+	}
+	object := l.objects[0]
+	l.objects = l.objects[1:]
+	return &openfga.StreamedListObjectsResponse{Object: object}, nil
+}
+
+func (l *listObjectsStream) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (l *listObjectsStream) Trailer() metadata.MD         { return metadata.MD{} }
+func (l *listObjectsStream) CloseSend() error             { return nil }
+func (l *listObjectsStream) Context() context.Context     { return l.ctx }
+func (l *listObjectsStream) SendMsg(any) error            { return nil }
+func (l *listObjectsStream) RecvMsg(any) error            { return io.EOF }
+
+// ListUsers implements openfga.OpenFGAServiceClient. As in OpenFGA,
+// users granted through a wildcard are reported as the wildcard only.
+func (s *Server) ListUsers(_ context.Context, in *openfga.ListUsersRequest, _ ...grpc.CallOption) (*openfga.ListUsersResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, err := s.evaluator(in.GetStoreId(), in.GetAuthorizationModelId(), in.GetContextualTuples(), in.GetContext())
+	if err != nil {
+		return nil, err
+	}
+	if len(in.GetUserFilters()) != 1 {
+		return nil, fgaError(openfga.ErrorCode_validation_error, "exactly one user filter is required")
+	}
+	filter := in.GetUserFilters()[0]
+	object := in.GetObject().GetType() + ":" + in.GetObject().GetId()
+	if _, err := e.rewrite(in.GetObject().GetType(), in.GetRelation()); err != nil {
+		return nil, err
+	}
+	if _, ok := e.types[filter.GetType()]; !ok {
+		return nil, fgaError(openfga.ErrorCode_type_not_found, "type '%s' not found", filter.GetType())
+	}
+
+	var candidates []string
+	for _, tuples := range e.tuples {
+		for _, t := range tuples {
+			for _, user := range []string{t.GetObject(), t.GetUser()} {
+				if typeOf(user) != filter.GetType() {
+					continue
+				}
+				base, _, _ := strings.Cut(user, "#")
+				switch {
+				case filter.GetRelation() != "":
+					if !isWildcard(base) {
+						candidates = append(candidates, base+"#"+filter.GetRelation())
+					}
+				case base == user:
+					candidates = append(candidates, user)
+				}
+			}
+		}
+	}
+	slices.Sort(candidates)
+	candidates = slices.Compact(candidates)
+
+	var users []*openfga.User
+	wildcard := filter.GetType() + ":*"
+	if filter.GetRelation() == "" {
+		public, err := e.check(wildcard, object, in.GetRelation(), 0)
+		if err != nil {
+			return nil, err
+		}
+		if public {
+			users = append(users, &openfga.User{User: &openfga.User_Wildcard{Wildcard: &openfga.TypedWildcard{Type: filter.GetType()}}})
+		}
+	}
+
+	e.noWildcards = true
+	for _, user := range candidates {
+		if user == wildcard {
+			continue
+		}
+		ok, err := e.check(user, object, in.GetRelation(), 0)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		typ, id, _ := strings.Cut(user, ":")
+		if id, relation, ok := strings.Cut(id, "#"); ok {
+			users = append(users, &openfga.User{User: &openfga.User_Userset{Userset: &openfga.UsersetUser{Type: typ, Id: id, Relation: relation}}})
+			continue
+		}
+		users = append(users, &openfga.User{User: &openfga.User_Object{Object: &openfga.Object{Type: typ, Id: id}}})
+	}
+	return &openfga.ListUsersResponse{Users: users}, nil
+}
+
+// Expand implements openfga.OpenFGAServiceClient. As in OpenFGA, only
+// the rewrite of the relation is expanded: usersets, computed relations
+// and tuple-to-userset parents are returned as leaves for the caller to
+// expand in turn, and conditions are ignored.
+func (s *Server) Expand(_ context.Context, in *openfga.ExpandRequest, _ ...grpc.CallOption) (*openfga.ExpandResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, err := s.evaluator(in.GetStoreId(), in.GetAuthorizationModelId(), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	object, relation := in.GetTupleKey().GetObject(), in.GetTupleKey().GetRelation()
+	rw, err := e.rewrite(typeOf(object), relation)
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.ExpandResponse{Tree: &openfga.UsersetTree{Root: e.expand(object, relation, rw)}}, nil
+}
+
+// expand returns the Expand node of a rewrite of object#relation.
+func (e *evaluator) expand(object, relation string, rw *openfga.Userset) *openfga.UsersetTree_Node {
+	node := &openfga.UsersetTree_Node{Name: object + "#" + relation}
+	leaf := func(l *openfga.UsersetTree_Leaf) *openfga.UsersetTree_Node {
+		node.Value = &openfga.UsersetTree_Node_Leaf{Leaf: l}
+		return node
+	}
+	nodes := func(children []*openfga.Userset) *openfga.UsersetTree_Nodes {
+		out := &openfga.UsersetTree_Nodes{}
+		for _, child := range children {
+			out.Nodes = append(out.Nodes, e.expand(object, relation, child))
+		}
+		return out
+	}
+
+	switch {
+	case rw.GetThis() != nil:
+		users := []string{}
+		for _, t := range e.tuples[object+"#"+relation] {
+			users = append(users, t.GetUser())
+		}
+		return leaf(&openfga.UsersetTree_Leaf{Value: &openfga.UsersetTree_Leaf_Users{Users: &openfga.UsersetTree_Users{Users: users}}})
+
+	case rw.GetComputedUserset() != nil:
+		return leaf(&openfga.UsersetTree_Leaf{Value: &openfga.UsersetTree_Leaf_Computed{Computed: &openfga.UsersetTree_Computed{
+			Userset: object + "#" + rw.GetComputedUserset().GetRelation(),
+		}}})
+
+	case rw.GetTupleToUserset() != nil:
+		ttu := rw.GetTupleToUserset()
+		computed := ttu.GetComputedUserset().GetRelation()
+		out := &openfga.UsersetTree_TupleToUserset{Tupleset: object + "#" + ttu.GetTupleset().GetRelation()}
+		for _, t := range e.tuples[out.Tupleset] {
+			parent := t.GetUser()
+			if strings.Contains(parent, "#") {
+				continue
+			}
+			if _, err := e.rewrite(typeOf(parent), computed); err != nil {
+				continue
+			}
+			out.Computed = append(out.Computed, &openfga.UsersetTree_Computed{Userset: parent + "#" + computed})
+		}
+		return leaf(&openfga.UsersetTree_Leaf{Value: &openfga.UsersetTree_Leaf_TupleToUserset{TupleToUserset: out}})
+
+	case rw.GetUnion() != nil:
+		node.Value = &openfga.UsersetTree_Node_Union{Union: nodes(rw.GetUnion().GetChild())}
+
+	case rw.GetIntersection() != nil:
+		node.Value = &openfga.UsersetTree_Node_Intersection{Intersection: nodes(rw.GetIntersection().GetChild())}
+
+	case rw.GetDifference() != nil:
+		node.Value = &openfga.UsersetTree_Node_Difference{Difference: &openfga.UsersetTree_Difference{
+			Base:     e.expand(object, relation, rw.GetDifference().GetBase()),
+			Subtract: e.expand(object, relation, rw.GetDifference().GetSubtract()),
+		}}
+	}
+	return node
+}
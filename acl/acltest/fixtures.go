@@ -0,0 +1,76 @@
+package acltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+)
+
+// NewFromFiles returns a Server whose default store holds the model of
+// modelPath (see acl.LoadAuthorizationModel) and the tuples of
+// tuplesPath (see LoadTuplesFile). tuplesPath may be empty.
+func NewFromFiles(modelPath, tuplesPath string) (*Server, error) {
+	model, err := acl.LoadAuthorizationModel(modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := New()
+	s.AddModel(model)
+	if tuplesPath == "" {
+		return s, nil
+	}
+
+	tuples, err := LoadTuplesFile(tuplesPath)
+	if err != nil {
+		return nil, err
+	}
+	// Fixtures are validated like writes, without the per-request limit.
+	e := newEvaluator(model, nil, nil, nil)
+	for _, t := range tuples {
+		if err := e.validate(t); err != nil {
+			return nil, fmt.Errorf("invalid tuple in %s: %w", tuplesPath, err)
+		}
+	}
+	s.AddTuples(tuples...)
+	return s, nil
+}
+
+// tupleFixture is an entry of a tuple fixture file.
+type tupleFixture struct {
+	User     string `json:"user"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
+
+// LoadTuplesFile reads tuples from a JSON file holding an array of
+// objects with the user, relation and object fields, e.g.
+//
+//	[
+//	  {"user": "user:alice", "relation": "owner", "object": "pipeline:p1"},
+//	  {"user": "user:*", "relation": "executor", "object": "pipeline:p1"}
+//	]
+func LoadTuplesFile(path string) ([]*openfga.TupleKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	var fixtures []tupleFixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse tuples %s: %w", path, err)
+	}
+
+	tuples := make([]*openfga.TupleKey, 0, len(fixtures))
+	for i, f := range fixtures {
+		if f.User == "" || f.Relation == "" || f.Object == "" {
+			return nil, fmt.Errorf("tuple %d of %s: user, relation and object are required", i, path)
+		}
+		tuples = append(tuples, &openfga.TupleKey{User: f.User, Relation: f.Relation, Object: f.Object})
+	}
+	return tuples, nil
+}
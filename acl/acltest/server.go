@@ -0,0 +1,350 @@
+// Package acltest provides an in-memory OpenFGA fake to test
+// authorization end-to-end without a live OpenFGA server, including
+// acl.ACLClient.ExplainPermission through Expand. Assertions,
+// ReadChanges and store updates and deletions are not supported.
+package acltest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+)
+
+// DefaultStoreName is the name of the store created by New.
+const DefaultStoreName = "acltest"
+
+// Server is an in-memory implementation of openfga.OpenFGAServiceClient.
+// It keeps stores, authorization models and tuples in memory and
+// evaluates Check, ListObjects, ListUsers and Expand against them,
+// supporting direct relations (including wildcards and usersets),
+// computed relations, `X from Y`, and the or, and and but not
+// operators. The not_expired condition written by acl.GrantUntil is
+// evaluated; other conditions are rejected.
+//
+// Assertions, ReadChanges and store updates and deletions are not
+// implemented and return codes.Unimplemented. Consistency
+// preferences are ignored: every read sees every write.
+//
+// A Server is safe for concurrent use.
+type Server struct {
+	mu      sync.RWMutex
+	stores  map[string]*store
+	order   []string
+	storeID string
+}
+
+type store struct {
+	id        string
+	name      string
+	createdAt time.Time
+	// models are in write order; the last one is the latest.
+	models []*openfga.AuthorizationModel
+	tuples []*openfga.TupleKey
+}
+
+var _ openfga.OpenFGAServiceClient = (*Server)(nil)
+
+// New returns a Server with an empty store named DefaultStoreName.
+func New() *Server {
+	s := &Server{stores: map[string]*store{}}
+	s.storeID = s.createStore(DefaultStoreName).id
+	return s
+}
+
+func newID() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// createStore must be called with the lock held.
+func (s *Server) createStore(name string) *store {
+	st := &store{id: newID(), name: name, createdAt: time.Now()}
+	s.stores[st.id] = st
+	s.order = append(s.order, st.id)
+	return st
+}
+
+// StoreID returns the ID of the default store.
+func (s *Server) StoreID() string {
+	return s.storeID
+}
+
+// ModelID returns the ID of the latest model of the default store, or
+// an empty string.
+func (s *Server) ModelID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := s.stores[s.storeID]
+	if len(st.models) == 0 {
+		return ""
+	}
+	return st.models[len(st.models)-1].GetId()
+}
+
+// AddModel adds an authorization model to the default store and
+// returns its ID.
+func (s *Server) AddModel(model *openfga.AuthorizationModel) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := proto.Clone(model).(*openfga.AuthorizationModel)
+	m.Id = newID()
+	st := s.stores[s.storeID]
+	st.models = append(st.models, m)
+	return m.Id
+}
+
+// AddTuples writes tuples to the default store, skipping those that
+// already exist. Unlike Write, it doesn't validate them against the
+// model.
+func (s *Server) AddTuples(tuples ...*openfga.TupleKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stores[s.storeID]
+	for _, t := range tuples {
+		if st.find(t.GetObject(), t.GetRelation(), t.GetUser()) < 0 {
+			st.tuples = append(st.tuples, proto.Clone(t).(*openfga.TupleKey))
+		}
+	}
+}
+
+// Tuples returns a copy of the tuples of the default store.
+func (s *Server) Tuples() []*openfga.TupleKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := s.stores[s.storeID]
+	out := make([]*openfga.TupleKey, len(st.tuples))
+	for i, t := range st.tuples {
+		out[i] = proto.Clone(t).(*openfga.TupleKey)
+	}
+	return out
+}
+
+func (st *store) find(object, relation, user string) int {
+	return slices.IndexFunc(st.tuples, func(t *openfga.TupleKey) bool {
+		return t.GetObject() == object && t.GetRelation() == relation && t.GetUser() == user
+	})
+}
+
+// model returns the requested model of the store, or its latest one.
+func (st *store) model(id string) (*openfga.AuthorizationModel, error) {
+	if len(st.models) == 0 {
+		return nil, fgaError(openfga.ErrorCode_latest_authorization_model_not_found, "no authorization model in store %s", st.id)
+	}
+	if id == "" {
+		return st.models[len(st.models)-1], nil
+	}
+	for _, m := range st.models {
+		if m.GetId() == id {
+			return m, nil
+		}
+	}
+	return nil, fgaError(openfga.ErrorCode_authorization_model_not_found, "authorization model %s not found", id)
+}
+
+// lookup returns the store and model of a request, with the read lock
+// held by the caller.
+func (s *Server) lookup(storeID, modelID string) (*store, *openfga.AuthorizationModel, error) {
+	st, err := s.store(storeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	m, err := st.model(modelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return st, m, nil
+}
+
+func (s *Server) store(id string) (*store, error) {
+	st, ok := s.stores[id]
+	if !ok {
+		return nil, status.Errorf(codes.Code(openfga.NotFoundErrorCode_store_id_not_found), "store %s not found", id)
+	}
+	return st, nil
+}
+
+// fgaError returns a status error carrying an OpenFGA error code, as
+// the server does for validation errors.
+func fgaError(code openfga.ErrorCode, format string, args ...any) error {
+	return status.Error(codes.Code(code), fmt.Sprintf(format, args...))
+}
+
+func unimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "acltest: %s is not implemented", method)
+}
+
+// ============================================================
+// Stores and models
+// ============================================================
+
+// CreateStore implements openfga.OpenFGAServiceClient.
+func (s *Server) CreateStore(_ context.Context, in *openfga.CreateStoreRequest, _ ...grpc.CallOption) (*openfga.CreateStoreResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.createStore(in.GetName())
+	return &openfga.CreateStoreResponse{Id: st.id, Name: st.name, CreatedAt: timestamppb.New(st.createdAt), UpdatedAt: timestamppb.New(st.createdAt)}, nil
+}
+
+// GetStore implements openfga.OpenFGAServiceClient.
+func (s *Server) GetStore(_ context.Context, in *openfga.GetStoreRequest, _ ...grpc.CallOption) (*openfga.GetStoreResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, err := s.store(in.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.GetStoreResponse{Id: st.id, Name: st.name, CreatedAt: timestamppb.New(st.createdAt), UpdatedAt: timestamppb.New(st.createdAt)}, nil
+}
+
+// ListStores implements openfga.OpenFGAServiceClient.
+func (s *Server) ListStores(_ context.Context, in *openfga.ListStoresRequest, _ ...grpc.CallOption) (*openfga.ListStoresResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stores []*openfga.Store
+	for _, id := range s.order {
+		st := s.stores[id]
+		if in.GetName() != "" && st.name != in.GetName() {
+			continue
+		}
+		stores = append(stores, &openfga.Store{Id: st.id, Name: st.name, CreatedAt: timestamppb.New(st.createdAt), UpdatedAt: timestamppb.New(st.createdAt)})
+	}
+
+	page, token, err := paginate(stores, in.GetPageSize().GetValue(), in.GetContinuationToken())
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.ListStoresResponse{Stores: page, ContinuationToken: token}, nil
+}
+
+// ReadAuthorizationModels implements openfga.OpenFGAServiceClient.
+// Models are returned latest first.
+func (s *Server) ReadAuthorizationModels(_ context.Context, in *openfga.ReadAuthorizationModelsRequest, _ ...grpc.CallOption) (*openfga.ReadAuthorizationModelsResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, err := s.store(in.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+	models := slices.Clone(st.models)
+	slices.Reverse(models)
+
+	page, token, err := paginate(models, in.GetPageSize().GetValue(), in.GetContinuationToken())
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.ReadAuthorizationModelsResponse{AuthorizationModels: page, ContinuationToken: token}, nil
+}
+
+// ReadAuthorizationModel implements openfga.OpenFGAServiceClient.
+func (s *Server) ReadAuthorizationModel(_ context.Context, in *openfga.ReadAuthorizationModelRequest, _ ...grpc.CallOption) (*openfga.ReadAuthorizationModelResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, err := s.store(in.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+	if in.GetId() == "" {
+		return nil, fgaError(openfga.ErrorCode_authorization_model_not_found, "authorization model ID is empty")
+	}
+	m, err := st.model(in.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.ReadAuthorizationModelResponse{AuthorizationModel: m}, nil
+}
+
+// WriteAuthorizationModel implements openfga.OpenFGAServiceClient.
+func (s *Server) WriteAuthorizationModel(_ context.Context, in *openfga.WriteAuthorizationModelRequest, _ ...grpc.CallOption) (*openfga.WriteAuthorizationModelResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, err := s.store(in.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+	m := &openfga.AuthorizationModel{
+		Id:              newID(),
+		SchemaVersion:   in.GetSchemaVersion(),
+		TypeDefinitions: in.GetTypeDefinitions(),
+		Conditions:      in.GetConditions(),
+	}
+	st.models = append(st.models, m)
+	return &openfga.WriteAuthorizationModelResponse{AuthorizationModelId: m.Id}, nil
+}
+
+// paginate returns the page of items starting at the offset encoded in
+// token.
+func paginate[T any](items []T, pageSize int32, token string) ([]T, string, error) {
+	offset := 0
+	if token != "" {
+		var err error
+		if offset, err = strconv.Atoi(token); err != nil || offset < 0 || offset > len(items) {
+			return nil, "", fgaError(openfga.ErrorCode_invalid_continuation_token, "invalid continuation token")
+		}
+	}
+	if pageSize <= 0 {
+		pageSize = int32(acl.DefaultReadPageSize)
+	}
+	end := min(offset+int(pageSize), len(items))
+	next := ""
+	if end < len(items) {
+		next = strconv.Itoa(end)
+	}
+	return items[offset:end], next, nil
+}
+
+// ============================================================
+// Unimplemented
+// ============================================================
+
+// WriteAssertions implements openfga.OpenFGAServiceClient. It is not
+// supported.
+func (s *Server) WriteAssertions(context.Context, *openfga.WriteAssertionsRequest, ...grpc.CallOption) (*openfga.WriteAssertionsResponse, error) {
+	return nil, unimplemented("WriteAssertions")
+}
+
+// ReadAssertions implements openfga.OpenFGAServiceClient. It is not
+// supported.
+func (s *Server) ReadAssertions(context.Context, *openfga.ReadAssertionsRequest, ...grpc.CallOption) (*openfga.ReadAssertionsResponse, error) {
+	return nil, unimplemented("ReadAssertions")
+}
+
+// ReadChanges implements openfga.OpenFGAServiceClient. It is not
+// supported.
+func (s *Server) ReadChanges(context.Context, *openfga.ReadChangesRequest, ...grpc.CallOption) (*openfga.ReadChangesResponse, error) {
+	return nil, unimplemented("ReadChanges")
+}
+
+// UpdateStore implements openfga.OpenFGAServiceClient. It is not
+// supported.
+func (s *Server) UpdateStore(context.Context, *openfga.UpdateStoreRequest, ...grpc.CallOption) (*openfga.UpdateStoreResponse, error) {
+	return nil, unimplemented("UpdateStore")
+}
+
+// DeleteStore implements openfga.OpenFGAServiceClient. It is not
+// supported.
+func (s *Server) DeleteStore(context.Context, *openfga.DeleteStoreRequest, ...grpc.CallOption) (*openfga.DeleteStoreResponse, error) {
+	return nil, unimplemented("DeleteStore")
+}
@@ -0,0 +1,316 @@
+package acltest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+	"github.com/instill-ai/x/constant"
+)
+
+const testModel = `model
+  schema 1.1
+
+type user
+
+type organization
+  relations
+    define owner: [user]
+    define member: [user] or owner
+
+type pipeline
+  relations
+    define owner: [user, organization]
+    define admin: [user] or owner or owner from owner
+    define writer: [user] or admin
+    define reader: [user, user:*, organization#member] or writer
+    define executor: [user, user:*] or writer
+    define banned: [user]
+    define runner: executor but not banned
+`
+
+var (
+	alice    = uuid.Must(uuid.NewV4())
+	bob      = uuid.Must(uuid.NewV4())
+	carol    = uuid.Must(uuid.NewV4())
+	org      = uuid.Must(uuid.NewV4())
+	pipeline = uuid.Must(uuid.NewV4())
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestClient returns an acl client backed by a fake loaded with the
+// test model and tuples.
+func newTestClient(t *testing.T, tuples string) (*Server, *acl.ACLClient) {
+	t.Helper()
+
+	tuplesPath := ""
+	if tuples != "" {
+		tuplesPath = writeFile(t, "tuples.json", tuples)
+	}
+	fake, err := NewFromFiles(writeFile(t, "model.fga", testModel), tuplesPath)
+	if err != nil {
+		t.Fatalf("NewFromFiles: %v", err)
+	}
+
+	client, err := acl.NewClientWithCache(context.Background(), fake, fake, nil, acl.Config{})
+	if err != nil {
+		t.Fatalf("NewClientWithCache: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return fake, client
+}
+
+func userCtx(uid uuid.UUID) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(constant.HeaderUserUIDKey, uid.String()))
+}
+
+func fixture(user, relation, object string) string {
+	return `{"user": "` + user + `", "relation": "` + relation + `", "object": "` + object + `"}`
+}
+
+// ============================================================
+// Check
+// ============================================================
+
+func TestServer_CheckPermission(t *testing.T) {
+	p := "pipeline:" + pipeline.String()
+	_, client := newTestClient(t, `[
+		`+fixture("organization:"+org.String(), "owner", p)+`,
+		`+fixture("user:"+alice.String(), "owner", "organization:"+org.String())+`,
+		`+fixture("user:"+bob.String(), "member", "organization:"+org.String())+`,
+		`+fixture("organization:"+org.String()+"#member", "reader", p)+`,
+		`+fixture("user:*", "executor", p)+`,
+		`+fixture("user:"+carol.String(), "banned", p)+`
+	]`)
+
+	tests := []struct {
+		name     string
+		user     uuid.UUID
+		relation string
+		want     bool
+	}{
+		{"tupleset rewrite", alice, "admin", true},
+		{"computed through tupleset", alice, "reader", true},
+		{"userset", bob, "reader", true},
+		{"userset does not grant writer", bob, "writer", false},
+		{"wildcard", carol, "executor", true},
+		{"exclusion", carol, "runner", false},
+		{"exclusion base", bob, "runner", true},
+		{"no tuple", carol, "reader", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.CheckPermission(userCtx(tt.user), "pipeline", pipeline, tt.relation)
+			if err != nil {
+				t.Fatalf("CheckPermission: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckPermission(%s) = %v, want %v", tt.relation, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_CheckUnknownType(t *testing.T) {
+	fake, _ := newTestClient(t, "")
+
+	_, err := fake.Check(context.Background(), &openfga.CheckRequest{
+		StoreId:  fake.StoreID(),
+		TupleKey: &openfga.CheckRequestTupleKey{User: "user:" + alice.String(), Relation: "reader", Object: "model:m1"},
+	})
+	if status.Code(err) != codes.Code(openfga.ErrorCode_type_not_found) {
+		t.Errorf("Check of an unknown type: got %v, want type_not_found", err)
+	}
+}
+
+// ============================================================
+// Write and list
+// ============================================================
+
+func TestServer_SetResourcePermissionAndList(t *testing.T) {
+	fake, client := newTestClient(t, "")
+	ctx := context.Background()
+
+	if err := client.SetOwner(ctx, "pipeline", pipeline, "users", alice); err != nil {
+		t.Fatalf("SetOwner: %v", err)
+	}
+	if err := client.SetResourcePermission(ctx, "pipeline", pipeline, "user:"+bob.String(), "reader", true); err != nil {
+		t.Fatalf("SetResourcePermission: %v", err)
+	}
+	// Switching role replaces the previous one.
+	if err := client.SetResourcePermission(ctx, "pipeline", pipeline, "user:"+bob.String(), "writer", true); err != nil {
+		t.Fatalf("SetResourcePermission: %v", err)
+	}
+	if got := len(fake.Tuples()); got != 2 {
+		t.Errorf("store holds %d tuples, want 2", got)
+	}
+
+	for _, user := range []uuid.UUID{alice, bob} {
+		uids, err := client.ListPermissions(userCtx(user), "pipeline", "writer")
+		if err != nil {
+			t.Fatalf("ListPermissions: %v", err)
+		}
+		if !slices.Equal(uids, []uuid.UUID{pipeline}) {
+			t.Errorf("ListPermissions(%s) = %v, want [%s]", user, uids, pipeline)
+		}
+	}
+
+	owner, ownerUID, err := client.GetOwner(ctx, "pipeline", pipeline)
+	if err != nil || owner != "user" || ownerUID != alice.String() {
+		t.Errorf("GetOwner = %s, %s, %v; want user, %s", owner, ownerUID, err, alice)
+	}
+}
+
+func TestServer_ListUsers(t *testing.T) {
+	p := "pipeline:" + pipeline.String()
+	fake, _ := newTestClient(t, `[
+		`+fixture("user:"+alice.String(), "owner", p)+`,
+		`+fixture("user:*", "executor", p)+`
+	]`)
+
+	resp, err := fake.ListUsers(context.Background(), &openfga.ListUsersRequest{
+		StoreId:     fake.StoreID(),
+		Object:      &openfga.Object{Type: "pipeline", Id: pipeline.String()},
+		Relation:    "executor",
+		UserFilters: []*openfga.UserTypeFilter{{Type: "user"}},
+	})
+	if err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if len(resp.GetUsers()) != 2 ||
+		resp.GetUsers()[0].GetWildcard().GetType() != "user" ||
+		resp.GetUsers()[1].GetObject().GetId() != alice.String() {
+		t.Errorf("ListUsers = %v, want the wildcard and alice", resp.GetUsers())
+	}
+}
+
+func TestServer_ExplainPermission(t *testing.T) {
+	p := "pipeline:" + pipeline.String()
+	_, client := newTestClient(t, `[
+		`+fixture("organization:"+org.String(), "owner", p)+`,
+		`+fixture("user:"+alice.String(), "owner", "organization:"+org.String())+`
+	]`)
+
+	// alice reads the pipeline as the owner of its owner organization.
+	exp, err := client.ExplainPermission(userCtx(alice), "pipeline", pipeline, "reader")
+	if err != nil {
+		t.Fatalf("ExplainPermission: %v", err)
+	}
+	if !exp.Allowed || !exp.Tree.Matched || exp.Tree.Kind != acl.ExplainUnion {
+		t.Errorf("ExplainPermission = %+v, want a matched union", exp)
+	}
+
+	exp, err = client.ExplainPermission(userCtx(bob), "pipeline", pipeline, "reader")
+	if err != nil {
+		t.Fatalf("ExplainPermission: %v", err)
+	}
+	if exp.Allowed || exp.Tree.Matched {
+		t.Errorf("ExplainPermission = %+v, want no match for bob", exp)
+	}
+}
+
+func TestServer_ReadTypeOnlyObject(t *testing.T) {
+	p := "pipeline:" + pipeline.String()
+	fake, _ := newTestClient(t, `[`+fixture("user:"+alice.String(), "owner", p)+`]`)
+	read := func(key *openfga.ReadRequestTupleKey) (*openfga.ReadResponse, error) {
+		return fake.Read(context.Background(), &openfga.ReadRequest{StoreId: fake.StoreID(), TupleKey: key})
+	}
+
+	// As in OpenFGA, a type-only object needs a user.
+	if _, err := read(&openfga.ReadRequestTupleKey{Object: "pipeline:"}); status.Code(err) != codes.Code(openfga.ErrorCode_validation_error) {
+		t.Errorf("Read without a user: got %v, want %s", err, openfga.ErrorCode_validation_error)
+	}
+	resp, err := read(&openfga.ReadRequestTupleKey{Object: "pipeline:", User: "user:" + alice.String()})
+	if err != nil || len(resp.GetTuples()) != 1 {
+		t.Errorf("Read with a user = %v, %v; want alice's tuple", resp.GetTuples(), err)
+	}
+	if resp, err := read(nil); err != nil || len(resp.GetTuples()) != 1 {
+		t.Errorf("Read of the whole store = %v, %v; want alice's tuple", resp.GetTuples(), err)
+	}
+}
+
+func TestServer_WriteErrors(t *testing.T) {
+	p := "pipeline:" + pipeline.String()
+	fake, _ := newTestClient(t, `[`+fixture("user:"+alice.String(), "owner", p)+`]`)
+	write := func(writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) error {
+		req := &openfga.WriteRequest{StoreId: fake.StoreID()}
+		if writes != nil {
+			req.Writes = &openfga.WriteRequestWrites{TupleKeys: writes}
+		}
+		if deletes != nil {
+			req.Deletes = &openfga.WriteRequestDeletes{TupleKeys: deletes}
+		}
+		_, err := fake.Write(context.Background(), req)
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		writes  []*openfga.TupleKey
+		deletes []*openfga.TupleKeyWithoutCondition
+		want    openfga.ErrorCode
+	}{
+		{
+			name:   "duplicate",
+			writes: []*openfga.TupleKey{{User: "user:" + alice.String(), Relation: "owner", Object: p}},
+			want:   openfga.ErrorCode_write_failed_due_to_invalid_input,
+		},
+		{
+			name:    "missing delete",
+			deletes: []*openfga.TupleKeyWithoutCondition{{User: "user:" + bob.String(), Relation: "owner", Object: p}},
+			want:    openfga.ErrorCode_write_failed_due_to_invalid_input,
+		},
+		{
+			name:    "same tuple twice",
+			writes:  []*openfga.TupleKey{{User: "user:" + bob.String(), Relation: "reader", Object: p}},
+			deletes: []*openfga.TupleKeyWithoutCondition{{User: "user:" + bob.String(), Relation: "reader", Object: p}},
+			want:    openfga.ErrorCode_cannot_allow_duplicate_tuples_in_one_request,
+		},
+		{
+			name:   "unknown relation",
+			writes: []*openfga.TupleKey{{User: "user:" + bob.String(), Relation: "viewer", Object: p}},
+			want:   openfga.ErrorCode_relation_not_found,
+		},
+		{
+			name:   "type restriction",
+			writes: []*openfga.TupleKey{{User: "user:*", Relation: "writer", Object: p}},
+			want:   openfga.ErrorCode_validation_error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := write(tt.writes, tt.deletes)
+			if status.Code(err) != codes.Code(tt.want) {
+				t.Errorf("Write: got %v, want %s", err, tt.want)
+			}
+			if got := len(fake.Tuples()); got != 1 {
+				t.Errorf("a failed write changed the store: %d tuples", got)
+			}
+		})
+	}
+}
+
+func TestNewFromFiles_InvalidTuple(t *testing.T) {
+	_, err := NewFromFiles(writeFile(t, "model.fga", testModel), writeFile(t, "tuples.json", `[`+fixture("user:"+alice.String(), "viewer", "pipeline:p1")+`]`))
+	if err == nil {
+		t.Fatal("NewFromFiles accepted a tuple with an unknown relation")
+	}
+}
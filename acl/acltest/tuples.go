@@ -0,0 +1,181 @@
+package acltest
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+)
+
+// Read implements openfga.OpenFGAServiceClient. As in OpenFGA, an
+// object without an ID ("pipeline:") matches every object of the type
+// and is only accepted along with a user.
+func (s *Server) Read(_ context.Context, in *openfga.ReadRequest, _ ...grpc.CallOption) (*openfga.ReadResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st, err := s.store(in.GetStoreId())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := in.GetTupleKey()
+	if strings.HasSuffix(filter.GetObject(), ":") && filter.GetUser() == "" {
+		return nil, fgaError(openfga.ErrorCode_validation_error, "the user is required when the object %q has no ID", filter.GetObject())
+	}
+	var tuples []*openfga.Tuple
+	for _, t := range st.tuples {
+		if !matchObject(filter.GetObject(), t.GetObject()) ||
+			(filter.GetRelation() != "" && filter.GetRelation() != t.GetRelation()) ||
+			(filter.GetUser() != "" && filter.GetUser() != t.GetUser()) {
+			continue
+		}
+		tuples = append(tuples, &openfga.Tuple{Key: proto.Clone(t).(*openfga.TupleKey), Timestamp: timestamppb.Now()})
+	}
+
+	page, token, err := paginate(tuples, in.GetPageSize().GetValue(), in.GetContinuationToken())
+	if err != nil {
+		return nil, err
+	}
+	return &openfga.ReadResponse{Tuples: page, ContinuationToken: token}, nil
+}
+
+func matchObject(filter, object string) bool {
+	if filter == "" {
+		return true
+	}
+	if strings.HasSuffix(filter, ":") {
+		return strings.HasPrefix(object, filter)
+	}
+	return filter == object
+}
+
+// Write implements openfga.OpenFGAServiceClient. Tuples are validated
+// against the latest (or requested) model and the request is applied
+// atomically, with the error codes of the OpenFGA server.
+func (s *Server) Write(_ context.Context, in *openfga.WriteRequest, _ ...grpc.CallOption) (*openfga.WriteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, model, err := s.lookup(in.GetStoreId(), in.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	writes := in.GetWrites().GetTupleKeys()
+	deletes := in.GetDeletes().GetTupleKeys()
+	if len(writes)+len(deletes) > acl.MaxTuplesPerWrite {
+		return nil, fgaError(openfga.ErrorCode_exceeded_entity_limit, "the number of writes and deletes exceeds the limit of %d", acl.MaxTuplesPerWrite)
+	}
+
+	seen := map[string]bool{}
+	unique := func(object, relation, user string) error {
+		key := object + "#" + relation + "@" + user
+		if seen[key] {
+			return fgaError(openfga.ErrorCode_cannot_allow_duplicate_tuples_in_one_request, "duplicate tuple in write: %s", key)
+		}
+		seen[key] = true
+		return nil
+	}
+
+	e := newEvaluator(model, nil, nil, nil)
+	for _, t := range writes {
+		if err := unique(t.GetObject(), t.GetRelation(), t.GetUser()); err != nil {
+			return nil, err
+		}
+		if err := e.validate(t); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range deletes {
+		if err := unique(t.GetObject(), t.GetRelation(), t.GetUser()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check everything before touching the store so that a failed
+	// request changes nothing.
+	tuples := st.tuples
+	var removed []int
+	for _, t := range deletes {
+		i := st.find(t.GetObject(), t.GetRelation(), t.GetUser())
+		if i < 0 {
+			if in.GetDeletes().GetOnMissing() == "ignore" {
+				continue
+			}
+			return nil, fgaError(openfga.ErrorCode_write_failed_due_to_invalid_input, "cannot delete a tuple which does not exist: %s#%s@%s", t.GetObject(), t.GetRelation(), t.GetUser())
+		}
+		removed = append(removed, i)
+	}
+	var added []*openfga.TupleKey
+	for _, t := range writes {
+		i := st.find(t.GetObject(), t.GetRelation(), t.GetUser())
+		if i >= 0 {
+			if in.GetWrites().GetOnDuplicate() == "ignore" && proto.Equal(tuples[i].GetCondition(), t.GetCondition()) {
+				continue
+			}
+			return nil, fgaError(openfga.ErrorCode_write_failed_due_to_invalid_input, "cannot write a tuple which already exists: %s#%s@%s", t.GetObject(), t.GetRelation(), t.GetUser())
+		}
+		added = append(added, proto.Clone(t).(*openfga.TupleKey))
+	}
+
+	gone := map[int]bool{}
+	for _, i := range removed {
+		gone[i] = true
+	}
+	kept := make([]*openfga.TupleKey, 0, len(tuples)-len(removed)+len(added))
+	for i, t := range tuples {
+		if !gone[i] {
+			kept = append(kept, t)
+		}
+	}
+	st.tuples = append(kept, added...)
+
+	return &openfga.WriteResponse{}, nil
+}
+
+// validate checks a tuple against the type restrictions of the model.
+// Relations without restriction metadata accept any user.
+func (e *evaluator) validate(t *openfga.TupleKey) error {
+	if t.GetObject() == "" || t.GetRelation() == "" || t.GetUser() == "" {
+		return fgaError(openfga.ErrorCode_invalid_tuple, "invalid tuple %s#%s@%s", t.GetObject(), t.GetRelation(), t.GetUser())
+	}
+	objectType := typeOf(t.GetObject())
+	if _, err := e.rewrite(objectType, t.GetRelation()); err != nil {
+		return err
+	}
+	if _, ok := e.types[typeOf(t.GetUser())]; !ok {
+		return fgaError(openfga.ErrorCode_type_not_found, "type '%s' not found", typeOf(t.GetUser()))
+	}
+
+	meta, ok := e.types[objectType].GetMetadata().GetRelations()[t.GetRelation()]
+	if !ok {
+		return nil
+	}
+	userType := typeOf(t.GetUser())
+	_, userRelation, _ := strings.Cut(t.GetUser(), "#")
+	for _, ref := range meta.GetDirectlyRelatedUserTypes() {
+		if ref.GetType() != userType || ref.GetCondition() != t.GetCondition().GetName() {
+			continue
+		}
+		switch {
+		case ref.GetWildcard() != nil:
+			if isWildcard(t.GetUser()) {
+				return nil
+			}
+		case ref.GetRelation() != "":
+			if ref.GetRelation() == userRelation {
+				return nil
+			}
+		case userRelation == "" && !isWildcard(t.GetUser()):
+			return nil
+		}
+	}
+	return fgaError(openfga.ErrorCode_validation_error, "type '%s' is not an allowed type restriction for '%s#%s'", t.GetUser(), objectType, t.GetRelation())
+}
@@ -0,0 +1,210 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/constant"
+	"github.com/instill-ai/x/resource"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// AuditOperation names the client method that mutated permissions.
+type AuditOperation string
+
+// Audit operations, named after the ACLClient method that emits them.
+const (
+	AuditSetOwner                 AuditOperation = "set_owner"
+	AuditTransferOwnership        AuditOperation = "transfer_ownership"
+	AuditSetResourcePermission    AuditOperation = "set_resource_permission"
+	AuditDeleteResourcePermission AuditOperation = "delete_resource_permission"
+	AuditSetPublicPermission      AuditOperation = "set_public_permission"
+	AuditDeletePublicPermission   AuditOperation = "delete_public_permission"
+	AuditPurge                    AuditOperation = "purge"
+	AuditCreateShareLink          AuditOperation = "create_share_link"
+	AuditRevokeShareLink          AuditOperation = "revoke_share_link"
+	AuditRotateShareLink          AuditOperation = "rotate_share_link"
+	AuditAddMember                AuditOperation = "add_member"
+	AuditRemoveMember             AuditOperation = "remove_member"
+	AuditSetMemberRole            AuditOperation = "set_member_role"
+	// AuditTransaction is the operation of TupleTx.Commit.
+	AuditTransaction AuditOperation = "transaction"
+	// AuditApplyTuples is the operation of ApplyTuples.
+	AuditApplyTuples AuditOperation = "apply_tuples"
+)
+
+// AuditEvent records the change of the relations a subject holds on an
+// object. A mutation touching several subjects or objects emits one
+// event per (subject, object) pair. Share-link tokens and link codes
+// are redacted from every field, wherever they appear, before the event
+// reaches a sink.
+type AuditEvent struct {
+	Time      time.Time
+	Operation AuditOperation
+	// Actor is the FGA subject of the request that made the change, e.g.
+	// "user:<uid>", or empty for changes made outside of a request, such
+	// as migrations.
+	Actor string
+	// Requester is the namespace the actor acted on behalf of, when the
+	// request carries one.
+	Requester string
+	// Subject is the FGA subject whose relations changed.
+	Subject string
+	// Relation is the relation the operation targeted, e.g. the role of
+	// SetResourcePermission. It is empty for operations that target every
+	// relation, such as Purge.
+	Relation string
+	Object   string
+	// Before and After list the relations of Subject on Object before and
+	// after the change. Only the relations the operation read or changed
+	// are listed.
+	Before []string
+	After  []string
+	// RequestID is the request-id header of the request, if any.
+	RequestID string
+	// TraceID is the ID of the active trace, if any.
+	TraceID string
+	// Error is set when the change failed, in which case After is the
+	// state the operation tried to reach: some tuples may not have been
+	// applied.
+	Error string
+}
+
+// AuditSink receives the audit events of an ACLClient. Emit is called
+// synchronously after each mutation; its errors are logged and don't
+// fail the mutation.
+type AuditSink interface {
+	Emit(ctx context.Context, event AuditEvent) error
+}
+
+// MultiAuditSink returns a sink emitting every event to all sinks.
+func MultiAuditSink(sinks ...AuditSink) AuditSink {
+	return multiAuditSink(sinks)
+}
+
+type multiAuditSink []AuditSink
+
+func (m multiAuditSink) Emit(ctx context.Context, event AuditEvent) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Emit(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// auditPair identifies the relations of a subject on an object.
+type auditPair struct {
+	object, user string
+}
+
+// audit emits the events of a mutation. read holds the tuples the
+// operation read before writing, if any; writes and deletes are the
+// tuples it sent and err the result of the write.
+func (c *ACLClient) audit(ctx context.Context, op AuditOperation, relation string, read []ReadTuple, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition, err error) {
+	if c.config.Audit == nil || (len(writes) == 0 && len(deletes) == 0) {
+		return
+	}
+
+	var pairs []auditPair
+	touched := map[auditPair]bool{}
+	touch := func(object, user string) auditPair {
+		p := auditPair{object, user}
+		if !touched[p] {
+			touched[p] = true
+			pairs = append(pairs, p)
+		}
+		return p
+	}
+
+	before, deleted, written := map[auditPair][]string{}, map[auditPair][]string{}, map[auditPair][]string{}
+	for _, t := range deletes {
+		p := touch(t.GetObject(), t.GetUser())
+		deleted[p] = append(deleted[p], t.GetRelation())
+	}
+	for _, t := range writes {
+		p := touch(t.GetObject(), t.GetUser())
+		written[p] = append(written[p], t.GetRelation())
+	}
+	for _, t := range read {
+		if p := (auditPair{t.Object, t.User}); touched[p] {
+			before[p] = append(before[p], t.Relation)
+		}
+	}
+	// Deleted tuples were held even when the operation didn't read them.
+	for p, relations := range deleted {
+		before[p] = append(before[p], relations...)
+	}
+
+	actor := ""
+	if userType, userUID, err := resolveACLSubject(ctx); err == nil {
+		actor = fmt.Sprintf("%s:%s", userType, userUID)
+	}
+	traceID := ""
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	}
+	errMsg := ""
+	if err != nil {
+		errMsg = redactSubjects(err.Error())
+	}
+
+	log, _ := logx.GetZapLogger(ctx)
+	now := time.Now().UTC()
+	for _, p := range pairs {
+		held := sortedRelations(before[p])
+		kept := slices.DeleteFunc(slices.Clone(held), func(r string) bool {
+			return slices.Contains(deleted[p], r)
+		})
+
+		event := AuditEvent{
+			Time:      now,
+			Operation: op,
+			Actor:     redactSubject(actor),
+			Requester: resource.GetRequestSingleHeader(ctx, constant.HeaderRequesterUIDKey),
+			Subject:   redactSubject(p.user),
+			Relation:  relation,
+			Object:    redactSubject(p.object),
+			Before:    redactRelations(held),
+			After:     redactRelations(sortedRelations(append(kept, written[p]...))),
+			RequestID: resource.GetRequestSingleHeader(ctx, constant.HeaderRequestIDKey),
+			TraceID:   traceID,
+			Error:     errMsg,
+		}
+		if err := c.config.Audit.Emit(ctx, event); err != nil {
+			log.Warn("Failed to emit ACL audit event",
+				zap.Error(err),
+				zap.String("operation", string(op)),
+				zap.String("object", redactSubject(p.object)))
+		}
+	}
+}
+
+func sortedRelations(relations []string) []string {
+	if len(relations) == 0 {
+		return []string{}
+	}
+	out := slices.Clone(relations)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// redactRelations applies redactSubjects to relations in place.
+// Relations are plain names in the models this package manages, but
+// the event fields are redacted wholesale so a sink never has to care.
+func redactRelations(relations []string) []string {
+	for i, r := range relations {
+		relations[i] = redactSubjects(r)
+	}
+	return relations
+}
@@ -0,0 +1,146 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/log/global"
+	"go.uber.org/zap"
+
+	otellog "go.opentelemetry.io/otel/log"
+)
+
+// AuditEventName is the message of the audit log entries and the event
+// name of the OpenTelemetry log records.
+const AuditEventName = "acl.audit"
+
+// DefaultAuditStream is the Redis stream of RedisAuditSink.
+const DefaultAuditStream = "acl:audit"
+
+// ZapAuditSink writes audit events as Info entries of a zap logger.
+type ZapAuditSink struct {
+	logger *zap.Logger
+}
+
+// NewZapAuditSink returns a sink writing to logger.
+func NewZapAuditSink(logger *zap.Logger) *ZapAuditSink {
+	return &ZapAuditSink{logger: logger}
+}
+
+// Emit implements AuditSink.
+func (s *ZapAuditSink) Emit(_ context.Context, e AuditEvent) error {
+	s.logger.Info(AuditEventName,
+		zap.Time("time", e.Time),
+		zap.String("operation", string(e.Operation)),
+		zap.String("actor", e.Actor),
+		zap.String("requester", e.Requester),
+		zap.String("subject", e.Subject),
+		zap.String("relation", e.Relation),
+		zap.String("object", e.Object),
+		zap.Strings("before", e.Before),
+		zap.Strings("after", e.After),
+		zap.String("requestID", e.RequestID),
+		zap.String("traceID", e.TraceID),
+		zap.String("error", e.Error),
+	)
+	return nil
+}
+
+// OTelAuditSink emits audit events as OpenTelemetry log records, which
+// are correlated with the active span of the mutation.
+type OTelAuditSink struct {
+	logger otellog.Logger
+}
+
+// NewOTelAuditSink returns a sink emitting to provider, or to the global
+// logger provider (see otel.SetupLogging) when provider is nil.
+func NewOTelAuditSink(provider otellog.LoggerProvider) *OTelAuditSink {
+	if provider == nil {
+		provider = global.GetLoggerProvider()
+	}
+	return &OTelAuditSink{logger: provider.Logger("github.com/instill-ai/x/acl")}
+}
+
+// Emit implements AuditSink.
+func (s *OTelAuditSink) Emit(ctx context.Context, e AuditEvent) error {
+	var r otellog.Record
+	r.SetEventName(AuditEventName)
+	r.SetTimestamp(e.Time)
+	r.SetSeverity(otellog.SeverityInfo)
+	r.SetSeverityText("INFO")
+	r.SetBody(otellog.StringValue(fmt.Sprintf("%s %s on %s", e.Operation, e.Subject, e.Object)))
+	r.AddAttributes(
+		otellog.String("acl.operation", string(e.Operation)),
+		otellog.String("acl.actor", e.Actor),
+		otellog.String("acl.requester", e.Requester),
+		otellog.String("acl.subject", e.Subject),
+		otellog.String("acl.relation", e.Relation),
+		otellog.String("acl.object", e.Object),
+		otellog.Slice("acl.before", stringValues(e.Before)...),
+		otellog.Slice("acl.after", stringValues(e.After)...),
+		otellog.String("acl.request_id", e.RequestID),
+		otellog.String("acl.error", e.Error),
+	)
+	s.logger.Emit(ctx, r)
+	return nil
+}
+
+func stringValues(ss []string) []otellog.Value {
+	values := make([]otellog.Value, len(ss))
+	for i, s := range ss {
+		values[i] = otellog.StringValue(s)
+	}
+	return values
+}
+
+// RedisAuditSink appends audit events to a Redis stream, where a
+// compliance consumer can read them with XREAD or a consumer group.
+// Before and After are stored as comma-separated lists.
+type RedisAuditSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisAuditSink returns a sink appending to stream (DefaultAuditStream
+// when empty). When maxLen is positive the stream is approximately
+// capped to that many entries; consumers must keep up to not lose
+// events. The client is owned by the caller.
+func NewRedisAuditSink(client *redis.Client, stream string, maxLen int64) *RedisAuditSink {
+	if stream == "" {
+		stream = DefaultAuditStream
+	}
+	return &RedisAuditSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+// Emit implements AuditSink.
+func (s *RedisAuditSink) Emit(ctx context.Context, e AuditEvent) error {
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: []any{
+			"time", e.Time.Format(time.RFC3339Nano),
+			"operation", string(e.Operation),
+			"actor", e.Actor,
+			"requester", e.Requester,
+			"subject", e.Subject,
+			"relation", e.Relation,
+			"object", e.Object,
+			"before", strings.Join(e.Before, ","),
+			"after", strings.Join(e.After, ","),
+			"request_id", e.RequestID,
+			"trace_id", e.TraceID,
+			"error", e.Error,
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("appending audit event to %s: %w", s.stream, err)
+	}
+	return nil
+}
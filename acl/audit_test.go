@@ -0,0 +1,272 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+
+	"github.com/instill-ai/x/constant"
+)
+
+// recordingAuditSink keeps the events it receives.
+type recordingAuditSink struct {
+	events []AuditEvent
+	err    error
+}
+
+func (s *recordingAuditSink) Emit(_ context.Context, e AuditEvent) error {
+	s.events = append(s.events, e)
+	return s.err
+}
+
+func newAuditedTestClient(fga *mockFGA) (*ACLClient, *recordingAuditSink) {
+	sink := &recordingAuditSink{}
+	c := newTestClient(fga)
+	c.config.Audit = sink
+	return c, sink
+}
+
+// ============================================================
+// Audit events of the mutations
+// ============================================================
+
+func TestAudit_SetResourcePermission(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c, sink := newAuditedTestClient(&mockFGA{readFn: existingRolesReadFn("reader"), writeFn: recordWrites(&writes)})
+
+	traceID := trace.TraceID{1, 2, 3}
+	ctx := ctxWithHeaders(map[string]string{
+		constant.HeaderAuthTypeKey:     "user",
+		constant.HeaderUserUIDKey:      testUserUID,
+		constant.HeaderRequesterUIDKey: "org-uid",
+		constant.HeaderRequestIDKey:    "req-1",
+	})
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID}))
+
+	if err := c.SetResourcePermission(ctx, "pipeline", testObjectUID, "user:bob", "writer", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("expected one event, got %v", sink.events)
+	}
+	e := sink.events[0]
+	want := AuditEvent{
+		Time:      e.Time,
+		Operation: AuditSetResourcePermission,
+		Actor:     "user:" + testUserUID,
+		Requester: "org-uid",
+		Subject:   "user:bob",
+		Relation:  "writer",
+		Object:    "pipeline:" + testObjectUID.String(),
+		Before:    []string{"reader"},
+		After:     []string{"writer"},
+		RequestID: "req-1",
+		TraceID:   traceID.String(),
+	}
+	if e.Time.IsZero() || !auditEventsEqual(e, want) {
+		t.Errorf("got event %+v, want %+v", e, want)
+	}
+}
+
+func TestAudit_SetPublicPermission(t *testing.T) {
+	c, sink := newAuditedTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{}, nil
+		},
+		writeFn: recordWrites(new([]*openfga.WriteRequest)),
+	})
+
+	if err := c.SetPublicPermission(context.Background(), "pipeline", testObjectUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 3 {
+		t.Fatalf("expected one event per wildcard grant, got %v", sink.events)
+	}
+	for _, e := range sink.events {
+		if e.Operation != AuditSetPublicPermission || e.Actor != "" || len(e.Before) != 0 || len(e.After) != 1 {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+}
+
+func TestAudit_PurgeGroupsBySubject(t *testing.T) {
+	object := "pipeline:" + testObjectUID.String()
+	c, sink := newAuditedTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{
+				{Key: &openfga.TupleKey{Object: object, Relation: "owner", User: "user:alice"}},
+				{Key: &openfga.TupleKey{Object: object, Relation: "reader", User: "user:*"}},
+				{Key: &openfga.TupleKey{Object: object, Relation: "executor", User: "user:*"}},
+			}}, nil
+		},
+		writeFn: recordWrites(new([]*openfga.WriteRequest)),
+	})
+
+	if err := c.Purge(context.Background(), "pipeline", testObjectUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 2 {
+		t.Fatalf("expected one event per subject, got %v", sink.events)
+	}
+	if e := sink.events[1]; e.Subject != "user:*" || !slices.Equal(e.Before, []string{"executor", "reader"}) || len(e.After) != 0 {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestAudit_FailedWriteAndSinkError(t *testing.T) {
+	writeErr := errors.New("write failed")
+	c, sink := newAuditedTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{}, nil
+		},
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return nil, writeErr
+		},
+	})
+	sink.err = errors.New("sink down")
+
+	err := c.SetResourcePermission(context.Background(), "pipeline", testObjectUID, "user:bob", "reader", true)
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	if len(sink.events) != 1 || sink.events[0].Error == "" {
+		t.Errorf("expected an event carrying the error, got %v", sink.events)
+	}
+}
+
+func TestAudit_ShareLinkTokenRedacted(t *testing.T) {
+	c, sink := newAuditedTestClient(&mockFGA{writeFn: recordWrites(new([]*openfga.WriteRequest))})
+
+	link, err := c.CreateShareLink(context.Background(), "pipeline", testObjectUID, "reader", time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 1 || sink.events[0].Subject == link.Subject() {
+		t.Errorf("the token should be redacted, got %v", sink.events)
+	}
+}
+
+func TestAudit_ShareLinkTokenRedactedFromError(t *testing.T) {
+	const token = "s3cr3t-t0ken-value"
+	c, sink := newAuditedTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{}, nil
+		},
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return nil, fmt.Errorf("cannot write a tuple which already exists: user: 'share_link:%s'", token)
+		},
+	})
+
+	if err := c.SetResourcePermission(context.Background(), "pipeline", testObjectUID, "share_link:"+token, "reader", true); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("expected one event, got %v", sink.events)
+	}
+	if e := sink.events[0]; strings.Contains(fmt.Sprintf("%+v", e), token) || e.Error == "" {
+		t.Errorf("the token should be redacted from every field, got %+v", e)
+	}
+}
+
+func TestAudit_NoSinkNoEvent(t *testing.T) {
+	c := newTestClient(&mockFGA{writeFn: recordWrites(new([]*openfga.WriteRequest))})
+	// Must not panic without a sink.
+	if err := c.Begin().Grant("pipeline", testObjectUID, "user:bob", "reader").Commit(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func auditEventsEqual(a, b AuditEvent) bool {
+	return a.Time.Equal(b.Time) && a.Operation == b.Operation && a.Actor == b.Actor &&
+		a.Requester == b.Requester && a.Subject == b.Subject && a.Relation == b.Relation &&
+		a.Object == b.Object && slices.Equal(a.Before, b.Before) && slices.Equal(a.After, b.After) &&
+		a.RequestID == b.RequestID && a.TraceID == b.TraceID && a.Error == b.Error
+}
+
+// ============================================================
+// Sinks
+// ============================================================
+
+var testAuditEvent = AuditEvent{
+	Operation: AuditSetOwner,
+	Subject:   "user:alice",
+	Relation:  "owner",
+	Object:    "pipeline:p1",
+	Before:    []string{},
+	After:     []string{"owner"},
+}
+
+func TestZapAuditSink(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	if err := NewZapAuditSink(zap.New(core)).Emit(context.Background(), testAuditEvent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := logs.FilterMessage(AuditEventName).All()
+	if len(entries) != 1 || entries[0].ContextMap()["object"] != "pipeline:p1" {
+		t.Errorf("unexpected entries %v", entries)
+	}
+}
+
+// recordingExporter keeps the exported log records.
+type recordingExporter struct {
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.records = append(e.records, records...)
+	return nil
+}
+func (e *recordingExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func TestOTelAuditSink(t *testing.T) {
+	exporter := &recordingExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+
+	if err := NewOTelAuditSink(provider).Emit(context.Background(), testAuditEvent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exporter.records) != 1 || exporter.records[0].EventName() != AuditEventName {
+		t.Fatalf("unexpected records %v", exporter.records)
+	}
+	found := false
+	exporter.records[0].WalkAttributes(func(kv otellog.KeyValue) bool {
+		found = found || (string(kv.Key) == "acl.object" && kv.Value.AsString() == "pipeline:p1")
+		return true
+	})
+	if !found {
+		t.Error("the record should carry the object attribute")
+	}
+}
+
+func TestRedisAuditSink(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	if err := NewRedisAuditSink(rc, "", 100).Emit(context.Background(), testAuditEvent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, err := rc.XRange(context.Background(), DefaultAuditStream, "-", "+").Result()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one stream entry, got %v (%v)", entries, err)
+	}
+	if entries[0].Values["operation"] != string(AuditSetOwner) || entries[0].Values["after"] != "owner" {
+		t.Errorf("unexpected entry %v", entries[0].Values)
+	}
+}
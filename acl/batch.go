@@ -0,0 +1,230 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// BatchCheckPermission verifies several (object, role) pairs for the
+// current user and returns the decision for each of them. It is the
+// list-endpoint counterpart of CheckPermission: a page of 100 rows
+// costs one Redis MGET and (at most) one BatchCheck round-trip
+// instead of 100 GETs and 100 sequential Checks.
+//
+// Contract
+// --------
+//   - The subject is resolved once via resolveACLSubject, so the
+//     identity rules are exactly those of CheckPermission.
+//   - ContextKeyForceHigherConsistency and user pinning are honoured
+//     the same way: either trigger bypasses the cache (both read and
+//     write) and sends HIGHER_CONSISTENCY to OpenFGA.
+//   - Cache hits are served from the acl:perm: keyspace, which is
+//     shared with CheckPermission, so a decision cached by either
+//     method is reused by the other. The tag versions of every item
+//     are resolved in the same round-trip (see cache.go).
+//   - Misses are sent to OpenFGA in a single BatchCheck call, split
+//     into chunks of MaxChecksPerBatchCheck when the input is larger
+//     than the server allows. Results are written back to the cache
+//     through one pipeline.
+//   - Duplicate items are evaluated once. The returned map holds one
+//     entry per distinct item.
+//
+// Any per-item error reported by OpenFGA fails the whole call: a
+// partial map would force every caller to distinguish "denied" from
+// "unknown", which is exactly the ambiguity CheckPermission avoids.
+// When OpenFGA is unavailable, a chunk is served from the decisions
+// remembered for FailModeStale if every item of it has one, as
+// CheckPermission does. The check duration metric is recorded once per
+// distinct item, with the latency of the whole call.
+func (c *ACLClient) BatchCheckPermission(ctx context.Context, items []CheckItem) (map[CheckItem]bool, error) {
+	startedAt := time.Now()
+	results, err := c.batchCheckPermission(ctx, items)
+	recorded := make(map[CheckItem]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := recorded[item]; ok {
+			continue
+		}
+		recorded[item] = struct{}{}
+		metrics.recordCheck(ctx, startedAt, item.ObjectType, item.Role, results[item], err)
+	}
+	return results, err
+}
+
+func (c *ACLClient) batchCheckPermission(ctx context.Context, items []CheckItem) (map[CheckItem]bool, error) {
+	log, _ := logx.GetZapLogger(ctx)
+
+	results := make(map[CheckItem]bool, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+	for _, item := range items {
+		if err := c.validate(item.ObjectType, item.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	userType, userUID, err := resolveACLSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consistency, forceConsistency := c.checkConsistency(ctx, userUID)
+	useCache := c.cacheEnabled && c.cache != nil && !forceConsistency && !hasCheckContext(ctx)
+
+	// Deduplicate while preserving the caller's order so chunking and
+	// logging stay deterministic. Items outside the scopes of a machine
+	// subject are denied upfront.
+	seen := make(map[CheckItem]struct{}, len(items))
+	unique := make([]CheckItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		if outOfScope(ctx, userType, item.ObjectType, item.Role) {
+			results[item] = false
+			continue
+		}
+		unique = append(unique, item)
+	}
+
+	entries := make([]*CacheEntry, len(unique))
+	for i, item := range unique {
+		entries[i] = checkCacheEntry(userType, userUID, item.ObjectType, item.ObjectUID.String(), item.Role)
+	}
+	misses, missEntries := unique, entries
+	if useCache {
+		cached, err := c.cache.Get(ctx, entries)
+		if err != nil {
+			// Log cache error but continue to OpenFGA with every item.
+			log.Warn("BatchCheckPermission cache error", zap.Error(err))
+		} else {
+			misses, missEntries = make([]CheckItem, 0, len(unique)), make([]*CacheEntry, 0, len(unique))
+			for i, v := range cached {
+				if v != "" {
+					results[unique[i]] = v == "1"
+					continue
+				}
+				misses = append(misses, unique[i])
+				missEntries = append(missEntries, entries[i])
+			}
+		}
+	}
+
+	log.Debug("BatchCheckPermission",
+		zap.String("userType", userType),
+		zap.String("userUID", userUID),
+		zap.Int("items", len(unique)),
+		zap.Int("cacheHits", len(unique)-len(misses)),
+		zap.Bool("forceConsistency", forceConsistency),
+		zap.String("consistency", consistency.String()),
+	)
+
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	modelID, err := c.getAuthorizationModelID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting authorization model: %w", err)
+	}
+
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user := fmt.Sprintf("%s:%s", userType, userUID)
+	for start := 0; start < len(misses); start += MaxChecksPerBatchCheck {
+		end := min(start+MaxChecksPerBatchCheck, len(misses))
+		chunk, chunkEntries := misses[start:end], missEntries[start:end]
+
+		// The correlation ID only has to be unique within a request,
+		// so the index into the chunk is enough to map results back.
+		checks := make([]*openfga.BatchCheckItem, len(chunk))
+		for i, item := range chunk {
+			checks[i] = &openfga.BatchCheckItem{
+				TupleKey: &openfga.CheckRequestTupleKey{
+					User:     user,
+					Relation: item.Role,
+					Object:   fmt.Sprintf("%s:%s", item.ObjectType, item.ObjectUID.String()),
+				},
+				Context:       reqContext,
+				CorrelationId: strconv.Itoa(i),
+			}
+		}
+
+		staleEpoch := c.staleEpoch()
+		resp, err := c.getClient(ctx, ReadMode).BatchCheck(ctx, &openfga.BatchCheckRequest{
+			StoreId:              c.storeID,
+			AuthorizationModelId: modelID,
+			Checks:               checks,
+			Consistency:          consistency,
+		})
+		if err != nil {
+			// As in CheckPermission, stale decisions can't vouch for a
+			// pinned user nor for checks depending on the request
+			// context.
+			if !forceConsistency && !hasCheckContext(ctx) && c.staleChunk(ctx, chunk, chunkEntries, results, err) {
+				continue
+			}
+			log.Error("BatchCheckPermission failed", zap.Error(err))
+			return nil, err
+		}
+
+		for i, item := range chunk {
+			result, ok := resp.GetResult()[strconv.Itoa(i)]
+			if !ok {
+				return nil, fmt.Errorf("batch check: no result for %s:%s#%s", item.ObjectType, item.ObjectUID, item.Role)
+			}
+			if checkErr := result.GetError(); checkErr != nil {
+				return nil, fmt.Errorf("batch check %s:%s#%s: %s", item.ObjectType, item.ObjectUID, item.Role, checkErr.GetMessage())
+			}
+			results[item] = result.GetAllowed()
+			if !hasCheckContext(ctx) {
+				c.rememberDecision(chunkEntries[i], result.GetAllowed(), staleEpoch)
+			}
+		}
+	}
+
+	if useCache {
+		values := make([]string, len(misses))
+		for i, item := range misses {
+			values[i] = "0"
+			if results[item] {
+				values[i] = "1"
+			}
+		}
+		if err := c.cache.Set(ctx, missEntries, values); err != nil {
+			log.Warn("BatchCheckPermission failed to cache results", zap.Error(err))
+		}
+	}
+
+	return results, nil
+}
+
+// staleChunk fills results with the stale decisions of chunk, whose
+// BatchCheck failed with err. It reports false, leaving results
+// untouched, unless every item of the chunk has one.
+func (c *ACLClient) staleChunk(ctx context.Context, chunk []CheckItem, entries []*CacheEntry, results map[CheckItem]bool, err error) bool {
+	decisions := make([]bool, len(chunk))
+	for i, item := range chunk {
+		allowed, ok := c.staleDecision(ctx, item.ObjectType, entries[i], err)
+		if !ok {
+			return false
+		}
+		decisions[i] = allowed
+	}
+	for i, item := range chunk {
+		results[item] = decisions[i]
+	}
+	return true
+}
@@ -0,0 +1,250 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// allowedBatchResponse answers every check in req with the result of
+// allow(tupleKey), keyed by the request's correlation IDs.
+func allowedBatchResponse(req *openfga.BatchCheckRequest, allow func(*openfga.CheckRequestTupleKey) bool) *openfga.BatchCheckResponse {
+	resp := &openfga.BatchCheckResponse{Result: map[string]*openfga.BatchCheckSingleResult{}}
+	for _, check := range req.Checks {
+		resp.Result[check.CorrelationId] = &openfga.BatchCheckSingleResult{
+			CheckResult: &openfga.BatchCheckSingleResult_Allowed{Allowed: allow(check.TupleKey)},
+		}
+	}
+	return resp
+}
+
+// ============================================================
+// BatchCheckPermission — batched Check with shared cache
+// ============================================================
+
+func TestBatchCheckPermission_ReturnsDecisionPerItem(t *testing.T) {
+	allowedUID := uuid.Must(uuid.NewV4())
+	deniedUID := uuid.Must(uuid.NewV4())
+	var calls int
+
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			calls++
+			for _, check := range req.Checks {
+				if check.TupleKey.User != "user:"+testUserUID {
+					t.Errorf("expected user subject, got %s", check.TupleKey.User)
+				}
+			}
+			return allowedBatchResponse(req, func(tk *openfga.CheckRequestTupleKey) bool {
+				return tk.Object == "file:"+allowedUID.String()
+			}), nil
+		},
+	}
+	c := newTestClient(fga)
+
+	allowed := CheckItem{ObjectType: "file", ObjectUID: allowedUID, Role: "reader"}
+	denied := CheckItem{ObjectType: "file", ObjectUID: deniedUID, Role: "reader"}
+	got, err := c.BatchCheckPermission(userCtx(testUserUID), []CheckItem{allowed, denied, allowed})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single BatchCheck call, got %d", calls)
+	}
+	if len(got) != 2 {
+		t.Errorf("duplicates should collapse: expected 2 results, got %d", len(got))
+	}
+	if !got[allowed] || got[denied] {
+		t.Errorf("unexpected decisions: %v", got)
+	}
+}
+
+func TestBatchCheckPermission_EmptyInputSkipsFGA(t *testing.T) {
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, _ *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			t.Fatal("empty input should not call FGA")
+			return nil, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	got, err := c.BatchCheckPermission(context.Background(), nil)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected empty result, got %v / %v", got, err)
+	}
+}
+
+func TestBatchCheckPermission_NoIdentity_Unauthenticated(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+
+	_, err := c.BatchCheckPermission(context.Background(), []CheckItem{{ObjectType: "file", ObjectUID: testObjectUID, Role: "reader"}})
+	if !errors.Is(err, errorsx.ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestBatchCheckPermission_ServesHitsFromCacheAndCachesMisses(t *testing.T) {
+	cachedUID := uuid.Must(uuid.NewV4())
+	missUID := uuid.Must(uuid.NewV4())
+	var checkedObjects []string
+
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			for _, check := range req.Checks {
+				checkedObjects = append(checkedObjects, check.TupleKey.Object)
+			}
+			return allowedBatchResponse(req, func(*openfga.CheckRequestTupleKey) bool { return true }), nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+
+	// Seed a cached denial written by CheckPermission.
+	_ = mr.Set(permissionCacheKey("user", testUserUID, "file", cachedUID.String(), "reader"), "0")
+
+	cached := CheckItem{ObjectType: "file", ObjectUID: cachedUID, Role: "reader"}
+	miss := CheckItem{ObjectType: "file", ObjectUID: missUID, Role: "reader"}
+	got, err := c.BatchCheckPermission(userCtx(testUserUID), []CheckItem{cached, miss})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[cached] {
+		t.Error("cached denial should be served from Redis")
+	}
+	if !got[miss] {
+		t.Error("miss should be resolved by FGA")
+	}
+	if len(checkedObjects) != 1 || checkedObjects[0] != "file:"+missUID.String() {
+		t.Errorf("only the miss should reach FGA, got %v", checkedObjects)
+	}
+
+	v, err := mr.Get(permissionCacheKey("user", testUserUID, "file", missUID.String(), "reader"))
+	if err != nil || v != "1" {
+		t.Errorf("miss should be written back to the cache, got %q (%v)", v, err)
+	}
+
+	// The write-back is shared with CheckPermission.
+	fga.checkFn = func(_ context.Context, _ *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+		t.Error("CheckPermission should reuse the batch-cached decision")
+		return &openfga.CheckResponse{}, nil
+	}
+	if ok, _ := c.CheckPermission(userCtx(testUserUID), "file", missUID, "reader"); !ok {
+		t.Error("expected cached grant")
+	}
+}
+
+func TestBatchCheckPermission_ForceConsistencyBypassesCache(t *testing.T) {
+	var calls int
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			calls++
+			if req.Consistency != openfga.ConsistencyPreference_HIGHER_CONSISTENCY {
+				t.Errorf("expected HIGHER_CONSISTENCY, got %s", req.Consistency)
+			}
+			return allowedBatchResponse(req, func(*openfga.CheckRequestTupleKey) bool { return true }), nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+
+	key := permissionCacheKey("user", testUserUID, "file", testObjectUID.String(), "reader")
+	_ = mr.Set(key, "0")
+
+	ctx := context.WithValue(userCtx(testUserUID), ContextKeyForceHigherConsistency, true)
+	item := CheckItem{ObjectType: "file", ObjectUID: testObjectUID, Role: "reader"}
+	got, err := c.BatchCheckPermission(ctx, []CheckItem{item})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || !got[item] {
+		t.Errorf("forced consistency should ignore the cached denial: calls=%d got=%v", calls, got)
+	}
+	if v, _ := mr.Get(key); v != "0" {
+		t.Errorf("forced consistency should not write to the cache, got %q", v)
+	}
+}
+
+func TestBatchCheckPermission_PinnedUserUsesHigherConsistency(t *testing.T) {
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			if req.Consistency != openfga.ConsistencyPreference_HIGHER_CONSISTENCY {
+				t.Errorf("pinned user should use HIGHER_CONSISTENCY, got %s", req.Consistency)
+			}
+			return allowedBatchResponse(req, func(*openfga.CheckRequestTupleKey) bool { return true }), nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+	c.config.Replica.ReplicationTimeFrame = 30
+	ctx := userCtx(testUserUID)
+	c.PinUserForConsistency(ctx)
+
+	if _, err := c.BatchCheckPermission(ctx, []CheckItem{{ObjectType: "file", ObjectUID: testObjectUID, Role: "reader"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBatchCheckPermission_SplitsOversizedBatches(t *testing.T) {
+	var batchSizes []int
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			batchSizes = append(batchSizes, len(req.Checks))
+			return allowedBatchResponse(req, func(*openfga.CheckRequestTupleKey) bool { return true }), nil
+		},
+	}
+	c := newTestClient(fga)
+
+	items := make([]CheckItem, MaxChecksPerBatchCheck+1)
+	for i := range items {
+		items[i] = CheckItem{ObjectType: "file", ObjectUID: uuid.Must(uuid.NewV4()), Role: "reader"}
+	}
+	got, err := c.BatchCheckPermission(userCtx(testUserUID), items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batchSizes) != 2 || batchSizes[0] != MaxChecksPerBatchCheck || batchSizes[1] != 1 {
+		t.Errorf("expected batches of [%d 1], got %v", MaxChecksPerBatchCheck, batchSizes)
+	}
+	if len(got) != len(items) {
+		t.Errorf("expected %d results, got %d", len(items), len(got))
+	}
+}
+
+func TestBatchCheckPermission_ItemErrorFailsCall(t *testing.T) {
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			return &openfga.BatchCheckResponse{Result: map[string]*openfga.BatchCheckSingleResult{
+				req.Checks[0].CorrelationId: {
+					CheckResult: &openfga.BatchCheckSingleResult_Error{Error: &openfga.CheckError{Message: "relation not found"}},
+				},
+			}}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	_, err := c.BatchCheckPermission(userCtx(testUserUID), []CheckItem{{ObjectType: "file", ObjectUID: testObjectUID, Role: "bogus"}})
+	if err == nil {
+		t.Fatal("per-item FGA error should fail the call")
+	}
+}
+
+func TestBatchCheckPermission_FGAErrorPropagated(t *testing.T) {
+	fga := &mockFGA{
+		batchCheckFn: func(_ context.Context, _ *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			return nil, fmt.Errorf("connection refused")
+		},
+	}
+	c := newTestClient(fga)
+
+	_, err := c.BatchCheckPermission(userCtx(testUserUID), []CheckItem{{ObjectType: "file", ObjectUID: testObjectUID, Role: "reader"}})
+	if err == nil {
+		t.Fatal("FGA error should propagate")
+	}
+}
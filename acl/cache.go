@@ -0,0 +1,114 @@
+package acl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache stores CheckPermission / ListPermissions results and the
+// per-user read-after-write pins for an ACLClient.
+//
+// Cached values are not deleted one by one when permissions change.
+// Instead, every entry is tagged with the objects, subjects and object
+// types it depends on (see ObjectCacheTag, SubjectCacheTag and
+// ObjectTypeCacheTag) and the Invalidate methods drop every entry
+// carrying the given tag, ideally in O(1).
+//
+// Two implementations are provided: RedisCache, shared by every replica
+// of a service, and MemoryCache, for services without Redis and tests.
+type Cache interface {
+	// Get returns the cached value of each entry, or "" on a miss. It
+	// sets the Version of every entry, which Set relies on.
+	Get(ctx context.Context, entries []*CacheEntry) ([]string, error)
+	// Set stores values for entries previously passed to Get. Values
+	// computed across an invalidation of the entries' tags are dropped.
+	Set(ctx context.Context, entries []*CacheEntry, values []string) error
+	// Delete removes entries from the cache.
+	Delete(ctx context.Context, entries []*CacheEntry) error
+	// InvalidateObject drops every entry that depends on the tuples of
+	// the given object.
+	InvalidateObject(ctx context.Context, objectType, objectUID string) error
+	// InvalidateUser drops every entry computed for the given FGA
+	// subject, e.g. "user:<UUID>".
+	InvalidateUser(ctx context.Context, user string) error
+	// InvalidateObjectType drops every entry that depends on all the
+	// objects of a type, such as the ListPermissions results affected
+	// by a public (wildcard) grant.
+	InvalidateObjectType(ctx context.Context, objectType string) error
+	// Pin directs the subject's reads to the primary for ttl, for
+	// read-after-write consistency.
+	Pin(ctx context.Context, subjectUID string, ttl time.Duration) error
+	// IsPinned reports whether the subject is pinned. Implementations
+	// should report true when they cannot tell, so callers fall back to
+	// the consistent path.
+	IsPinned(ctx context.Context, subjectUID string) bool
+	// Close releases the background resources held by the cache.
+	Close() error
+}
+
+// CacheEntry identifies a cached value.
+type CacheEntry struct {
+	// Key is the cache key, e.g. a permissionCacheKey.
+	Key string
+	// Tags lists the invalidation scopes the value depends on.
+	Tags []string
+	// Version is an opaque value set by Cache.Get and passed back to
+	// Cache.Set, so that a value computed across an invalidation is
+	// never served.
+	Version string
+}
+
+// CacheBackend selects the Cache implementation NewClient builds.
+type CacheBackend string
+
+const (
+	// CacheBackendRedis caches in the Redis given to NewClient. This is
+	// the default.
+	CacheBackendRedis CacheBackend = "redis"
+	// CacheBackendMemory caches in process. Invalidations are not
+	// shared across replicas, so it is meant for single-replica
+	// deployments and tests.
+	CacheBackendMemory CacheBackend = "memory"
+)
+
+// newCache builds the Cache selected by cfg. It returns nil when the
+// Redis backend is selected but no Redis client is available.
+func newCache(redisClient *redis.Client, cfg CacheConfig) Cache {
+	if cfg.Backend == CacheBackendMemory {
+		return NewMemoryCache(cfg)
+	}
+	if redisClient == nil {
+		return nil
+	}
+	return NewRedisCache(redisClient, cfg)
+}
+
+// ObjectCacheTag tags entries that depend on the tuples of one object.
+func ObjectCacheTag(objectType, objectUID string) string {
+	return "obj:" + objectType + ":" + objectUID
+}
+
+// SubjectCacheTag tags entries computed for one FGA subject, e.g.
+// "user:<UUID>".
+func SubjectCacheTag(user string) string {
+	return "subj:" + user
+}
+
+// ObjectTypeCacheTag tags entries that depend on every object of a type.
+func ObjectTypeCacheTag(objectType string) string {
+	return "type:" + objectType
+}
+
+// versionedCacheKey appends the tag versions to base. Unversioned tags
+// ("0") leave the legacy key untouched.
+func versionedCacheKey(base string, versions []string) string {
+	for _, v := range versions {
+		if v != "0" {
+			return base + ":v" + strings.Join(versions, ".")
+		}
+	}
+	return base
+}
@@ -0,0 +1,140 @@
+package acl
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-process implementation of Cache with TTL
+// expiry. Invalidations and pins are not shared across replicas, so it
+// is meant for services running a single replica or without Redis,
+// and for tests.
+type MemoryCache struct {
+	entries *localCache
+
+	mu   sync.Mutex
+	pins map[string]time.Time
+	// sweepAt is the number of pins past which Pin drops the expired
+	// ones, so subjects pinned once and never checked again don't
+	// accumulate.
+	sweepAt int
+	now     func() time.Time
+}
+
+// minPinSweep is the smallest number of pins Pin lets accumulate
+// before sweeping the expired ones.
+const minPinSweep = 1024
+
+// NewMemoryCache returns an in-process Cache holding at most
+// cfg.MemorySize entries for cfg.TTL.
+func NewMemoryCache(cfg CacheConfig) *MemoryCache {
+	size := cfg.MemorySize
+	if size <= 0 {
+		size = DefaultMemoryCacheSize
+	}
+	return &MemoryCache{
+		entries: newLocalCache(size, cfg.CacheTTLDuration()),
+		pins:    map[string]time.Time{},
+		sweepAt: minPinSweep,
+		now:     time.Now,
+	}
+}
+
+// Get implements Cache.
+func (c *MemoryCache) Get(ctx context.Context, entries []*CacheEntry) ([]string, error) {
+	epoch := strconv.FormatUint(c.entries.snapshot(), 10)
+	values := make([]string, len(entries))
+	hits := 0
+	for i, e := range entries {
+		e.Version = epoch
+		var ok bool
+		if values[i], ok = c.entries.get(e.Key); ok {
+			hits++
+		}
+	}
+	metrics.recordCache(ctx, cacheLayerMemory, "hit", hits)
+	metrics.recordCache(ctx, cacheLayerMemory, "miss", len(entries)-hits)
+	return values, nil
+}
+
+// Set implements Cache.
+func (c *MemoryCache) Set(_ context.Context, entries []*CacheEntry, values []string) error {
+	for i, e := range entries {
+		epoch, err := strconv.ParseUint(e.Version, 10, 64)
+		if err != nil {
+			continue
+		}
+		c.entries.set(e.Key, values[i], e.Tags, epoch)
+	}
+	return nil
+}
+
+// Delete implements Cache.
+func (c *MemoryCache) Delete(_ context.Context, entries []*CacheEntry) error {
+	for _, e := range entries {
+		c.entries.delete(e.Key)
+	}
+	return nil
+}
+
+// InvalidateObject implements Cache.
+func (c *MemoryCache) InvalidateObject(_ context.Context, objectType, objectUID string) error {
+	c.entries.invalidate(ObjectCacheTag(objectType, objectUID))
+	return nil
+}
+
+// InvalidateUser implements Cache.
+func (c *MemoryCache) InvalidateUser(_ context.Context, user string) error {
+	c.entries.invalidate(SubjectCacheTag(user))
+	return nil
+}
+
+// InvalidateObjectType implements Cache.
+func (c *MemoryCache) InvalidateObjectType(_ context.Context, objectType string) error {
+	c.entries.invalidate(ObjectTypeCacheTag(objectType))
+	return nil
+}
+
+// Pin implements Cache.
+func (c *MemoryCache) Pin(_ context.Context, subjectUID string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.pins[subjectUID] = now.Add(ttl)
+	if len(c.pins) < c.sweepAt {
+		return nil
+	}
+	for uid, expiresAt := range c.pins {
+		if now.After(expiresAt) {
+			delete(c.pins, uid)
+		}
+	}
+	// Sweeping again only once the live pins have doubled keeps Pin
+	// amortised constant time.
+	c.sweepAt = max(2*len(c.pins), minPinSweep)
+	return nil
+}
+
+// IsPinned implements Cache.
+func (c *MemoryCache) IsPinned(_ context.Context, subjectUID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt, ok := c.pins[subjectUID]
+	if !ok {
+		return false
+	}
+	if c.now().After(expiresAt) {
+		delete(c.pins, subjectUID)
+		return false
+	}
+	return true
+}
+
+// Close implements Cache.
+func (c *MemoryCache) Close() error {
+	return nil
+}
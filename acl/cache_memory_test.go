@@ -0,0 +1,81 @@
+package acl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// ============================================================
+// MemoryCache — in-process backend
+// ============================================================
+
+func TestMemoryCache_InvalidateDropsTaggedEntries(t *testing.T) {
+	c := NewMemoryCache(CacheConfig{})
+	ctx := context.Background()
+
+	perm := checkCacheEntry("user", testUserUID, "file", testObjectUID.String(), "reader")
+	list := listCacheEntry("user", testUserUID, "file", "reader")
+	entries := []*CacheEntry{perm, list}
+	_, _ = c.Get(ctx, entries)
+	_ = c.Set(ctx, entries, []string{"1", "[]"})
+
+	_ = c.InvalidateObjectType(ctx, "file")
+
+	values, _ := c.Get(ctx, entries)
+	if values[0] != "1" {
+		t.Error("check entry does not depend on the object type")
+	}
+	if values[1] != "" {
+		t.Error("list entry should be invalidated")
+	}
+}
+
+func TestMemoryCache_SetAcrossInvalidationIsDropped(t *testing.T) {
+	c := NewMemoryCache(CacheConfig{})
+	ctx := context.Background()
+
+	entry := checkCacheEntry("user", testUserUID, "file", testObjectUID.String(), "reader")
+	_, _ = c.Get(ctx, []*CacheEntry{entry})
+	_ = c.InvalidateUser(ctx, "user:"+testUserUID)
+	_ = c.Set(ctx, []*CacheEntry{entry}, []string{"1"})
+
+	if values, _ := c.Get(ctx, []*CacheEntry{entry}); values[0] != "" {
+		t.Error("a value computed before the invalidation must not be served")
+	}
+}
+
+func TestMemoryCache_PinsExpire(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryCache(CacheConfig{})
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_ = c.Pin(ctx, testUserUID, time.Second)
+	if !c.IsPinned(ctx, testUserUID) {
+		t.Fatal("user should be pinned")
+	}
+
+	now = now.Add(2 * time.Second)
+	if c.IsPinned(ctx, testUserUID) {
+		t.Error("pin should expire")
+	}
+}
+
+func TestMemoryCache_PinSweepsExpiredPins(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryCache(CacheConfig{})
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := range minPinSweep - 1 {
+		_ = c.Pin(ctx, strconv.Itoa(i), time.Second)
+	}
+	now = now.Add(2 * time.Second)
+	_ = c.Pin(ctx, testUserUID, time.Second)
+
+	if len(c.pins) != 1 || !c.IsPinned(ctx, testUserUID) {
+		t.Errorf("expected only the live pin to be kept, got %d pins", len(c.pins))
+	}
+}
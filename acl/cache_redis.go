@@ -0,0 +1,328 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// RedisCache is the Redis implementation of Cache.
+//
+// Each tag has a version stored under CacheVersionPrefix, and the
+// versions of an entry's tags are appended to its Redis key, so
+// bumping a version makes every dependent entry unreachable in O(1);
+// orphaned entries simply expire with the cache TTL.
+//
+// While no version has been bumped for an entry's tags, its key is the
+// plain acl:perm: / acl:list: key used by earlier releases, which keeps
+// mixed-version deployments sharing a Redis coherent during rollout
+// (see CacheConfig.LegacyInvalidation).
+//
+// When CacheConfig.Local is enabled, values are also kept in an
+// in-process LRU keyed by the unversioned key. Invalidations are
+// published on CacheInvalidationChannel so every replica drops the
+// affected local entries.
+type RedisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+	legacy bool
+	local  *localCache
+	sub    *redis.PubSub
+}
+
+// NewRedisCache returns a Cache backed by client. The client is owned
+// by the caller and is not closed by Close.
+func NewRedisCache(client *redis.Client, cfg CacheConfig) *RedisCache {
+	c := &RedisCache{
+		client: client,
+		ttl:    cfg.CacheTTLDuration(),
+		legacy: cfg.LegacyInvalidation,
+	}
+	if cfg.Local.Enabled {
+		local := cfg.Local.resolved()
+		c.local = newLocalCache(local.Size, local.TTLDuration())
+		c.subscribe()
+	}
+	return c
+}
+
+// subscribe keeps the local cache coherent with the invalidations
+// published by other replicas. Events missed while the subscription
+// reconnects are bounded by the local cache TTL.
+func (c *RedisCache) subscribe() {
+	c.sub = c.client.Subscribe(context.Background(), CacheInvalidationChannel)
+	ch := c.sub.Channel()
+	go func() {
+		for msg := range ch {
+			c.local.invalidate(strings.Fields(msg.Payload)...)
+		}
+	}()
+}
+
+// Close stops the invalidation subscription, if any.
+func (c *RedisCache) Close() error {
+	if c.sub == nil {
+		return nil
+	}
+	if err := c.sub.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// redisCacheVersion packs the local cache epoch and the resolved Redis
+// key into CacheEntry.Version.
+func redisCacheVersion(epoch uint64, key string) string {
+	return strconv.FormatUint(epoch, 10) + "/" + key
+}
+
+func parseRedisCacheVersion(version string) (epoch uint64, key string, ok bool) {
+	e, key, ok := strings.Cut(version, "/")
+	if !ok {
+		return 0, "", false
+	}
+	epoch, err := strconv.ParseUint(e, 10, 64)
+	return epoch, key, err == nil
+}
+
+// Get implements Cache.
+func (c *RedisCache) Get(ctx context.Context, entries []*CacheEntry) ([]string, error) {
+	values := make([]string, len(entries))
+
+	var epoch uint64
+	if c.local != nil {
+		epoch = c.local.snapshot()
+	}
+	pending := make([]*CacheEntry, 0, len(entries))
+	pendingIdx := make([]int, 0, len(entries))
+	for i, e := range entries {
+		e.Version = ""
+		if c.local != nil {
+			if v, ok := c.local.get(e.Key); ok {
+				values[i] = v
+				continue
+			}
+		}
+		pending = append(pending, e)
+		pendingIdx = append(pendingIdx, i)
+	}
+	if c.local != nil {
+		metrics.recordCache(ctx, cacheLayerLocal, "hit", len(entries)-len(pending))
+		metrics.recordCache(ctx, cacheLayerLocal, "miss", len(pending))
+	}
+	if len(pending) == 0 {
+		return values, nil
+	}
+
+	keys, err := c.resolveKeys(ctx, pending)
+	if err != nil {
+		metrics.recordCache(ctx, cacheLayerRedis, "error", len(pending))
+		return values, err
+	}
+	for i, e := range pending {
+		e.Version = redisCacheVersion(epoch, keys[i])
+	}
+
+	cached, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		metrics.recordCache(ctx, cacheLayerRedis, "error", len(pending))
+		return values, err
+	}
+	hits := 0
+	for i, v := range cached {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		hits++
+		values[pendingIdx[i]] = s
+		if c.local != nil {
+			c.local.set(pending[i].Key, s, pending[i].Tags, epoch)
+		}
+	}
+	metrics.recordCache(ctx, cacheLayerRedis, "hit", hits)
+	metrics.recordCache(ctx, cacheLayerRedis, "miss", len(pending)-hits)
+	return values, nil
+}
+
+// resolveKeys returns the versioned Redis key of every entry, resolving
+// the versions of every distinct tag in one round-trip.
+func (c *RedisCache) resolveKeys(ctx context.Context, entries []*CacheEntry) ([]string, error) {
+	tagIdx := map[string]int{}
+	var versionKeys []string
+	for _, e := range entries {
+		for _, tag := range e.Tags {
+			if _, ok := tagIdx[tag]; !ok {
+				tagIdx[tag] = len(versionKeys)
+				versionKeys = append(versionKeys, CacheVersionPrefix+tag)
+			}
+		}
+	}
+
+	var versions []any
+	if len(versionKeys) > 0 {
+		var err error
+		if versions, err = c.client.MGet(ctx, versionKeys...).Result(); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		v := make([]string, len(e.Tags))
+		for j, tag := range e.Tags {
+			v[j] = "0"
+			if s, ok := versions[tagIdx[tag]].(string); ok {
+				v[j] = s
+			}
+		}
+		keys[i] = versionedCacheKey(e.Key, v)
+	}
+	return keys, nil
+}
+
+// Set implements Cache. Entries whose version could not be resolved
+// by Get are skipped.
+func (c *RedisCache) Set(ctx context.Context, entries []*CacheEntry, values []string) error {
+	pipe := c.client.Pipeline()
+	for i, e := range entries {
+		epoch, key, ok := parseRedisCacheVersion(e.Version)
+		if !ok {
+			continue
+		}
+		pipe.Set(ctx, key, values[i], c.ttl)
+		if c.local != nil {
+			c.local.set(e.Key, values[i], e.Tags, epoch)
+		}
+	}
+	if pipe.Len() == 0 {
+		return nil
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Delete implements Cache.
+func (c *RedisCache) Delete(ctx context.Context, entries []*CacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	keys, err := c.resolveKeys(ctx, entries)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+		if c.local != nil {
+			c.local.delete(e.Key)
+		}
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// InvalidateObject implements Cache.
+func (c *RedisCache) InvalidateObject(ctx context.Context, objectType, objectUID string) error {
+	err := c.invalidateTags(ctx, ObjectCacheTag(objectType, objectUID))
+	if c.legacy {
+		// Cache key format: acl:perm:userType:userUID:objectType:objectUID:role
+		// Pattern should match any user and any role for this specific object
+		c.deleteLegacyKeys(ctx, fmt.Sprintf("%s*:%s:%s:*", PermissionCachePrefix, objectType, objectUID))
+	}
+	return err
+}
+
+// InvalidateUser implements Cache.
+func (c *RedisCache) InvalidateUser(ctx context.Context, user string) error {
+	err := c.invalidateTags(ctx, SubjectCacheTag(user))
+	if c.legacy {
+		c.deleteLegacyKeys(ctx, fmt.Sprintf("%s%s:*", ListPermissionsCachePrefix, user))
+	}
+	return err
+}
+
+// InvalidateObjectType implements Cache.
+func (c *RedisCache) InvalidateObjectType(ctx context.Context, objectType string) error {
+	err := c.invalidateTags(ctx, ObjectTypeCacheTag(objectType))
+	if c.legacy {
+		c.deleteLegacyKeys(ctx, fmt.Sprintf("%s*:%s:*", ListPermissionsCachePrefix, objectType))
+	}
+	return err
+}
+
+// invalidateTags bumps the version of each tag, drops the matching
+// local entries and notifies the other replicas.
+//
+// Versions are timestamps rather than counters so that they can expire:
+// a version outlives every entry written under the previous one by at
+// least one TTL, after which the tag safely falls back to "0".
+func (c *RedisCache) invalidateTags(ctx context.Context, tags ...string) error {
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+	pipe := c.client.Pipeline()
+	for _, tag := range tags {
+		pipe.Set(ctx, CacheVersionPrefix+tag, version, 2*c.ttl)
+	}
+	if c.local != nil {
+		c.local.invalidate(tags...)
+		pipe.Publish(ctx, CacheInvalidationChannel, strings.Join(tags, " "))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// deleteLegacyKeys deletes the keys matching pattern with SCAN. This
+// is only needed while CacheConfig.LegacyInvalidation is on.
+func (c *RedisCache) deleteLegacyKeys(ctx context.Context, pattern string) {
+	log, _ := logx.GetZapLogger(ctx)
+
+	var cursor uint64
+	var deletedCount int
+	for {
+		var keys []string
+		var err error
+		keys, cursor, err = c.client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			log.Warn("Failed to scan cache keys for invalidation", zap.Error(err), zap.String("pattern", pattern))
+			return
+		}
+		if len(keys) > 0 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				log.Warn("Failed to delete cache keys", zap.Error(err), zap.Strings("keys", keys))
+			} else {
+				deletedCount += len(keys)
+			}
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if deletedCount > 0 {
+		log.Debug("Legacy cache invalidation completed",
+			zap.String("pattern", pattern),
+			zap.Int("deletedKeys", deletedCount),
+		)
+	}
+}
+
+// pinKey is the Redis key pinning a subject to the primary.
+func pinKey(subjectUID string) string {
+	return fmt.Sprintf("db_pin_user:%s:openfga", subjectUID)
+}
+
+// Pin implements Cache.
+func (c *RedisCache) Pin(ctx context.Context, subjectUID string, ttl time.Duration) error {
+	return c.client.Set(ctx, pinKey(subjectUID), time.Now(), ttl).Err()
+}
+
+// IsPinned implements Cache.
+func (c *RedisCache) IsPinned(ctx context.Context, subjectUID string) bool {
+	return !errors.Is(c.client.Get(ctx, pinKey(subjectUID)).Err(), redis.Nil)
+}
@@ -0,0 +1,146 @@
+package acl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisCache returns a RedisCache over the Redis served by mr.
+func newTestRedisCache(t *testing.T, mr *miniredis.Miniredis, cfg CacheConfig) *RedisCache {
+	t.Helper()
+	c := NewRedisCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), cfg)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// ============================================================
+// RedisCache — versioned keys
+// ============================================================
+
+func TestRedisCache_InvalidateObjectBumpsVersionWithoutScan(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr, CacheConfig{})
+	ctx := context.Background()
+
+	entry := checkCacheEntry("user", testUserUID, "file", testObjectUID.String(), "reader")
+	_ = mr.Set(entry.Key, "1")
+	if values, _ := c.Get(ctx, []*CacheEntry{entry}); values[0] != "1" {
+		t.Fatal("legacy entry should be served while the object is unversioned")
+	}
+
+	if err := c.InvalidateObject(ctx, "file", testObjectUID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if values, _ := c.Get(ctx, []*CacheEntry{entry}); values[0] != "" {
+		t.Error("entry should be unreachable after the version bump")
+	}
+	if !mr.Exists(entry.Key) {
+		t.Error("without legacy invalidation the old key is left to expire")
+	}
+	if !mr.Exists(CacheVersionPrefix + ObjectCacheTag("file", testObjectUID.String())) {
+		t.Error("object version should be stored")
+	}
+}
+
+func TestRedisCache_LegacyInvalidationDeletesOldKeys(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr, CacheConfig{LegacyInvalidation: true})
+
+	key := permissionCacheKey("user", testUserUID, "file", testObjectUID.String(), "reader")
+	_ = mr.Set(key, "1")
+
+	if err := c.InvalidateObject(context.Background(), "file", testObjectUID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mr.Exists(key) {
+		t.Error("legacy invalidation should delete the unversioned key")
+	}
+}
+
+func TestRedisCache_SetAcrossInvalidationIsNotServed(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr, CacheConfig{})
+	ctx := context.Background()
+
+	entry := checkCacheEntry("user", testUserUID, "file", testObjectUID.String(), "reader")
+	if _, err := c.Get(ctx, []*CacheEntry{entry}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = c.InvalidateObject(ctx, "file", testObjectUID.String())
+	if err := c.Set(ctx, []*CacheEntry{entry}, []string{"1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if values, _ := c.Get(ctx, []*CacheEntry{entry}); values[0] != "" {
+		t.Error("a value computed before the invalidation must not be served")
+	}
+}
+
+func TestRedisCache_PinUsesLegacyKey(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr, CacheConfig{})
+	ctx := context.Background()
+
+	if c.IsPinned(ctx, testUserUID) {
+		t.Fatal("user should not be pinned yet")
+	}
+	if err := c.Pin(ctx, testUserUID, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mr.Exists("db_pin_user:" + testUserUID + ":openfga") {
+		t.Error("pin key should stay compatible with earlier releases")
+	}
+	if !c.IsPinned(ctx, testUserUID) {
+		t.Error("user should be pinned")
+	}
+}
+
+// ============================================================
+// RedisCache — local layer kept coherent over pub/sub
+// ============================================================
+
+func TestRedisCache_LocalLayerServesHitsWithoutRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr, CacheConfig{Local: LocalCacheConfig{Enabled: true}})
+	ctx := context.Background()
+
+	entry := checkCacheEntry("user", testUserUID, "file", testObjectUID.String(), "reader")
+	if _, err := c.Get(ctx, []*CacheEntry{entry}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Set(ctx, []*CacheEntry{entry}, []string{"1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mr.FlushAll()
+	if values, _ := c.Get(ctx, []*CacheEntry{entry}); values[0] != "1" {
+		t.Error("local entry should be served without Redis")
+	}
+}
+
+func TestRedisCache_InvalidationReachesOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := CacheConfig{Local: LocalCacheConfig{Enabled: true}}
+	writer := newTestRedisCache(t, mr, cfg)
+	reader := newTestRedisCache(t, mr, cfg)
+
+	entry := checkCacheEntry("user", testUserUID, "file", testObjectUID.String(), "reader")
+	reader.local.set(entry.Key, "1", entry.Tags, reader.local.snapshot())
+
+	if err := writer.InvalidateObject(context.Background(), "file", testObjectUID.String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for reader.local.len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the other replica's local entry should be dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,129 @@
+package acl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// isCached reports whether entry is currently served from the client's
+// cache.
+func isCached(t *testing.T, c *ACLClient, entry *CacheEntry) bool {
+	t.Helper()
+	e := &CacheEntry{Key: entry.Key, Tags: entry.Tags}
+	values, err := c.cache.Get(context.Background(), []*CacheEntry{e})
+	if err != nil {
+		t.Fatalf("unexpected cache error: %v", err)
+	}
+	return values[0] != ""
+}
+
+// seedCache stores values for entries through the client's cache.
+func seedCache(t *testing.T, c *ACLClient, entries []*CacheEntry, values []string) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := c.cache.Get(ctx, entries); err != nil {
+		t.Fatalf("unexpected cache error: %v", err)
+	}
+	if err := c.cache.Set(ctx, entries, values); err != nil {
+		t.Fatalf("unexpected cache error: %v", err)
+	}
+}
+
+// ============================================================
+// Cache — backend selection and client integration
+// ============================================================
+
+func TestVersionedCacheKey_UnversionedTagsKeepLegacyKey(t *testing.T) {
+	base := permissionCacheKey("user", testUserUID, "file", testObjectUID.String(), "reader")
+
+	if got := versionedCacheKey(base, []string{"0", "0"}); got != base {
+		t.Errorf("unversioned tags should keep the legacy key, got %s", got)
+	}
+	if got := versionedCacheKey(base, []string{"42", "0"}); got != base+":v42.0" {
+		t.Errorf("unexpected versioned key %s", got)
+	}
+}
+
+func TestNewCache_SelectsBackend(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	if c := newCache(nil, CacheConfig{}); c != nil {
+		t.Errorf("redis backend without a client should disable caching, got %T", c)
+	}
+	if _, ok := newCache(rc, CacheConfig{}).(*RedisCache); !ok {
+		t.Error("redis should be the default backend")
+	}
+	if _, ok := newCache(nil, CacheConfig{Backend: CacheBackendMemory}).(*MemoryCache); !ok {
+		t.Error("memory backend should not need Redis")
+	}
+}
+
+func TestCheckPermission_CachesUnderCurrentVersion(t *testing.T) {
+	var calls int
+	fga := &mockFGA{
+		checkFn: func(_ context.Context, _ *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			calls++
+			return &openfga.CheckResponse{Allowed: calls > 1}, nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+	ctx := userCtx(testUserUID)
+
+	c.invalidateObjectCache(ctx, "file", testObjectUID.String())
+	if ok, _ := c.CheckPermission(ctx, "file", testObjectUID, "reader"); ok {
+		t.Fatal("first check should be denied")
+	}
+	if ok, _ := c.CheckPermission(ctx, "file", testObjectUID, "reader"); ok || calls != 1 {
+		t.Fatalf("second check should be served from the cache, calls=%d", calls)
+	}
+
+	c.invalidateListPermissionsCacheForUser(ctx, "user:"+testUserUID)
+	if ok, _ := c.CheckPermission(ctx, "file", testObjectUID, "reader"); !ok || calls != 2 {
+		t.Errorf("subject invalidation should reach CheckPermission entries, calls=%d", calls)
+	}
+}
+
+func TestMemoryCacheClient_CachesAndPinsWithoutRedis(t *testing.T) {
+	var calls int
+	fga := &mockFGA{
+		checkFn: func(_ context.Context, req *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			calls++
+			if calls == 2 && req.Consistency != openfga.ConsistencyPreference_HIGHER_CONSISTENCY {
+				t.Errorf("pinned user should use HIGHER_CONSISTENCY, got %s", req.Consistency)
+			}
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	}
+	c := newTestClient(fga)
+	c.cache = NewMemoryCache(CacheConfig{})
+	c.cacheEnabled = true
+	c.config.Replica.ReplicationTimeFrame = 30
+	ctx := userCtx(testUserUID)
+
+	for range 2 {
+		if ok, err := c.CheckPermission(ctx, "file", testObjectUID, "reader"); !ok || err != nil {
+			t.Fatalf("unexpected result: %v / %v", ok, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("second check should be served from the memory cache, got %d calls", calls)
+	}
+
+	c.PinUserForConsistency(ctx)
+	if !c.IsUserPinned(ctx) {
+		t.Fatal("user should be pinned in memory")
+	}
+	if _, err := c.CheckPermission(ctx, "file", testObjectUID, "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("pinned user should bypass the cache, got %d calls", calls)
+	}
+}
@@ -3,10 +3,12 @@ package acl
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -37,13 +39,26 @@ type Client interface {
 	Purge(ctx context.Context, objectType string, objectUID uuid.UUID) error
 	// CheckPermission verifies if the current user has a specific role for an object.
 	CheckPermission(ctx context.Context, objectType string, objectUID uuid.UUID, role string) (bool, error)
+	// BatchCheckPermission verifies several (object, role) pairs for the
+	// current user in a single round-trip. See the method doc for the
+	// caching and consistency contract.
+	BatchCheckPermission(ctx context.Context, items []CheckItem) (map[CheckItem]bool, error)
 	// CheckPublicExecutable checks if an object has public execute permission.
 	CheckPublicExecutable(ctx context.Context, objectType string, objectUID uuid.UUID) (bool, error)
 	// ListPermissions lists all objects of a type that the current user has a role for.
 	ListPermissions(ctx context.Context, objectType string, role string) ([]uuid.UUID, error)
+	// ListPermissionsIter streams the objects ListPermissions returns
+	// without materialising them.
+	ListPermissionsIter(ctx context.Context, objectType string, role string) iter.Seq2[uuid.UUID, error]
+	// ListPermissionsPage returns one page of the objects ListPermissions
+	// returns, in UUID order, with the token of the next page.
+	ListPermissionsPage(ctx context.Context, objectType string, role string, pageSize int32, pageToken string) ([]uuid.UUID, string, error)
 	// ListPublicPermissions lists all objects of a type that are readable by
 	// everyone (tuples keyed to the FGA wildcard `user:*`).
 	ListPublicPermissions(ctx context.Context, objectType string, role string) ([]uuid.UUID, error)
+	// ListSubjects lists every subject that holds a relation on an
+	// object, directly or transitively.
+	ListSubjects(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, subjectTypes []string) ([]Subject, error)
 	// ReadTuples enumerates direct-grant tuples that match the given
 	// filter. Backed by the OpenFGA Read API, which is indexed,
 	// deadline-free, and not subject to the listObjectsDeadline /
@@ -54,16 +69,32 @@ type Client interface {
 	ReadTuples(ctx context.Context, filter ReadTupleFilter) ([]ReadTuple, error)
 	// SetResourcePermission sets a permission for a user on any resource type.
 	SetResourcePermission(ctx context.Context, objectType string, objectUID uuid.UUID, user, role string, enable bool) error
+	// SetResourcePermissionWithCondition grants a permission under an OpenFGA condition.
+	SetResourcePermissionWithCondition(ctx context.Context, objectType string, objectUID uuid.UUID, user, role string, cond *Condition) error
+	// GrantUntil grants a permission that expires at the given time.
+	GrantUntil(ctx context.Context, objectType string, objectUID uuid.UUID, user, role string, expiry time.Time) error
 	// DeleteResourcePermission deletes all permissions for a user on a resource.
 	DeleteResourcePermission(ctx context.Context, objectType string, objectUID uuid.UUID, user string) error
 	// SetPublicPermission sets public reader/executor permissions on a resource.
 	SetPublicPermission(ctx context.Context, objectType string, objectUID uuid.UUID) error
 	// DeletePublicPermission deletes public permissions from a resource.
 	DeletePublicPermission(ctx context.Context, objectType string, objectUID uuid.UUID) error
+	// TransferOwnership replaces the owner of an object in a single write.
+	TransferOwnership(ctx context.Context, objectType string, objectUID uuid.UUID, newOwnerType string, newOwnerUID uuid.UUID) error
 	// GetOwner retrieves the owner of a given object.
 	GetOwner(ctx context.Context, objectType string, objectUID uuid.UUID) (ownerType string, ownerUID string, err error)
 	// CheckLinkPermission checks access through a shareable link/code.
 	CheckLinkPermission(ctx context.Context, objectType string, objectUID uuid.UUID, role string, codeHeaderKey string) (bool, error)
+	// ExplainPermission explains the answer of CheckPermission for the request's subject.
+	ExplainPermission(ctx context.Context, objectType string, objectUID uuid.UUID, relation string) (*Explanation, error)
+	// CreateShareLink mints a share link granting a relation on an object.
+	CreateShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, expiry time.Time) (*ShareLink, error)
+	// ListShareLinks returns the share links on an object.
+	ListShareLinks(ctx context.Context, objectType string, objectUID uuid.UUID) ([]ShareLink, error)
+	// RevokeShareLink deletes a share link on an object.
+	RevokeShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, token string) error
+	// RotateShareLink replaces a share link token on an object with a new one.
+	RotateShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, token string) ([]ShareLink, error)
 	// CheckShareLinkPermission checks if a share link token has the specified permission.
 	CheckShareLinkPermission(ctx context.Context, shareToken string, objectType string, objectUID uuid.UUID, relation string) (bool, error)
 	// CheckPermissionWithShareLink runs both an identity-scoped check
@@ -71,8 +102,21 @@ type Client interface {
 	// returns true if EITHER grants access. See the method doc for the
 	// full contract and rationale.
 	CheckPermissionWithShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, shareToken string) (bool, error)
+	// AddMember adds a subject to an organization or a group with a role.
+	AddMember(ctx context.Context, groupType string, groupUID uuid.UUID, user, role string) error
+	// RemoveMember removes a subject from an organization or a group.
+	RemoveMember(ctx context.Context, groupType string, groupUID uuid.UUID, user string) error
+	// SetMemberRole replaces the role of a member of an organization or a group.
+	SetMemberRole(ctx context.Context, groupType string, groupUID uuid.UUID, user, role string) error
+	// ListMembers lists the direct members of an organization or a group.
+	ListMembers(ctx context.Context, groupType string, groupUID uuid.UUID) ([]Membership, error)
+	// ListMemberships lists the organizations or groups a subject is a member of.
+	ListMemberships(ctx context.Context, user, groupType string) ([]Membership, error)
 	// CheckRequesterPermission validates organization impersonation.
 	CheckRequesterPermission(ctx context.Context) error
+	// Begin starts a TupleTx that batches grants and revocations into as
+	// few Write requests as possible.
+	Begin() *TupleTx
 	// IsUserPinned checks if the user is currently pinned to the primary database for read-after-write consistency.
 	// This is used to bypass caches and use HIGHER_CONSISTENCY mode in OpenFGA queries.
 	IsUserPinned(ctx context.Context) bool
@@ -82,80 +126,159 @@ type Client interface {
 type ACLClient struct {
 	writeClient            openfga.OpenFGAServiceClient
 	readClient             openfga.OpenFGAServiceClient
-	redisClient            *redis.Client
+	cache                  Cache // Results and read-after-write pins; nil disables both
+	ownsCache              bool  // Whether Close should close cache
 	storeID                string
-	modelID                string // Cached authorization model ID - fetched once at startup
-	cacheEnabled           bool   // Controls CheckPermission Redis cache
-	listPermissionsCacheOn bool   // Controls ListPermissions / ListPublicPermissions Redis cache
-	cacheTTL               time.Duration
-	listObjectsCfg         ListObjectsConfig // Truncation-guard thresholds for StreamedListObjects
+	modelID                atomic.Pointer[string] // Authorization model ID - resolved at startup, swapped by the model watcher
+	schema                 atomic.Pointer[Schema] // Types and relations of the model; nil disables argument validation
+	stopWatch              context.CancelFunc     // Stops the model watcher; nil when it is not running
+	watchDone              chan struct{}          // Closed when the model watcher returns
+	cacheEnabled           bool                   // Controls CheckPermission cache
+	listPermissionsCacheOn bool                   // Controls ListPermissions / ListPublicPermissions cache
+	listSubjectsCacheOn    bool                   // Controls ListSubjects cache
+	listObjectsCfg         ListObjectsConfig      // Truncation-guard thresholds for StreamedListObjects
+	stale                  *localCache            // Last OpenFGA decisions for FailModeStale; nil when unused
 	config                 Config
 }
 
 // NewClient creates a new ACL client with the given configuration.
 // It auto-discovers the OpenFGA store and fetches the latest authorization model.
+//
+// Results and pins are cached in the backend selected by
+// cfg.Cache.Backend: redisClient for CacheBackendRedis (caching is
+// disabled when it is nil), or in process for CacheBackendMemory.
+//
+// NewClient panics if the store or the model cannot be resolved within
+// DefaultDiscoveryTimeout. Use NewClientWithContext to handle the error.
 func NewClient(wc openfga.OpenFGAServiceClient, rc openfga.OpenFGAServiceClient, redisClient *redis.Client, cfg Config) *ACLClient {
-	if rc == nil {
-		rc = wc
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDiscoveryTimeout)
+	defer cancel()
+
+	c, err := NewClientWithContext(ctx, wc, rc, redisClient, cfg)
+	if err != nil {
+		panic(err.Error())
 	}
+	return c
+}
 
-	// Auto-discover the store from OpenFGA
-	storeResp, err := wc.ListStores(context.Background(), &openfga.ListStoresRequest{})
+// NewClientWithContext creates a new ACL client like NewClient, but
+// returns an error instead of panicking. Store discovery and the model
+// lookup are retried with backoff until they succeed or ctx is done.
+// ctx only bounds the construction; the model watcher runs until Close.
+func NewClientWithContext(ctx context.Context, wc openfga.OpenFGAServiceClient, rc openfga.OpenFGAServiceClient, redisClient *redis.Client, cfg Config) (*ACLClient, error) {
+	cache := newCache(redisClient, cfg.Cache)
+	c, err := NewClientWithCache(ctx, wc, rc, cache, cfg)
 	if err != nil {
-		panic(fmt.Sprintf("failed to list OpenFGA stores: %v", err))
+		if cache != nil {
+			_ = cache.Close()
+		}
+		return nil, err
 	}
-	if len(storeResp.Stores) == 0 {
-		panic("no OpenFGA store found - mgmt-backend migration must run first to create the store")
+	c.ownsCache = true
+	return c, nil
+}
+
+// NewClientWithCache creates a new ACL client that caches results and
+// pins in the given Cache, which may be nil to disable both. The cache
+// is owned by the caller and is not closed by Close. Errors and retries
+// behave as in NewClientWithContext.
+func NewClientWithCache(ctx context.Context, wc openfga.OpenFGAServiceClient, rc openfga.OpenFGAServiceClient, cache Cache, cfg Config) (*ACLClient, error) {
+	if rc == nil {
+		rc = wc
 	}
-	if len(storeResp.Stores) > 1 {
-		panic(fmt.Sprintf("multiple OpenFGA stores found (%d) - this indicates a configuration problem; there should be exactly one store", len(storeResp.Stores)))
+	if err := cfg.Store.validate(); err != nil {
+		return nil, err
 	}
 
-	storeID := storeResp.Stores[0].Id
+	writeClient, readClient := instrumentFGA(wc), instrumentFGA(rc)
+	if cfg.Resilience.Enabled {
+		cfg.Resilience = cfg.Resilience.resolved()
+		resilientWrite := newResilientFGA(writeClient, cfg.Resilience)
+		if rc == wc {
+			// One server, one breaker.
+			readClient = resilientWrite
+		} else {
+			readClient = newResilientFGA(readClient, cfg.Resilience)
+		}
+		writeClient = resilientWrite
+	}
 
-	// Fetch and cache the authorization model ID at startup
-	// This avoids fetching the entire model schema on every permission check
-	modelResp, err := wc.ReadAuthorizationModels(context.Background(), &openfga.ReadAuthorizationModelsRequest{
-		StoreId: storeID,
-	})
-	if err != nil {
-		panic(fmt.Sprintf("failed to read OpenFGA authorization models: %v", err))
+	c := &ACLClient{
+		writeClient:            writeClient,
+		readClient:             readClient,
+		cache:                  cache,
+		cacheEnabled:           cfg.Cache.Enabled,
+		listPermissionsCacheOn: cfg.Cache.ListPermissionsEnabled,
+		listSubjectsCacheOn:    cfg.Cache.ListSubjectsEnabled,
+		listObjectsCfg:         cfg.ListObjects.resolved(),
+		config:                 cfg,
 	}
-	if len(modelResp.AuthorizationModels) == 0 {
-		panic(fmt.Sprintf("no authorization model found in OpenFGA store %s", storeID))
+	if staleness := cfg.Resilience.maxStaleness(); cfg.Resilience.Enabled && staleness > 0 {
+		c.stale = newLocalCache(cfg.Resilience.StaleSize, staleness)
 	}
-	modelID := modelResp.AuthorizationModels[0].Id
 
-	// Configure permission caching
-	cacheEnabled := cfg.Cache.Enabled
-	listPermissionsCacheOn := cfg.Cache.ListPermissionsEnabled
-	cacheTTL := cfg.Cache.CacheTTLDuration()
-	listObjectsCfg := cfg.ListObjects.resolved()
+	// Resolve the authorization model ID at startup so that permission
+	// checks don't fetch the entire model schema on every call
+	if err := retryDiscovery(ctx, func() error {
+		if c.storeID == "" {
+			storeID, err := c.discoverStore(ctx)
+			if err != nil {
+				return err
+			}
+			c.storeID = storeID
+		}
+		model, err := c.resolveModel(ctx)
+		if err != nil {
+			return err
+		}
+		modelID := model.GetId()
+		c.modelID.Store(&modelID)
+		c.setSchema(model)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	refresh := cfg.Model.refreshInterval()
+	if refresh > 0 {
+		c.startModelWatcher(refresh)
+	}
 
-	log, _ := logx.GetZapLogger(context.Background())
+	log, _ := logx.GetZapLogger(ctx)
 	log.Info("ACL client initialized",
-		zap.String("storeID", storeID),
-		zap.String("modelID", modelID),
-		zap.Bool("checkPermissionCacheEnabled", cacheEnabled),
-		zap.Bool("listPermissionsCacheEnabled", listPermissionsCacheOn),
-		zap.Duration("cacheTTL", cacheTTL),
-		zap.Duration("listObjectsDeadline", listObjectsCfg.Deadline),
-		zap.Int("listObjectsMaxResults", listObjectsCfg.MaxResults),
-		zap.Duration("listObjectsSlack", listObjectsCfg.Slack),
+		zap.String("storeID", c.storeID),
+		zap.String("storeName", cfg.Store.Name),
+		zap.String("modelID", c.GetModelID()),
+		zap.Bool("modelPinned", cfg.Model.ID != ""),
+		zap.Bool("argumentValidation", c.Schema() != nil),
+		zap.Duration("modelRefreshInterval", refresh),
+		zap.Bool("checkPermissionCacheEnabled", c.cacheEnabled),
+		zap.Bool("listPermissionsCacheEnabled", c.listPermissionsCacheOn),
+		zap.Bool("listSubjectsCacheEnabled", c.listSubjectsCacheOn),
+		zap.Duration("cacheTTL", cfg.Cache.CacheTTLDuration()),
+		zap.Duration("listObjectsDeadline", c.listObjectsCfg.Deadline),
+		zap.Int("listObjectsMaxResults", c.listObjectsCfg.MaxResults),
+		zap.Duration("listObjectsSlack", c.listObjectsCfg.Slack),
+		zap.Bool("cacheAvailable", cache != nil),
+		zap.String("cacheBackend", string(cfg.Cache.Backend)),
+		zap.Bool("localCacheEnabled", cfg.Cache.Local.Enabled),
+		zap.Bool("legacyCacheInvalidation", cfg.Cache.LegacyInvalidation),
+		zap.Bool("resilienceEnabled", cfg.Resilience.Enabled),
 	)
 
-	return &ACLClient{
-		writeClient:            wc,
-		readClient:             rc,
-		redisClient:            redisClient,
-		storeID:                storeID,
-		modelID:                modelID,
-		cacheEnabled:           cacheEnabled,
-		listPermissionsCacheOn: listPermissionsCacheOn,
-		cacheTTL:               cacheTTL,
-		listObjectsCfg:         listObjectsCfg,
-		config:                 cfg,
+	return c, nil
+}
+
+// Close releases the background resources held by the client: the
+// model watcher and the cache invalidation subscription of a cache
+// built by NewClient. The OpenFGA and Redis connections are owned by
+// the caller and are left open.
+func (c *ACLClient) Close() error {
+	c.stopModelWatcher()
+	if !c.ownsCache || c.cache == nil {
+		return nil
 	}
+	return c.cache.Close()
 }
 
 // InitOpenFGAClient initializes gRPC connections to OpenFGA server.
@@ -184,7 +307,7 @@ func InitOpenFGAClient(ctx context.Context, host string, port int, maxDataSize i
 func (c *ACLClient) getClient(ctx context.Context, mode Mode) openfga.OpenFGAServiceClient {
 	userUID := resource.GetRequestSingleHeader(ctx, constant.HeaderUserUIDKey)
 
-	if c.redisClient == nil {
+	if c.cache == nil {
 		return c.writeClient
 	}
 
@@ -192,13 +315,13 @@ func (c *ACLClient) getClient(ctx context.Context, mode Mode) openfga.OpenFGASer
 		// To solve the read-after-write inconsistency problem,
 		// we direct the user to read from the primary database for a certain time frame
 		if c.config.Replica.ReplicationTimeFrame > 0 {
-			_ = c.redisClient.Set(ctx, fmt.Sprintf("db_pin_user:%s:openfga", userUID), time.Now(), time.Duration(c.config.Replica.ReplicationTimeFrame)*time.Second)
+			_ = c.cache.Pin(ctx, userUID, c.replicationTimeFrame())
 		}
 		return c.writeClient
 	}
 
 	// Check if user is pinned to primary for read-after-write consistency
-	if !errors.Is(c.redisClient.Get(ctx, fmt.Sprintf("db_pin_user:%s:openfga", userUID)).Err(), redis.Nil) {
+	if c.cache.IsPinned(ctx, userUID) {
 		return c.writeClient // Primary
 	}
 
@@ -209,19 +332,30 @@ func (c *ACLClient) getClient(ctx context.Context, mode Mode) openfga.OpenFGASer
 	return c.writeClient
 }
 
+// replicationTimeFrame returns how long a subject stays pinned to the
+// primary after a write.
+func (c *ACLClient) replicationTimeFrame() time.Duration {
+	return time.Duration(c.config.Replica.ReplicationTimeFrame) * time.Second
+}
+
 // getAuthorizationModelID returns the cached authorization model ID.
-// The model ID is fetched once at startup and cached for the lifetime of the client.
+// The model ID is resolved at startup and kept up to date by the model
+// watcher unless Config.Model pins it.
 func (c *ACLClient) getAuthorizationModelID(ctx context.Context) (string, error) {
-	if c.modelID == "" {
+	modelID := c.GetModelID()
+	if modelID == "" {
 		return "", fmt.Errorf("authorization model ID not initialized")
 	}
-	return c.modelID, nil
+	return modelID, nil
 }
 
 // GetModelID returns the cached authorization model ID.
 // This is useful for external code that needs to make direct OpenFGA calls.
 func (c *ACLClient) GetModelID() string {
-	return c.modelID
+	if modelID := c.modelID.Load(); modelID != nil {
+		return *modelID
+	}
+	return ""
 }
 
 // permissionCacheKey generates a cache key for permission checks.
@@ -229,109 +363,37 @@ func permissionCacheKey(userType, userUID, objectType, objectUID, role string) s
 	return fmt.Sprintf("%s%s:%s:%s:%s:%s", PermissionCachePrefix, userType, userUID, objectType, objectUID, role)
 }
 
-// invalidateObjectCache invalidates all permission cache entries for a given object.
-func (c *ACLClient) invalidateObjectCache(ctx context.Context, objectType string, objectUID string) {
-	if !c.cacheEnabled || c.redisClient == nil {
-		return
-	}
-
-	log, _ := logx.GetZapLogger(ctx)
-
-	// Pattern to match all permission cache entries for this object
-	// Cache key format: acl:perm:userType:userUID:objectType:objectUID:role
-	// Pattern should match any user and any role for this specific object
-	pattern := fmt.Sprintf("%s*:%s:%s:*", PermissionCachePrefix, objectType, objectUID)
-
-	log.Debug("Invalidating permission cache",
-		zap.String("objectType", objectType),
-		zap.String("objectUID", objectUID),
-		zap.String("pattern", pattern),
-	)
-
-	var cursor uint64
-	var deletedCount int
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = c.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			log.Warn("Failed to scan cache keys for invalidation", zap.Error(err), zap.String("pattern", pattern))
-			return
-		}
-
-		if len(keys) > 0 {
-			log.Debug("Found cache keys to delete", zap.Strings("keys", keys))
-			if err := c.redisClient.Del(ctx, keys...).Err(); err != nil {
-				log.Warn("Failed to delete cache keys", zap.Error(err), zap.Strings("keys", keys))
-			} else {
-				deletedCount += len(keys)
-			}
-		}
-
-		if cursor == 0 {
-			break
-		}
+// checkCacheEntry returns the cache entry of a permission check. It
+// depends on the object's tuples and on the subject's memberships.
+func checkCacheEntry(userType, userUID, objectType, objectUID, role string) *CacheEntry {
+	return &CacheEntry{
+		Key:  permissionCacheKey(userType, userUID, objectType, objectUID, role),
+		Tags: []string{ObjectCacheTag(objectType, objectUID), SubjectCacheTag(userType + ":" + userUID)},
 	}
-
-	log.Debug("Permission cache invalidation completed",
-		zap.String("objectType", objectType),
-		zap.String("objectUID", objectUID),
-		zap.Int("deletedKeys", deletedCount),
-	)
 }
 
-// invalidateUserObjectCache invalidates cache entries for a specific user on a specific object.
-// This is used when setting permissions for a specific user to ensure their cached "denied" results
-// are immediately invalidated.
-func (c *ACLClient) invalidateUserObjectCache(ctx context.Context, user, objectType, objectUID string) {
-	if !c.cacheEnabled || c.redisClient == nil {
+// invalidateObjectCache invalidates all permission cache entries for a
+// given object, and the ListSubjects entries of the object.
+func (c *ACLClient) invalidateObjectCache(ctx context.Context, objectType string, objectUID string) {
+	if c.stale != nil {
+		c.stale.invalidate(ObjectCacheTag(objectType, objectUID))
+	}
+	if (!c.cacheEnabled && !c.listSubjectsCacheOn) || c.cache == nil {
 		return
 	}
 
 	log, _ := logx.GetZapLogger(ctx)
-
-	// Pattern to match all permission cache entries for this user on this object
-	// Cache key format: acl:perm:userType:userUID:objectType:objectUID:role
-	pattern := fmt.Sprintf("%s%s:%s:%s:*", PermissionCachePrefix, user, objectType, objectUID)
-
-	log.Debug("Invalidating user-specific permission cache",
-		zap.String("user", user),
+	log.Debug("Invalidating permission cache",
 		zap.String("objectType", objectType),
 		zap.String("objectUID", objectUID),
-		zap.String("pattern", pattern),
 	)
 
-	var cursor uint64
-	var deletedCount int
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = c.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			log.Warn("Failed to scan user cache keys for invalidation", zap.Error(err), zap.String("pattern", pattern))
-			return
-		}
-
-		if len(keys) > 0 {
-			log.Debug("Found user cache keys to delete", zap.Strings("keys", keys))
-			if err := c.redisClient.Del(ctx, keys...).Err(); err != nil {
-				log.Warn("Failed to delete user cache keys", zap.Error(err), zap.Strings("keys", keys))
-			} else {
-				deletedCount += len(keys)
-			}
-		}
-
-		if cursor == 0 {
-			break
-		}
+	if err := c.cache.InvalidateObject(ctx, objectType, objectUID); err != nil {
+		log.Warn("Failed to invalidate permission cache", zap.Error(err),
+			zap.String("objectType", objectType),
+			zap.String("objectUID", objectUID),
+		)
 	}
-
-	log.Debug("User permission cache invalidation completed",
-		zap.String("user", user),
-		zap.String("objectType", objectType),
-		zap.String("objectUID", objectUID),
-		zap.Int("deletedKeys", deletedCount),
-	)
 }
 
 // listPermissionsCacheKey generates a Redis key for caching ListPermissions results.
@@ -341,58 +403,30 @@ func listPermissionsCacheKey(userType, userUID, objectType, role string) string
 	return fmt.Sprintf("%s%s:%s:%s:%s", ListPermissionsCachePrefix, userType, userUID, objectType, role)
 }
 
-// invalidateListPermissionsCache deletes all ListPermissions cache entries that
-// match the given pattern. This is called from every FGA write path so that
-// cached object-UID lists are refreshed after permission changes.
-//
-// Pattern examples:
-//
-//	"acl:list:user:abc-123:*"       — all list entries for a specific user
-//	"acl:list:*:file:*"             — all list entries for object type "file"
-//	"acl:list:*"                    — everything (nuclear option, not used)
-func (c *ACLClient) invalidateListPermissionsCache(ctx context.Context, pattern string) {
-	if !c.listPermissionsCacheOn || c.redisClient == nil {
-		return
-	}
-
-	log, _ := logx.GetZapLogger(ctx)
-	log.Debug("Invalidating ListPermissions cache", zap.String("pattern", pattern))
-
-	var cursor uint64
-	var deletedCount int
-	for {
-		var keys []string
-		var err error
-		keys, cursor, err = c.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			log.Warn("Failed to scan list-permissions cache keys", zap.Error(err), zap.String("pattern", pattern))
-			return
-		}
-		if len(keys) > 0 {
-			if err := c.redisClient.Del(ctx, keys...).Err(); err != nil {
-				log.Warn("Failed to delete list-permissions cache keys", zap.Error(err), zap.Strings("keys", keys))
-			} else {
-				deletedCount += len(keys)
-			}
-		}
-		if cursor == 0 {
-			break
-		}
-	}
-
-	if deletedCount > 0 {
-		log.Debug("ListPermissions cache invalidation completed",
-			zap.String("pattern", pattern),
-			zap.Int("deletedKeys", deletedCount),
-		)
+// listCacheEntry returns the cache entry of a ListPermissions result. It
+// depends on the subject's grants and on public grants of the type.
+func listCacheEntry(userType, userUID, objectType, role string) *CacheEntry {
+	return &CacheEntry{
+		Key:  listPermissionsCacheKey(userType, userUID, objectType, role),
+		Tags: []string{SubjectCacheTag(userType + ":" + userUID), ObjectTypeCacheTag(objectType)},
 	}
 }
 
 // invalidateListPermissionsCacheForUser invalidates all ListPermissions cache
 // entries for the given FGA user string (e.g. "user:abc-123" or "user:*").
+// CheckPermission entries computed for the user are invalidated as well.
 func (c *ACLClient) invalidateListPermissionsCacheForUser(ctx context.Context, user string) {
-	pattern := fmt.Sprintf("%s%s:*", ListPermissionsCachePrefix, user)
-	c.invalidateListPermissionsCache(ctx, pattern)
+	if c.stale != nil {
+		c.stale.invalidate(SubjectCacheTag(user))
+	}
+	if (!c.listPermissionsCacheOn && !c.cacheEnabled) || c.cache == nil {
+		return
+	}
+
+	if err := c.cache.InvalidateUser(ctx, user); err != nil {
+		log, _ := logx.GetZapLogger(ctx)
+		log.Warn("Failed to invalidate user cache", zap.Error(err), zap.String("user", user))
+	}
 }
 
 // invalidateListPermissionsCacheForObjectType invalidates all ListPermissions
@@ -400,8 +434,14 @@ func (c *ACLClient) invalidateListPermissionsCacheForUser(ctx context.Context, u
 // public permission change (user:* / visitor:*) alters the FGA graph for every
 // caller.
 func (c *ACLClient) invalidateListPermissionsCacheForObjectType(ctx context.Context, objectType string) {
-	pattern := fmt.Sprintf("%s*:%s:*", ListPermissionsCachePrefix, objectType)
-	c.invalidateListPermissionsCache(ctx, pattern)
+	if !c.listPermissionsCacheOn || c.cache == nil {
+		return
+	}
+
+	if err := c.cache.InvalidateObjectType(ctx, objectType); err != nil {
+		log, _ := logx.GetZapLogger(ctx)
+		log.Warn("Failed to invalidate object type cache", zap.Error(err), zap.String("objectType", objectType))
+	}
 }
 
 // SetOwner sets the owner of a given object.
@@ -410,6 +450,12 @@ func (c *ACLClient) SetOwner(ctx context.Context, objectType string, objectUID u
 
 	// Normalize ownerType
 	ownerType = strings.TrimSuffix(ownerType, "s")
+	if err := c.validate(objectType, "owner"); err != nil {
+		return err
+	}
+	if err := c.validate(ownerType); err != nil {
+		return err
+	}
 
 	// Check if the owner already exists
 	data, err := c.getClient(ctx, ReadMode).Read(ctx, &openfga.ReadRequest{
@@ -441,19 +487,19 @@ func (c *ACLClient) SetOwner(ctx context.Context, objectType string, objectUID u
 	)
 
 	// Write the new owner
+	writes := []*openfga.TupleKey{
+		{
+			User:     fmt.Sprintf("%s:%s", ownerType, ownerUID.String()),
+			Relation: "owner",
+			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
+		},
+	}
 	_, err = c.getClient(ctx, WriteMode).Write(ctx, &openfga.WriteRequest{
 		StoreId:              c.storeID,
 		AuthorizationModelId: modelID,
-		Writes: &openfga.WriteRequestWrites{
-			TupleKeys: []*openfga.TupleKey{
-				{
-					User:     fmt.Sprintf("%s:%s", ownerType, ownerUID.String()),
-					Relation: "owner",
-					Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
-				},
-			},
-		},
+		Writes:               &openfga.WriteRequestWrites{TupleKeys: writes},
 	})
+	c.audit(ctx, AuditSetOwner, "owner", nil, writes, nil, err)
 	if err != nil {
 		log.Error("SetOwner: failed to write owner tuple", zap.Error(err))
 		return err
@@ -469,53 +515,42 @@ func (c *ACLClient) SetOwner(ctx context.Context, objectType string, objectUID u
 }
 
 // Purge deletes all permissions associated with the specified object.
+//
+// Every page of the Read result is walked (a large knowledge base easily
+// has more tuples than fit in a single page) and the deletes are sent
+// as chunked multi-tuple Write requests. When some chunks fail, the
+// remaining ones are still applied and a *TupleWriteError listing the
+// tuples left behind is returned.
 func (c *ACLClient) Purge(ctx context.Context, objectType string, objectUID uuid.UUID) error {
-	// Read all tuples related to the specified object
-	data, err := c.getClient(ctx, ReadMode).Read(ctx, &openfga.ReadRequest{
-		StoreId: c.storeID,
-		TupleKey: &openfga.ReadRequestTupleKey{
-			Object: fmt.Sprintf("%s:%s", objectType, objectUID),
-		},
-	})
-	if err != nil {
+	if err := c.validate(objectType); err != nil {
 		return err
 	}
 
-	// Invalidate permission cache for the object
-	c.invalidateObjectCache(ctx, objectType, objectUID.String())
-	c.invalidateListPermissionsCacheForObjectType(ctx, objectType)
-
-	if len(data.Tuples) == 0 {
-		return nil
-	}
-
-	// Get the latest authorization model ID
-	modelID, err := c.getAuthorizationModelID(ctx)
+	// Read all tuples related to the specified object
+	tuples, err := c.ReadTuples(ctx, ReadTupleFilter{
+		Object: fmt.Sprintf("%s:%s", objectType, objectUID),
+	})
 	if err != nil {
-		return fmt.Errorf("getting authorization model: %w", err)
+		return err
 	}
 
-	// Delete each tuple
-	for _, tuple := range data.Tuples {
-		_, err = c.getClient(ctx, WriteMode).Write(ctx, &openfga.WriteRequest{
-			StoreId:              c.storeID,
-			AuthorizationModelId: modelID,
-			Deletes: &openfga.WriteRequestDeletes{
-				TupleKeys: []*openfga.TupleKeyWithoutCondition{
-					{
-						User:     tuple.Key.User,
-						Relation: tuple.Key.Relation,
-						Object:   tuple.Key.Object,
-					},
-				},
-			},
+	deletes := make([]*openfga.TupleKeyWithoutCondition, 0, len(tuples))
+	for _, tuple := range tuples {
+		deletes = append(deletes, &openfga.TupleKeyWithoutCondition{
+			User:     tuple.User,
+			Relation: tuple.Relation,
+			Object:   tuple.Object,
 		})
-		if err != nil {
-			return err
-		}
 	}
+	err = c.writeTuples(ctx, nil, deletes)
+	c.audit(ctx, AuditPurge, "", tuples, nil, deletes, err)
 
-	return nil
+	// Invalidate permission cache for the object, even on a partial
+	// failure: some of the tuples are gone already.
+	c.invalidateObjectCache(ctx, objectType, objectUID.String())
+	c.invalidateListPermissionsCacheForObjectType(ctx, objectType)
+
+	return err
 }
 
 // resolveACLSubject derives the OpenFGA subject (type, UID) from the
@@ -549,7 +584,13 @@ func (c *ACLClient) Purge(ctx context.Context, objectType string, objectUID uuid
 //     store are keyed that way — emitting `capability:{uuid}` would
 //     never match a stored tuple and silently deny access.
 //
-//  2. Otherwise, if `Instill-Auth-Type` is a visitor-shaped label
+//  2. Otherwise, if `Instill-Auth-Type` is `serviceaccount` or
+//     `apitoken`, the caller is a machine subject identified by
+//     `Instill-Service-Account-Uid` or `Instill-Api-Token-Uid`. Its
+//     FGA subject type is the auth type, and its checks are further
+//     restricted by the scopes of the request (see RequestScopes).
+//
+//  3. Otherwise, if `Instill-Auth-Type` is a visitor-shaped label
 //     (`visitor` or `capability`) and `Instill-Visitor-Uid` is set,
 //     the caller is an anonymous visitor. Both labels collapse to
 //     FGA subject type `visitor` because:
@@ -568,7 +609,7 @@ func (c *ACLClient) Purge(ctx context.Context, objectType string, objectUID uuid
 //     `Instill-Capability-Token-Uid` and call CheckShareLinkPermission
 //     explicitly.
 //
-//  3. Otherwise, no usable identity was found. Returning an error
+//  4. Otherwise, no usable identity was found. Returning an error
 //     here preserves the long-standing "empty subject = unauthenticated"
 //     contract that callers rely on for 401 mapping.
 //
@@ -583,65 +624,99 @@ func resolveACLSubject(ctx context.Context) (userType, userUID string, err error
 	}
 
 	authType := resource.GetRequestSingleHeader(ctx, constant.HeaderAuthTypeKey)
+	if header, ok := machineSubjectHeaders[authType]; ok {
+		// Rule (2): service account or unbound API token, keyed by its
+		// own UID so that no user UID has to be forged.
+		uid := resource.GetRequestSingleHeader(ctx, header)
+		if uid == "" {
+			return "", "", fmt.Errorf("%w: %s UID is empty in check permission", errorsx.ErrUnauthenticated, authType)
+		}
+		return authType, uid, nil
+	}
+
 	switch authType {
 	case "visitor", "capability":
 		visitorUID := resource.GetRequestSingleHeader(ctx, constant.HeaderVisitorUIDKey)
 		if visitorUID == "" {
 			return "", "", fmt.Errorf("%w: userUID is empty in check permission", errorsx.ErrUnauthenticated)
 		}
-		// Rule (2): collapse both labels to `visitor`. The FGA schema
+		// Rule (3): collapse both labels to `visitor`. The FGA schema
 		// only defines `user` and `visitor` identity types; capability
 		// tokens are handled separately via CheckShareLinkPermission.
 		return "visitor", visitorUID, nil
 	}
 
-	// Rule (3): no recognizable identity header. The upstream "user"
+	// Rule (4): no recognizable identity header. The upstream "user"
 	// branch lost its user-UID header before reaching us (bug in the
 	// gateway or a mis-forwarded gRPC-to-gRPC hop) — fail closed.
 	return "", "", fmt.Errorf("%w: userUID is empty in check permission", errorsx.ErrUnauthenticated)
 }
 
+// checkConsistency determines whether a Check-style read must bypass
+// the caches and use HIGHER_CONSISTENCY. Two triggers: (1) caller set
+// ContextKeyForceHigherConsistency (object-level, e.g. after a
+// visibility toggle that affects all users including anonymous), or
+// (2) the subject is pinned via Redis (per-user read-after-write).
+//
+// The zero ConsistencyPreference (UNSPECIFIED) is returned otherwise so
+// the OpenFGA server default applies.
+func (c *ACLClient) checkConsistency(ctx context.Context, userUID string) (openfga.ConsistencyPreference, bool) {
+	if forceHC, ok := ctx.Value(ContextKeyForceHigherConsistency).(bool); ok && forceHC {
+		return openfga.ConsistencyPreference_HIGHER_CONSISTENCY, true
+	}
+	if c.cache != nil && c.cache.IsPinned(ctx, userUID) {
+		metrics.recordPinnedRead(ctx, "check")
+		return openfga.ConsistencyPreference_HIGHER_CONSISTENCY, true
+	}
+	return openfga.ConsistencyPreference_UNSPECIFIED, false
+}
+
 // CheckPermission verifies if the current user has a specific role for an object.
 func (c *ACLClient) CheckPermission(ctx context.Context, objectType string, objectUID uuid.UUID, role string) (bool, error) {
+	startedAt := time.Now()
+	allowed, err := c.checkPermission(ctx, objectType, objectUID, role)
+	metrics.recordCheck(ctx, startedAt, objectType, role, allowed, err)
+	return allowed, err
+}
+
+func (c *ACLClient) checkPermission(ctx context.Context, objectType string, objectUID uuid.UUID, role string) (bool, error) {
 	log, _ := logx.GetZapLogger(ctx)
 
+	if err := c.validate(objectType, role); err != nil {
+		return false, err
+	}
+
 	userType, userUID, err := resolveACLSubject(ctx)
 	if err != nil {
 		return false, err
 	}
-
-	// Determine whether to bypass caches and use HIGHER_CONSISTENCY.
-	// Two triggers: (1) caller set ContextKeyForceHigherConsistency (object-level,
-	// e.g. after a visibility toggle that affects all users including anonymous),
-	// or (2) user is pinned via Redis (per-user read-after-write).
-	var consistency openfga.ConsistencyPreference
-	forceConsistency := false
-	if forceHC, ok := ctx.Value(ContextKeyForceHigherConsistency).(bool); ok && forceHC {
-		forceConsistency = true
-		consistency = openfga.ConsistencyPreference_HIGHER_CONSISTENCY
-	}
-	if !forceConsistency && c.redisClient != nil {
-		pinKey := fmt.Sprintf("db_pin_user:%s:openfga", userUID)
-		if !errors.Is(c.redisClient.Get(ctx, pinKey).Err(), redis.Nil) {
-			forceConsistency = true
-			consistency = openfga.ConsistencyPreference_HIGHER_CONSISTENCY
-		}
+	if outOfScope(ctx, userType, objectType, role) {
+		log.Debug("CheckPermission out of scope",
+			zap.String("userType", userType),
+			zap.String("userUID", userUID),
+			zap.String("objectType", objectType),
+			zap.String("role", role),
+		)
+		return false, nil
 	}
 
-	cacheKey := permissionCacheKey(userType, userUID, objectType, objectUID.String(), role)
-	if c.cacheEnabled && c.redisClient != nil && !forceConsistency {
-		cachedResult, err := c.redisClient.Get(ctx, cacheKey).Result()
-		if err == nil {
+	consistency, forceConsistency := c.checkConsistency(ctx, userUID)
+
+	useCache := c.cacheEnabled && c.cache != nil && !forceConsistency && !hasCheckContext(ctx)
+	cacheEntries := []*CacheEntry{checkCacheEntry(userType, userUID, objectType, objectUID.String(), role)}
+	if useCache {
+		cached, err := c.cache.Get(ctx, cacheEntries)
+		if err != nil {
+			// Log cache error but continue to OpenFGA
+			log.Warn("CheckPermission cache error", zap.Error(err))
+		} else if cached[0] != "" {
 			// Cache hit
-			allowed := cachedResult == "1"
+			allowed := cached[0] == "1"
 			log.Debug("CheckPermission cache hit",
-				zap.String("cacheKey", cacheKey),
+				zap.String("cacheKey", cacheEntries[0].Key),
 				zap.Bool("allowed", allowed),
 			)
 			return allowed, nil
-		} else if !errors.Is(err, redis.Nil) {
-			// Log cache error but continue to OpenFGA
-			log.Warn("CheckPermission cache error", zap.Error(err))
 		}
 	}
 
@@ -663,6 +738,11 @@ func (c *ACLClient) CheckPermission(ctx context.Context, objectType string, obje
 		zap.String("consistency", consistency.String()),
 	)
 
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	// Create a CheckRequest to verify the user's permission
 	checkReq := &openfga.CheckRequest{
 		StoreId:              c.storeID,
@@ -672,20 +752,32 @@ func (c *ACLClient) CheckPermission(ctx context.Context, objectType string, obje
 			Relation: role,
 			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
 		},
+		Context:     reqContext,
 		Consistency: consistency,
 	}
+	staleEpoch := c.staleEpoch()
 	data, err := c.getClient(ctx, ReadMode).Check(ctx, checkReq)
 	if err != nil {
+		// A stale decision can't vouch for a pinned user's recent write
+		// nor for a check depending on the request context.
+		if !forceConsistency && !hasCheckContext(ctx) {
+			if allowed, ok := c.staleDecision(ctx, objectType, cacheEntries[0], err); ok {
+				return allowed, nil
+			}
+		}
 		log.Error("CheckPermission failed", zap.Error(err))
 		return false, err
 	}
+	if !hasCheckContext(ctx) {
+		c.rememberDecision(cacheEntries[0], data.Allowed, staleEpoch)
+	}
 
-	if c.cacheEnabled && c.redisClient != nil && !forceConsistency {
+	if useCache {
 		cacheValue := "0"
 		if data.Allowed {
 			cacheValue = "1"
 		}
-		if err := c.redisClient.Set(ctx, cacheKey, cacheValue, c.cacheTTL).Err(); err != nil {
+		if err := c.cache.Set(ctx, cacheEntries, []string{cacheValue}); err != nil {
 			log.Warn("CheckPermission failed to cache result", zap.Error(err))
 		}
 	}
@@ -699,14 +791,19 @@ func (c *ACLClient) CheckPermission(ctx context.Context, objectType string, obje
 func (c *ACLClient) CheckPublicExecutable(ctx context.Context, objectType string, objectUID uuid.UUID) (bool, error) {
 	log, _ := logx.GetZapLogger(ctx)
 
+	if err := c.validate(objectType, "executor"); err != nil {
+		return false, err
+	}
+
 	// Check cache first if enabled
-	cacheKey := permissionCacheKey("user", "*", objectType, objectUID.String(), "executor")
-	if c.cacheEnabled && c.redisClient != nil {
-		cachedResult, err := c.redisClient.Get(ctx, cacheKey).Result()
-		if err == nil {
-			return cachedResult == "1", nil
-		} else if !errors.Is(err, redis.Nil) {
+	useCache := c.cacheEnabled && c.cache != nil && !hasCheckContext(ctx)
+	cacheEntries := []*CacheEntry{checkCacheEntry("user", "*", objectType, objectUID.String(), "executor")}
+	if useCache {
+		cached, err := c.cache.Get(ctx, cacheEntries)
+		if err != nil {
 			log.Warn("CheckPublicExecutable cache error", zap.Error(err))
+		} else if cached[0] != "" {
+			return cached[0] == "1", nil
 		}
 	}
 
@@ -716,6 +813,11 @@ func (c *ACLClient) CheckPublicExecutable(ctx context.Context, objectType string
 		return false, fmt.Errorf("getting authorization model: %w", err)
 	}
 
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	data, err := c.getClient(ctx, ReadMode).Check(ctx, &openfga.CheckRequest{
 		StoreId:              c.storeID,
 		AuthorizationModelId: modelID,
@@ -724,18 +826,19 @@ func (c *ACLClient) CheckPublicExecutable(ctx context.Context, objectType string
 			Relation: "executor",
 			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
 		},
+		Context: reqContext,
 	})
 	if err != nil {
 		return false, err
 	}
 
 	// Cache the result if caching is enabled
-	if c.cacheEnabled && c.redisClient != nil {
+	if useCache {
 		cacheValue := "0"
 		if data.Allowed {
 			cacheValue = "1"
 		}
-		if err := c.redisClient.Set(ctx, cacheKey, cacheValue, c.cacheTTL).Err(); err != nil {
+		if err := c.cache.Set(ctx, cacheEntries, []string{cacheValue}); err != nil {
 			log.Warn("CheckPublicExecutable failed to cache result", zap.Error(err))
 		}
 	}
@@ -747,15 +850,14 @@ func (c *ACLClient) CheckPublicExecutable(ctx context.Context, objectType string
 // This is used to determine whether to use HIGHER_CONSISTENCY mode in OpenFGA queries
 // and whether to bypass in-memory caches.
 func (c *ACLClient) IsUserPinned(ctx context.Context) bool {
-	if c.redisClient == nil {
+	if c.cache == nil {
 		return false
 	}
 	userUID := resource.GetRequestSingleHeader(ctx, constant.HeaderUserUIDKey)
 	if userUID == "" {
 		return false
 	}
-	pinKey := fmt.Sprintf("db_pin_user:%s:openfga", userUID)
-	return !errors.Is(c.redisClient.Get(ctx, pinKey).Err(), redis.Nil)
+	return c.cache.IsPinned(ctx, userUID)
 }
 
 // ListPermissions lists all objects of a type that the current user
@@ -774,12 +876,22 @@ func (c *ACLClient) IsUserPinned(ctx context.Context) bool {
 // conflating "list as me" with "list as anyone" has historically
 // produced subtle bugs (e.g. silently returning the public set when
 // the caller's identity failed to resolve).
+//
+// When the stream looks truncated and ListObjectsConfig selects
+// TruncationStrategyReadTuples, the result may come with an error
+// wrapping ErrPossiblyTruncated; the returned objects are still usable.
 func (c *ACLClient) ListPermissions(ctx context.Context, objectType string, role string) ([]uuid.UUID, error) {
 	userType, userUIDStr, err := resolveACLSubject(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.listObjectsForSubject(ctx, objectType, role, userType, userUIDStr)
+	if outOfScope(ctx, userType, objectType, role) {
+		return []uuid.UUID{}, nil
+	}
+	startedAt := time.Now()
+	objectUIDs, err := c.listObjectsForSubject(ctx, objectType, role, userType, userUIDStr)
+	metrics.recordList(ctx, startedAt, objectType, role, err)
+	return objectUIDs, err
 }
 
 // ListPublicPermissions lists all objects of a type that are readable
@@ -790,7 +902,10 @@ func (c *ACLClient) ListPermissions(ctx context.Context, objectType string, role
 // request, and is never subject to the per-user read-after-write
 // pinning that ListPermissions honours.
 func (c *ACLClient) ListPublicPermissions(ctx context.Context, objectType string, role string) ([]uuid.UUID, error) {
-	return c.listObjectsForSubject(ctx, objectType, role, "user", "*")
+	startedAt := time.Now()
+	objectUIDs, err := c.listObjectsForSubject(ctx, objectType, role, "user", "*")
+	metrics.recordList(ctx, startedAt, objectType, role, err)
+	return objectUIDs, err
 }
 
 // listObjectsForSubject issues a StreamedListObjects call for the
@@ -806,64 +921,34 @@ func (c *ACLClient) ListPublicPermissions(ctx context.Context, objectType string
 func (c *ACLClient) listObjectsForSubject(ctx context.Context, objectType, role, userType, userUIDStr string) ([]uuid.UUID, error) {
 	log, _ := logx.GetZapLogger(ctx)
 
+	if err := c.validate(objectType, role); err != nil {
+		return nil, err
+	}
+
 	modelID, err := c.getAuthorizationModelID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("getting authorization model: %w", err)
 	}
 
-	// Use HIGHER_CONSISTENCY when the caller forced it via context (object-level
-	// visibility change) or when the user is pinned (per-user read-after-write).
-	forceConsistency := false
-	consistency := openfga.ConsistencyPreference_MINIMIZE_LATENCY
-	if forceHC, ok := ctx.Value(ContextKeyForceHigherConsistency).(bool); ok && forceHC {
-		forceConsistency = true
-		consistency = openfga.ConsistencyPreference_HIGHER_CONSISTENCY
-	} else if c.IsUserPinned(ctx) {
-		forceConsistency = true
-		consistency = openfga.ConsistencyPreference_HIGHER_CONSISTENCY
-	}
+	consistency, forceConsistency := c.listConsistency(ctx)
 
 	// --- Cache read ---
-	cacheKey := listPermissionsCacheKey(userType, userUIDStr, objectType, role)
-	if c.listPermissionsCacheOn && c.redisClient != nil && !forceConsistency {
-		cached, redisErr := c.redisClient.Get(ctx, cacheKey).Result()
-		if redisErr == nil {
-			var uidStrs []string
-			if jsonErr := json.Unmarshal([]byte(cached), &uidStrs); jsonErr == nil {
-				uids := make([]uuid.UUID, 0, len(uidStrs))
-				for _, s := range uidStrs {
-					uids = append(uids, uuid.FromStringOrNil(s))
-				}
-				log.Debug("ListPermissions cache hit",
-					zap.String("cacheKey", cacheKey),
-					zap.Int("count", len(uids)),
-				)
-				return uids, nil
-			} else {
-				log.Warn("ListPermissions cache unmarshal error, falling through to FGA", zap.Error(jsonErr))
-			}
-		} else if !errors.Is(redisErr, redis.Nil) {
-			log.Warn("ListPermissions cache read error", zap.Error(redisErr))
+	useCache := c.listPermissionsCacheOn && c.cache != nil && !forceConsistency && !hasCheckContext(ctx)
+	cacheEntries := []*CacheEntry{listCacheEntry(userType, userUIDStr, objectType, role)}
+	if useCache {
+		if uids, ok := c.readListCache(ctx, cacheEntries); ok {
+			return uids, nil
 		}
 	}
 
 	// --- FGA call ---
 	startedAt := time.Now()
-	stream, err := c.getClient(ctx, ReadMode).StreamedListObjects(ctx, &openfga.StreamedListObjectsRequest{
-		StoreId:              c.storeID,
-		AuthorizationModelId: modelID,
-		User:                 fmt.Sprintf("%s:%s", userType, userUIDStr),
-		Relation:             role,
-		Type:                 objectType,
-		Consistency:          consistency,
-	})
+	stream, err := c.openListStream(ctx, modelID, objectType, role, userType, userUIDStr, consistency)
 	if err != nil {
-		if statusErr, ok := status.FromError(err); ok {
-			if statusErr.Code() == codes.Code(openfga.ErrorCode_type_not_found) {
-				return []uuid.UUID{}, nil
-			}
-		}
-		return nil, fmt.Errorf("starting streamed list objects: %w", err)
+		return nil, err
+	}
+	if stream == nil {
+		return []uuid.UUID{}, nil
 	}
 
 	objectUIDs := []uuid.UUID{}
@@ -875,7 +960,7 @@ func (c *ACLClient) listObjectsForSubject(ctx context.Context, objectType, role,
 		if err != nil {
 			return nil, fmt.Errorf("receiving from stream: %w", err)
 		}
-		objectUIDs = append(objectUIDs, uuid.FromStringOrNil(strings.Split(resp.GetObject(), ":")[1]))
+		objectUIDs = append(objectUIDs, objectUIDFromFGA(resp.GetObject()))
 	}
 	elapsed := time.Since(startedAt)
 
@@ -903,28 +988,32 @@ func (c *ACLClient) listObjectsForSubject(ctx context.Context, objectType, role,
 	// result is still returned to the caller — the alternative would
 	// break read paths that have legitimately small result sets but
 	// ran slowly under FGA load.
-	truncated := isLikelyTruncated(elapsed, len(objectUIDs), c.listObjectsCfg)
+	//
+	// With TruncationStrategyReadTuples the partial result is first
+	// unioned with the direct grants read through the indexed Read API;
+	// if that fallback cannot vouch for the union, it is returned along
+	// with ErrPossiblyTruncated so the caller decides how to degrade.
+	truncated := c.detectTruncation(ctx, objectType, role, userType, userUIDStr, elapsed, len(objectUIDs))
 	if truncated {
-		log.Warn("acl.list_objects_truncated: StreamedListObjects result is likely truncated; refusing to cache",
-			zap.String("objectType", objectType),
-			zap.String("role", role),
-			zap.String("subject", fmt.Sprintf("%s:%s", userType, userUIDStr)),
-			zap.Duration("elapsed", elapsed),
-			zap.Duration("deadline", c.listObjectsCfg.Deadline),
-			zap.Int("returned", len(objectUIDs)),
-			zap.Int("maxResults", c.listObjectsCfg.MaxResults),
-		)
+		if c.listObjectsCfg.TruncationStrategy == TruncationStrategyReadTuples {
+			subject := fmt.Sprintf("%s:%s", userType, userUIDStr)
+			recovered, err := c.recoverTruncatedList(ctx, objectType, role, subject, objectUIDs)
+			if err != nil {
+				return recovered, fmt.Errorf("listing %s %s objects: %w", objectType, role, err)
+			}
+			objectUIDs = recovered
+		}
 	}
 
 	// --- Cache write ---
-	if c.listPermissionsCacheOn && c.redisClient != nil && !forceConsistency && !truncated {
+	if useCache && !truncated {
 		uidStrs := make([]string, len(objectUIDs))
 		for i, u := range objectUIDs {
 			uidStrs[i] = u.String()
 		}
 		data, jsonErr := json.Marshal(uidStrs)
 		if jsonErr == nil {
-			if err := c.redisClient.Set(ctx, cacheKey, data, c.cacheTTL).Err(); err != nil {
+			if err := c.cache.Set(ctx, cacheEntries, []string{string(data)}); err != nil {
 				log.Warn("ListPermissions failed to cache result", zap.Error(err))
 			}
 		}
@@ -949,56 +1038,105 @@ func (c *ACLClient) listObjectsForSubject(ctx context.Context, objectType, role,
 // such as `viewer ... or X from permission_parent`) must keep using
 // ListPermissions; Read only sees the raw tuples.
 func (c *ACLClient) ReadTuples(ctx context.Context, filter ReadTupleFilter) ([]ReadTuple, error) {
-	pageSize := filter.PageSize
-	if pageSize <= 0 {
-		pageSize = DefaultReadPageSize
+	var out []ReadTuple
+	for k, err := range c.ScanTuples(ctx, filter) {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, readTupleFromKey(k))
 	}
+	return out, nil
+}
 
-	tupleKey := &openfga.ReadRequestTupleKey{
-		Object:   filter.Object,
-		Relation: filter.Relation,
-		User:     filter.User,
+func readTupleFromKey(k *openfga.TupleKey) ReadTuple {
+	return ReadTuple{
+		Object:    k.GetObject(),
+		Relation:  k.GetRelation(),
+		User:      k.GetUser(),
+		Condition: k.GetCondition().GetName(),
+		ExpiresAt: conditionExpiry(k.GetCondition()),
 	}
+}
 
-	var (
-		out               []ReadTuple
-		continuationToken string
-	)
-	for {
-		req := &openfga.ReadRequest{
-			StoreId:           c.storeID,
-			TupleKey:          tupleKey,
-			PageSize:          wrapperspb.Int32(pageSize),
-			ContinuationToken: continuationToken,
+// ScanTuples streams the tuples matching filter like ReadTuples, one
+// Read page at a time, as OpenFGA tuple keys that keep the full
+// condition. A zero filter scans the whole store. Iteration stops at
+// the first error, which is yielded with a nil key.
+func (c *ACLClient) ScanTuples(ctx context.Context, filter ReadTupleFilter) iter.Seq2[*openfga.TupleKey, error] {
+	return c.scanTuples(ctx, filter, ReadMode)
+}
+
+// scanTuples implements ScanTuples, reading from the client of mode.
+// WriteMode reads from the primary, for checks that can't afford
+// replication lag.
+func (c *ACLClient) scanTuples(ctx context.Context, filter ReadTupleFilter, mode Mode) iter.Seq2[*openfga.TupleKey, error] {
+	return func(yield func(*openfga.TupleKey, error) bool) {
+		pageSize := filter.PageSize
+		if pageSize <= 0 {
+			pageSize = DefaultReadPageSize
 		}
-		resp, err := c.getClient(ctx, ReadMode).Read(ctx, req)
-		if err != nil {
-			if statusErr, ok := status.FromError(err); ok {
-				if statusErr.Code() == codes.Code(openfga.ErrorCode_type_not_found) {
-					return out, nil
-				}
+
+		var tupleKey *openfga.ReadRequestTupleKey
+		if filter.Object != "" || filter.Relation != "" || filter.User != "" {
+			tupleKey = &openfga.ReadRequestTupleKey{
+				Object:   filter.Object,
+				Relation: filter.Relation,
+				User:     filter.User,
 			}
-			return nil, fmt.Errorf("reading tuples: %w", err)
 		}
 
-		for _, t := range resp.GetTuples() {
-			k := t.GetKey()
-			if k == nil {
-				continue
+		continuationToken := ""
+		for {
+			req := &openfga.ReadRequest{
+				StoreId:           c.storeID,
+				TupleKey:          tupleKey,
+				PageSize:          wrapperspb.Int32(pageSize),
+				ContinuationToken: continuationToken,
+			}
+			resp, err := c.getClient(ctx, mode).Read(ctx, req)
+			if err != nil {
+				if statusErr, ok := status.FromError(err); ok {
+					if statusErr.Code() == codes.Code(openfga.ErrorCode_type_not_found) {
+						return
+					}
+				}
+				yield(nil, fmt.Errorf("reading tuples: %w", err))
+				return
 			}
-			out = append(out, ReadTuple{
-				Object:   k.GetObject(),
-				Relation: k.GetRelation(),
-				User:     k.GetUser(),
-			})
-		}
 
-		continuationToken = resp.GetContinuationToken()
-		if continuationToken == "" {
-			break
+			for _, t := range resp.GetTuples() {
+				if k := t.GetKey(); k != nil && !yield(k, nil) {
+					return
+				}
+			}
+
+			continuationToken = resp.GetContinuationToken()
+			if continuationToken == "" {
+				return
+			}
 		}
 	}
-	return out, nil
+}
+
+// detectTruncation reports whether a StreamedListObjects result of
+// returned objects, received in elapsed, is likely truncated, and
+// records the metric and the warn log of listObjectsForSubject if so.
+func (c *ACLClient) detectTruncation(ctx context.Context, objectType, role, userType, userUIDStr string, elapsed time.Duration, returned int) bool {
+	if !isLikelyTruncated(elapsed, returned, c.listObjectsCfg) {
+		return false
+	}
+	log, _ := logx.GetZapLogger(ctx)
+	metrics.recordTruncation(ctx, objectType, role)
+	log.Warn("acl.list_objects_truncated: StreamedListObjects result is likely truncated; refusing to cache",
+		zap.String("objectType", objectType),
+		zap.String("role", role),
+		zap.String("subject", fmt.Sprintf("%s:%s", userType, userUIDStr)),
+		zap.Duration("elapsed", elapsed),
+		zap.Duration("deadline", c.listObjectsCfg.Deadline),
+		zap.Int("returned", returned),
+		zap.Int("maxResults", c.listObjectsCfg.MaxResults),
+	)
+	return true
 }
 
 // isLikelyTruncated implements the heuristic documented inline at the
@@ -1025,7 +1163,7 @@ func (c *ACLClient) GetStoreID() string {
 // check query cache, preventing stale "permission denied" results after permission changes.
 // Should be called after any write operation that affects permissions.
 func (c *ACLClient) PinUserForConsistency(ctx context.Context) {
-	if c.redisClient == nil || c.config.Replica.ReplicationTimeFrame <= 0 {
+	if c.cache == nil || c.config.Replica.ReplicationTimeFrame <= 0 {
 		return
 	}
 
@@ -1034,47 +1172,108 @@ func (c *ACLClient) PinUserForConsistency(ctx context.Context) {
 		return
 	}
 
-	pinKey := fmt.Sprintf("db_pin_user:%s:openfga", userUID)
-	_ = c.redisClient.Set(ctx, pinKey, time.Now(), time.Duration(c.config.Replica.ReplicationTimeFrame)*time.Second)
+	_ = c.cache.Pin(ctx, userUID, c.replicationTimeFrame())
 }
 
+// standardRoles are the roles SetResourcePermission and
+// DeleteResourcePermission manage for a subject. A subject holds at
+// most one of them on a given object.
+var standardRoles = []string{"admin", "writer", "executor", "reader"}
+
 // SetResourcePermission sets a permission for a user on any resource type.
 // This is a generic method that can be used for any object type (pipeline, model, knowledgebase, etc.).
-// It first deletes any existing permission for the user, then sets the new permission if enable is true.
+// It replaces any existing standard role of the user with the new permission if enable is true,
+// or just removes it otherwise. The change is applied as a single Write request, except when
+// a conditional grant of the same role (see GrantUntil) is made unconditional: the grant is
+// then deleted first, and written back if the rest of the change fails. Should that fail as
+// well, the error says the grant was removed and the user is left without the role.
 func (c *ACLClient) SetResourcePermission(ctx context.Context, objectType string, objectUID uuid.UUID, user, role string, enable bool) error {
-	// Delete existing permission first
-	_ = c.DeleteResourcePermission(ctx, objectType, objectUID, user)
+	return c.setResourcePermission(ctx, AuditSetResourcePermission, objectType, objectUID, user, role, enable, nil)
+}
 
-	if enable {
-		modelID, err := c.getAuthorizationModelID(ctx)
-		if err != nil {
-			return fmt.Errorf("getting authorization model: %w", err)
-		}
+func (c *ACLClient) setResourcePermission(ctx context.Context, op AuditOperation, objectType string, objectUID uuid.UUID, user, role string, enable bool, cond *Condition) error {
+	if err := c.validate(objectType, role); err != nil {
+		return err
+	}
+	object := fmt.Sprintf("%s:%s", objectType, objectUID.String())
 
-		_, err = c.getClient(ctx, WriteMode).Write(ctx, &openfga.WriteRequest{
-			StoreId:              c.storeID,
-			AuthorizationModelId: modelID,
-			Writes: &openfga.WriteRequestWrites{
-				TupleKeys: []*openfga.TupleKey{
-					{
-						User:     user,
-						Relation: role,
-						Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
-					},
-				},
-			},
-		})
+	condition, err := cond.toFGA()
+	if err != nil {
+		return err
+	}
+
+	var existing []ReadTuple
+	var existingKeys []*openfga.TupleKey
+	for k, err := range c.ScanTuples(ctx, ReadTupleFilter{Object: object, User: user}) {
 		if err != nil {
 			return err
 		}
+		existing = append(existing, readTupleFromKey(k))
+		existingKeys = append(existingKeys, k)
+	}
+
+	// OpenFGA rejects a request that writes a tuple that already exists
+	// or that both deletes and writes the same tuple, so an unconditional
+	// role the user already holds is kept as-is. A conditional grant of
+	// the same role may carry a different condition context (e.g. another
+	// expiry), so it is deleted in a request of its own and written again.
+	var deletes, replaced []*openfga.TupleKeyWithoutCondition
+	var replacedKeys []*openfga.TupleKey
+	alreadyGranted := false
+	for i, t := range existing {
+		if enable && t.Relation == role {
+			if cond == nil && t.Condition == "" {
+				alreadyGranted = true
+			} else {
+				replaced = append(replaced, &openfga.TupleKeyWithoutCondition{User: t.User, Relation: t.Relation, Object: t.Object})
+				replacedKeys = append(replacedKeys, existingKeys[i])
+			}
+			continue
+		}
+		if slices.Contains(standardRoles, t.Relation) {
+			deletes = append(deletes, &openfga.TupleKeyWithoutCondition{User: t.User, Relation: t.Relation, Object: t.Object})
+		}
 	}
 
-	// Invalidate permission cache for the object (all users)
-	c.invalidateObjectCache(ctx, objectType, objectUID.String())
+	var writes []*openfga.TupleKey
+	if enable && !alreadyGranted {
+		writes = append(writes, &openfga.TupleKey{User: user, Relation: role, Object: object, Condition: condition})
+	}
+
+	replacedWritten := false
+	if len(replaced) > 0 {
+		err = c.writeTuples(ctx, nil, replaced)
+		replacedWritten = err == nil
+	}
+	if err == nil {
+		err = c.writeTuples(ctx, writes, deletes)
+	}
+	c.audit(ctx, op, role, existing, writes, slices.Concat(replaced, deletes), err)
+	if err != nil {
+		// The conditional grant is already gone when only the second
+		// write failed: it is written back, with its condition, and the
+		// answers cached in between are stale all the same.
+		if replacedWritten {
+			if restoreErr := c.writeTuples(ctx, replacedKeys, nil); restoreErr != nil {
+				err = fmt.Errorf("%w; the previous %s grant of %s was removed and could not be restored: %v", err, role, user, restoreErr)
+			}
+			c.invalidateGrant(ctx, objectType, objectUID, user)
+		}
+		return err
+	}
 
-	// Also invalidate user-specific cache to ensure any previously cached "denied"
-	// results for this specific user are immediately cleared
-	c.invalidateUserObjectCache(ctx, user, objectType, objectUID.String())
+	c.invalidateGrant(ctx, objectType, objectUID, user)
+
+	return nil
+}
+
+// invalidateGrant clears the caches affected by a change of the grants
+// user holds on the object and pins user for read-after-write
+// consistency.
+func (c *ACLClient) invalidateGrant(ctx context.Context, objectType string, objectUID uuid.UUID, user string) {
+	// Invalidate permission cache for the object (all users, including any
+	// previously cached "denied" results for this specific user)
+	c.invalidateObjectCache(ctx, objectType, objectUID.String())
 
 	// Invalidate ListPermissions cache for the affected user.
 	// For wildcard subjects (user:* / visitor:*), invalidate by object type
@@ -1088,49 +1287,57 @@ func (c *ACLClient) SetResourcePermission(ctx context.Context, objectType string
 	// Pin the subject user to primary for read-after-write consistency.
 	// When user A grants permission to user B, we need to pin user B to primary
 	// so that user B's subsequent reads see the newly granted permission.
-	// The user format is "user:<UUID>" or "group:<UUID>#member"
-	if c.redisClient != nil && c.config.Replica.ReplicationTimeFrame > 0 {
-		// Extract the UUID from the user string (format: "user:<UUID>" or "group:<UUID>#member")
-		parts := strings.SplitN(user, ":", 2)
-		if len(parts) == 2 {
-			subjectUID := strings.TrimSuffix(parts[1], "#member")
-			_ = c.redisClient.Set(ctx, fmt.Sprintf("db_pin_user:%s:openfga", subjectUID), time.Now(), time.Duration(c.config.Replica.ReplicationTimeFrame)*time.Second)
-		}
+	c.pinSubject(ctx, user)
+}
+
+// pinSubject pins the subject of a tuple to the primary database for
+// read-after-write consistency. The user format is "user:<UUID>" or
+// "group:<UUID>#member"; the UUID is used as the pin key.
+func (c *ACLClient) pinSubject(ctx context.Context, user string) {
+	if c.cache == nil || c.config.Replica.ReplicationTimeFrame <= 0 {
+		return
 	}
 
-	return nil
+	parts := strings.SplitN(user, ":", 2)
+	if len(parts) != 2 {
+		return
+	}
+	_ = c.cache.Pin(ctx, strings.TrimSuffix(parts[1], "#member"), c.replicationTimeFrame())
 }
 
 // DeleteResourcePermission deletes all permissions for a user on any resource type.
-// It removes all standard roles (admin, writer, executor, reader) for the specified user.
+// It removes every standard role (admin, writer, executor, reader) the user
+// currently holds, in a single Write request.
 func (c *ACLClient) DeleteResourcePermission(ctx context.Context, objectType string, objectUID uuid.UUID, user string) error {
-	modelID, err := c.getAuthorizationModelID(ctx)
+	return c.deleteResourcePermission(ctx, AuditDeleteResourcePermission, objectType, objectUID, user)
+}
+
+func (c *ACLClient) deleteResourcePermission(ctx context.Context, op AuditOperation, objectType string, objectUID uuid.UUID, user string) error {
+	if err := c.validate(objectType); err != nil {
+		return err
+	}
+	object := fmt.Sprintf("%s:%s", objectType, objectUID.String())
+
+	// Deleting a tuple that doesn't exist fails the whole Write request,
+	// so only the roles the user actually holds are deleted.
+	existing, err := c.ReadTuples(ctx, ReadTupleFilter{Object: object, User: user})
 	if err != nil {
-		return fmt.Errorf("getting authorization model: %w", err)
+		return err
 	}
 
-	for _, role := range []string{"admin", "writer", "executor", "reader"} {
-		_, _ = c.getClient(ctx, WriteMode).Write(ctx, &openfga.WriteRequest{
-			StoreId:              c.storeID,
-			AuthorizationModelId: modelID,
-			Deletes: &openfga.WriteRequestDeletes{
-				TupleKeys: []*openfga.TupleKeyWithoutCondition{
-					{
-						User:     user,
-						Relation: role,
-						Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
-					},
-				},
-			},
-		})
+	var deletes []*openfga.TupleKeyWithoutCondition
+	for _, t := range existing {
+		if slices.Contains(standardRoles, t.Relation) {
+			deletes = append(deletes, &openfga.TupleKeyWithoutCondition{User: t.User, Relation: t.Relation, Object: t.Object})
+		}
 	}
 
-	// Invalidate permission cache for the object (all users)
-	c.invalidateObjectCache(ctx, objectType, objectUID.String())
+	err = c.writeTuples(ctx, nil, deletes)
+	c.audit(ctx, op, "", existing, nil, deletes, err)
 
-	// Also invalidate user-specific cache to ensure any cached results
-	// for this specific user are immediately cleared
-	c.invalidateUserObjectCache(ctx, user, objectType, objectUID.String())
+	// Invalidate permission cache for the object (all users, including any
+	// previously cached "denied" results for this specific user)
+	c.invalidateObjectCache(ctx, objectType, objectUID.String())
 
 	// Invalidate ListPermissions cache for the affected user.
 	if user == "user:*" || user == "visitor:*" {
@@ -1139,7 +1346,7 @@ func (c *ACLClient) DeleteResourcePermission(ctx context.Context, objectType str
 		c.invalidateListPermissionsCacheForUser(ctx, user)
 	}
 
-	return nil
+	return err
 }
 
 // SetPublicPermission sets public permissions on a resource.
@@ -1147,14 +1354,14 @@ func (c *ACLClient) DeleteResourcePermission(ctx context.Context, objectType str
 func (c *ACLClient) SetPublicPermission(ctx context.Context, objectType string, objectUID uuid.UUID) error {
 	// Set reader permission for user:* and visitor:*
 	for _, t := range []string{"user", "visitor"} {
-		err := c.SetResourcePermission(ctx, objectType, objectUID, fmt.Sprintf("%s:*", t), "reader", true)
+		err := c.setResourcePermission(ctx, AuditSetPublicPermission, objectType, objectUID, fmt.Sprintf("%s:*", t), "reader", true, nil)
 		if err != nil {
 			return err
 		}
 	}
 
 	// Set executor permission for user:*
-	err := c.SetResourcePermission(ctx, objectType, objectUID, "user:*", "executor", true)
+	err := c.setResourcePermission(ctx, AuditSetPublicPermission, objectType, objectUID, "user:*", "executor", true, nil)
 	if err != nil {
 		return err
 	}
@@ -1166,7 +1373,7 @@ func (c *ACLClient) SetPublicPermission(ctx context.Context, objectType string,
 // This removes reader and executor permissions for user:* and visitor:*.
 func (c *ACLClient) DeletePublicPermission(ctx context.Context, objectType string, objectUID uuid.UUID) error {
 	for _, t := range []string{"user", "visitor"} {
-		err := c.DeleteResourcePermission(ctx, objectType, objectUID, fmt.Sprintf("%s:*", t))
+		err := c.deleteResourcePermission(ctx, AuditDeletePublicPermission, objectType, objectUID, fmt.Sprintf("%s:*", t))
 		if err != nil {
 			return err
 		}
@@ -1177,6 +1384,10 @@ func (c *ACLClient) DeletePublicPermission(ctx context.Context, objectType strin
 // GetOwner retrieves the owner of a given object.
 // Returns the owner type (user or organization) and owner UID.
 func (c *ACLClient) GetOwner(ctx context.Context, objectType string, objectUID uuid.UUID) (ownerType string, ownerUID string, err error) {
+	if err := c.validate(objectType, "owner"); err != nil {
+		return "", "", err
+	}
+
 	data, err := c.getClient(ctx, ReadMode).Read(ctx, &openfga.ReadRequest{
 		StoreId: c.storeID,
 		TupleKey: &openfga.ReadRequestTupleKey{
@@ -1201,9 +1412,70 @@ func (c *ACLClient) GetOwner(ctx context.Context, objectType string, objectUID u
 	return parts[0], parts[1], nil
 }
 
+// TransferOwnership moves an object to a new owner, e.g. from a user
+// namespace to an organization one. The current owner tuples are read
+// and swapped for the new owner in a single Write request; caches are
+// invalidated and both the old and new owners pinned for
+// read-after-write consistency.
+//
+// newOwnerType must be "user" or "organization" (a trailing "s" is
+// accepted, as in SetOwner); any other value fails with
+// errorsx.ErrOwnerTypeNotMatch. Transferring to the current owner is a
+// no-op.
+func (c *ACLClient) TransferOwnership(ctx context.Context, objectType string, objectUID uuid.UUID, newOwnerType string, newOwnerUID uuid.UUID) error {
+	log, _ := logx.GetZapLogger(ctx)
+
+	// Normalize ownerType
+	newOwnerType = strings.TrimSuffix(newOwnerType, "s")
+	if err := c.validate(objectType, "owner"); err != nil {
+		return err
+	}
+	switch OwnerType(newOwnerType) {
+	case OwnerTypeUser, OwnerTypeOrganization:
+	default:
+		return fmt.Errorf("%w: unsupported owner type %q", errorsx.ErrOwnerTypeNotMatch, newOwnerType)
+	}
+
+	newOwner := fmt.Sprintf("%s:%s", newOwnerType, newOwnerUID.String())
+	current, err := c.ReadTuples(ctx, ReadTupleFilter{
+		Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
+		Relation: "owner",
+	})
+	if err != nil {
+		return err
+	}
+
+	tx := c.Begin()
+	tx.op, tx.relation, tx.before = AuditTransferOwnership, "owner", current
+	alreadyOwner := false
+	for _, t := range current {
+		if t.User == newOwner {
+			alreadyOwner = true
+			continue
+		}
+		tx.Revoke(objectType, objectUID, t.User, "owner")
+	}
+	if alreadyOwner && tx.Len() == 0 {
+		return nil
+	}
+	tx.Grant(objectType, objectUID, newOwner, "owner")
+
+	log.Debug("TransferOwnership",
+		zap.String("object", fmt.Sprintf("%s:%s", objectType, objectUID.String())),
+		zap.Int("previousOwners", len(current)),
+		zap.String("newOwner", newOwner),
+	)
+
+	return tx.Commit(ctx)
+}
+
 // CheckLinkPermission checks the access over a resource through a shareable link/code.
 // The codeHeaderKey parameter specifies which header to read the share code from.
 func (c *ACLClient) CheckLinkPermission(ctx context.Context, objectType string, objectUID uuid.UUID, role string, codeHeaderKey string) (bool, error) {
+	if err := c.validate(objectType, role); err != nil {
+		return false, err
+	}
+
 	code := resource.GetRequestSingleHeader(ctx, codeHeaderKey)
 	if code == "" {
 		return false, nil
@@ -1214,6 +1486,11 @@ func (c *ACLClient) CheckLinkPermission(ctx context.Context, objectType string,
 		return false, fmt.Errorf("getting authorization model: %w", err)
 	}
 
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	data, err := c.getClient(ctx, ReadMode).Check(ctx, &openfga.CheckRequest{
 		StoreId:              c.storeID,
 		AuthorizationModelId: modelID,
@@ -1222,6 +1499,7 @@ func (c *ACLClient) CheckLinkPermission(ctx context.Context, objectType string,
 			Relation: role,
 			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
 		},
+		Context: reqContext,
 	})
 	if err != nil {
 		return false, fmt.Errorf("requesting permissions from ACL service: %w", err)
@@ -1231,13 +1509,23 @@ func (c *ACLClient) CheckLinkPermission(ctx context.Context, objectType string,
 }
 
 // CheckShareLinkPermission checks if a share link token has the specified permission for a resource.
-// This is used to authorize anonymous access via share links.
+// This is used to authorize anonymous access via share links. The current time is passed as
+// request context, so share links granted with GrantUntil stop working at their expiry.
 func (c *ACLClient) CheckShareLinkPermission(ctx context.Context, shareToken string, objectType string, objectUID uuid.UUID, relation string) (bool, error) {
+	if err := c.validate(objectType, relation); err != nil {
+		return false, err
+	}
+
 	modelID, err := c.getAuthorizationModelID(ctx)
 	if err != nil {
 		return false, fmt.Errorf("getting authorization model: %w", err)
 	}
 
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
 	data, err := c.getClient(ctx, ReadMode).Check(ctx, &openfga.CheckRequest{
 		StoreId:              c.storeID,
 		AuthorizationModelId: modelID,
@@ -1246,6 +1534,7 @@ func (c *ACLClient) CheckShareLinkPermission(ctx context.Context, shareToken str
 			Relation: relation,
 			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
 		},
+		Context: reqContext,
 	})
 	if err != nil {
 		if statusErr, ok := status.FromError(err); ok {
@@ -1337,6 +1626,13 @@ func (c *ACLClient) CheckPermissionWithShareLink(
 // sets Instill-Requester-Uid to the org UID. This check ensures the
 // authenticated user is a member of that organization.
 //
+// Service accounts and API tokens may act on behalf of namespaces as well.
+// The requester may be any namespace type of the model, named by
+// Instill-Requester-Type, and backends forwarding a request on behalf of
+// another namespace set Instill-Requester-Chain instead: every namespace
+// of the chain must hold the configured relation (ImpersonationConfig) on
+// the next one, starting from the authenticated subject.
+//
 // Visitors skip this check: they have no namespace membership to validate.
 // Their per-resource access is gated by CheckPermission using the
 // visitor:{uid} FGA identity.
@@ -1345,24 +1641,48 @@ func (c *ACLClient) CheckRequesterPermission(ctx context.Context) error {
 	if authType == "visitor" {
 		return nil
 	}
-	if authType != "user" {
+	if authType != "user" && !isMachineSubject(authType) {
 		return fmt.Errorf("%w: unauthenticated user", errorsx.ErrUnauthenticated)
 	}
 
-	requester := resource.GetRequestSingleHeader(ctx, constant.HeaderRequesterUIDKey)
-	authenticatedUser := resource.GetRequestSingleHeader(ctx, constant.HeaderUserUIDKey)
-	if requester == "" || authenticatedUser == requester {
-		return nil
+	hops, err := requesterChain(ctx)
+	if err != nil || len(hops) == 0 {
+		return err
+	}
+	if maxHops := c.config.Impersonation.maxHops(); len(hops) > maxHops {
+		return fmt.Errorf("%w: requester chain exceeds %d namespaces", errorsx.ErrPermissionDenied, maxHops)
 	}
 
-	// The only impersonation that's currently implemented is switching to an organization namespace
-	isMember, err := c.CheckPermission(ctx, "organization", uuid.FromStringOrNil(requester), "member")
+	subjectType, subjectUID, err := resolveACLSubject(ctx)
 	if err != nil {
-		return fmt.Errorf("checking organization membership: %w", err)
+		return err
 	}
+	actor := true
+	for _, hop := range hops {
+		// Acting as oneself needs no permission.
+		if hop.UID.String() == subjectUID {
+			continue
+		}
+
+		relation := c.config.Impersonation.relation(hop.Type)
+		if err := c.validate(hop.Type, relation); err != nil {
+			return err
+		}
+
+		var allowed bool
+		if actor {
+			allowed, err = c.CheckPermission(ctx, hop.Type, hop.UID, relation)
+		} else {
+			allowed, err = c.checkSubject(ctx, subjectType+":"+subjectUID, hop.Type, hop.UID, relation)
+		}
+		if err != nil {
+			return fmt.Errorf("checking %s %s: %w", hop.Type, relation, err)
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s:%s can't act on behalf of %s", errorsx.ErrPermissionDenied, subjectType, subjectUID, hop)
+		}
 
-	if !isMember {
-		return fmt.Errorf("%w: authenticated user doesn't belong to requester organization", errorsx.ErrPermissionDenied)
+		subjectType, subjectUID, actor = hop.Type, hop.UID.String(), false
 	}
 
 	return nil
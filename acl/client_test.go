@@ -37,9 +37,18 @@ var (
 
 type mockFGA struct {
 	checkFn               func(ctx context.Context, req *openfga.CheckRequest) (*openfga.CheckResponse, error)
+	batchCheckFn          func(ctx context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error)
 	readFn                func(ctx context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error)
 	writeFn               func(ctx context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error)
 	streamedListObjectsFn func(ctx context.Context, req *openfga.StreamedListObjectsRequest) (openfga.OpenFGAService_StreamedListObjectsClient, error)
+	listStoresFn          func(ctx context.Context, req *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error)
+	readModelsFn          func(ctx context.Context, req *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error)
+	readModelFn           func(ctx context.Context, req *openfga.ReadAuthorizationModelRequest) (*openfga.ReadAuthorizationModelResponse, error)
+	writeModelFn          func(ctx context.Context, req *openfga.WriteAuthorizationModelRequest) (*openfga.WriteAuthorizationModelResponse, error)
+	getStoreFn            func(ctx context.Context, req *openfga.GetStoreRequest) (*openfga.GetStoreResponse, error)
+	createStoreFn         func(ctx context.Context, req *openfga.CreateStoreRequest) (*openfga.CreateStoreResponse, error)
+	listUsersFn           func(ctx context.Context, req *openfga.ListUsersRequest) (*openfga.ListUsersResponse, error)
+	expandFn              func(ctx context.Context, req *openfga.ExpandRequest) (*openfga.ExpandResponse, error)
 }
 
 func (m *mockFGA) Check(ctx context.Context, in *openfga.CheckRequest, _ ...grpc.CallOption) (*openfga.CheckResponse, error) {
@@ -49,6 +58,13 @@ func (m *mockFGA) Check(ctx context.Context, in *openfga.CheckRequest, _ ...grpc
 	return &openfga.CheckResponse{Allowed: false}, nil
 }
 
+func (m *mockFGA) BatchCheck(ctx context.Context, in *openfga.BatchCheckRequest, _ ...grpc.CallOption) (*openfga.BatchCheckResponse, error) {
+	if m.batchCheckFn != nil {
+		return m.batchCheckFn(ctx, in)
+	}
+	return &openfga.BatchCheckResponse{}, nil
+}
+
 func (m *mockFGA) Read(ctx context.Context, in *openfga.ReadRequest, _ ...grpc.CallOption) (*openfga.ReadResponse, error) {
 	if m.readFn != nil {
 		return m.readFn(ctx, in)
@@ -70,17 +86,29 @@ func (m *mockFGA) StreamedListObjects(ctx context.Context, in *openfga.StreamedL
 	return &mockStream{items: nil}, nil
 }
 
-func (m *mockFGA) Expand(context.Context, *openfga.ExpandRequest, ...grpc.CallOption) (*openfga.ExpandResponse, error) {
+func (m *mockFGA) Expand(ctx context.Context, in *openfga.ExpandRequest, _ ...grpc.CallOption) (*openfga.ExpandResponse, error) {
+	if m.expandFn != nil {
+		return m.expandFn(ctx, in)
+	}
 	panic("not used")
 }
-func (m *mockFGA) ReadAuthorizationModels(context.Context, *openfga.ReadAuthorizationModelsRequest, ...grpc.CallOption) (*openfga.ReadAuthorizationModelsResponse, error) {
-	panic("not used")
+func (m *mockFGA) ReadAuthorizationModels(ctx context.Context, in *openfga.ReadAuthorizationModelsRequest, _ ...grpc.CallOption) (*openfga.ReadAuthorizationModelsResponse, error) {
+	if m.readModelsFn != nil {
+		return m.readModelsFn(ctx, in)
+	}
+	panic("readModelsFn not set")
 }
-func (m *mockFGA) ReadAuthorizationModel(context.Context, *openfga.ReadAuthorizationModelRequest, ...grpc.CallOption) (*openfga.ReadAuthorizationModelResponse, error) {
-	panic("not used")
+func (m *mockFGA) ReadAuthorizationModel(ctx context.Context, in *openfga.ReadAuthorizationModelRequest, _ ...grpc.CallOption) (*openfga.ReadAuthorizationModelResponse, error) {
+	if m.readModelFn != nil {
+		return m.readModelFn(ctx, in)
+	}
+	panic("readModelFn not set")
 }
-func (m *mockFGA) WriteAuthorizationModel(context.Context, *openfga.WriteAuthorizationModelRequest, ...grpc.CallOption) (*openfga.WriteAuthorizationModelResponse, error) {
-	panic("not used")
+func (m *mockFGA) WriteAuthorizationModel(ctx context.Context, in *openfga.WriteAuthorizationModelRequest, _ ...grpc.CallOption) (*openfga.WriteAuthorizationModelResponse, error) {
+	if m.writeModelFn != nil {
+		return m.writeModelFn(ctx, in)
+	}
+	panic("writeModelFn not set")
 }
 func (m *mockFGA) WriteAssertions(context.Context, *openfga.WriteAssertionsRequest, ...grpc.CallOption) (*openfga.WriteAssertionsResponse, error) {
 	panic("not used")
@@ -91,8 +119,11 @@ func (m *mockFGA) ReadAssertions(context.Context, *openfga.ReadAssertionsRequest
 func (m *mockFGA) ReadChanges(context.Context, *openfga.ReadChangesRequest, ...grpc.CallOption) (*openfga.ReadChangesResponse, error) {
 	panic("not used")
 }
-func (m *mockFGA) CreateStore(context.Context, *openfga.CreateStoreRequest, ...grpc.CallOption) (*openfga.CreateStoreResponse, error) {
-	panic("not used")
+func (m *mockFGA) CreateStore(ctx context.Context, in *openfga.CreateStoreRequest, _ ...grpc.CallOption) (*openfga.CreateStoreResponse, error) {
+	if m.createStoreFn != nil {
+		return m.createStoreFn(ctx, in)
+	}
+	panic("createStoreFn not set")
 }
 func (m *mockFGA) UpdateStore(context.Context, *openfga.UpdateStoreRequest, ...grpc.CallOption) (*openfga.UpdateStoreResponse, error) {
 	panic("not used")
@@ -100,17 +131,26 @@ func (m *mockFGA) UpdateStore(context.Context, *openfga.UpdateStoreRequest, ...g
 func (m *mockFGA) DeleteStore(context.Context, *openfga.DeleteStoreRequest, ...grpc.CallOption) (*openfga.DeleteStoreResponse, error) {
 	panic("not used")
 }
-func (m *mockFGA) GetStore(context.Context, *openfga.GetStoreRequest, ...grpc.CallOption) (*openfga.GetStoreResponse, error) {
-	panic("not used")
+func (m *mockFGA) GetStore(ctx context.Context, in *openfga.GetStoreRequest, _ ...grpc.CallOption) (*openfga.GetStoreResponse, error) {
+	if m.getStoreFn != nil {
+		return m.getStoreFn(ctx, in)
+	}
+	panic("getStoreFn not set")
 }
-func (m *mockFGA) ListStores(context.Context, *openfga.ListStoresRequest, ...grpc.CallOption) (*openfga.ListStoresResponse, error) {
-	panic("not used")
+func (m *mockFGA) ListStores(ctx context.Context, in *openfga.ListStoresRequest, _ ...grpc.CallOption) (*openfga.ListStoresResponse, error) {
+	if m.listStoresFn != nil {
+		return m.listStoresFn(ctx, in)
+	}
+	panic("listStoresFn not set")
 }
 func (m *mockFGA) ListObjects(context.Context, *openfga.ListObjectsRequest, ...grpc.CallOption) (*openfga.ListObjectsResponse, error) {
 	panic("not used")
 }
-func (m *mockFGA) ListUsers(context.Context, *openfga.ListUsersRequest, ...grpc.CallOption) (*openfga.ListUsersResponse, error) {
-	panic("not used")
+func (m *mockFGA) ListUsers(ctx context.Context, in *openfga.ListUsersRequest, _ ...grpc.CallOption) (*openfga.ListUsersResponse, error) {
+	if m.listUsersFn != nil {
+		return m.listUsersFn(ctx, in)
+	}
+	panic("listUsersFn not set")
 }
 
 // --- Mock stream for ListPermissions ---
@@ -156,29 +196,47 @@ func ctxWithHeaders(headers map[string]string) context.Context {
 	return metadata.NewIncomingContext(context.Background(), md)
 }
 
+// existingRolesReadFn answers every Read with one tuple per role for
+// the requested user and object, mimicking a subject that currently
+// holds all of those roles.
+func existingRolesReadFn(roles ...string) func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+	return func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+		tuples := make([]*openfga.Tuple, 0, len(roles))
+		for _, role := range roles {
+			tuples = append(tuples, &openfga.Tuple{Key: &openfga.TupleKey{
+				User:     req.TupleKey.User,
+				Relation: role,
+				Object:   req.TupleKey.Object,
+			}})
+		}
+		return &openfga.ReadResponse{Tuples: tuples}, nil
+	}
+}
+
 func newTestClient(fga *mockFGA) *ACLClient {
-	return &ACLClient{
+	c := &ACLClient{
 		writeClient: fga,
 		readClient:  fga,
 		storeID:     testStoreID,
-		modelID:     testModelID,
 	}
+	c.modelID.Store(&testModelID)
+	return c
 }
 
 func newTestClientWithCache(fga *mockFGA) (*ACLClient, *miniredis.Miniredis) {
 	mr := miniredis.RunT(&testing.T{})
 	rc := redis.NewClient(&redis.Options{Addr: mr.Addr()})
-	return &ACLClient{
+	c := &ACLClient{
 		writeClient:            fga,
 		readClient:             fga,
-		redisClient:            rc,
+		cache:                  NewRedisCache(rc, CacheConfig{TTL: 60}),
 		storeID:                testStoreID,
-		modelID:                testModelID,
 		cacheEnabled:           true, // CheckPermission cache
 		listPermissionsCacheOn: true, // ListPermissions / ListPublicPermissions cache
-		cacheTTL:               60 * time.Second,
 		listObjectsCfg:         DefaultListObjectsConfig(),
-	}, mr
+	}
+	c.modelID.Store(&testModelID)
+	return c, mr
 }
 
 func userCtx(userUID string) context.Context {
@@ -706,6 +764,85 @@ func TestGetOwner_NoOwnerReturnsError(t *testing.T) {
 	}
 }
 
+// ============================================================
+// TransferOwnership — swap owner tuples
+// ============================================================
+
+func TestTransferOwnership_SwapsOwnerInSingleWrite(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	fga := &mockFGA{
+		readFn: func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			if req.TupleKey.Relation != "owner" {
+				t.Errorf("should read owner tuples, got %+v", req.TupleKey)
+			}
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{{
+				Key: &openfga.TupleKey{User: "user:" + testUserUID, Relation: "owner", Object: req.TupleKey.Object},
+			}}}, nil
+		},
+		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			writes = append(writes, req)
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+	c.config.Replica.ReplicationTimeFrame = 30
+
+	oldList := listCacheEntry("user", testUserUID, "pipeline", "reader")
+	_ = mr.Set(oldList.Key, "[]")
+
+	err := c.TransferOwnership(context.Background(), "pipeline", testObjectUID, "organizations", uuid.FromStringOrNil(testOrgUID))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 {
+		t.Fatalf("expected a single Write request, got %d", len(writes))
+	}
+	req := writes[0]
+	if len(req.Deletes.GetTupleKeys()) != 1 || req.Deletes.TupleKeys[0].User != "user:"+testUserUID {
+		t.Errorf("should delete the old owner, got %v", req.Deletes.GetTupleKeys())
+	}
+	if len(req.Writes.GetTupleKeys()) != 1 || req.Writes.TupleKeys[0].User != "organization:"+testOrgUID {
+		t.Errorf("should write the new owner, got %v", req.Writes.GetTupleKeys())
+	}
+	if isCached(t, c, oldList) {
+		t.Error("old owner's list cache should be invalidated")
+	}
+	for _, uid := range []string{testUserUID, testOrgUID} {
+		if !c.IsUserPinned(userCtx(uid)) {
+			t.Errorf("%s should be pinned", uid)
+		}
+	}
+}
+
+func TestTransferOwnership_SameOwnerIsNoop(t *testing.T) {
+	fga := &mockFGA{
+		readFn: func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{{
+				Key: &openfga.TupleKey{User: "user:" + testUserUID, Relation: "owner", Object: req.TupleKey.Object},
+			}}}, nil
+		},
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			t.Fatal("transferring to the current owner should not write")
+			return nil, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	if err := c.TransferOwnership(context.Background(), "pipeline", testObjectUID, "user", uuid.FromStringOrNil(testUserUID)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTransferOwnership_UnsupportedOwnerType(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+
+	err := c.TransferOwnership(context.Background(), "pipeline", testObjectUID, "team", testObjectUID)
+	if !errors.Is(err, errorsx.ErrOwnerTypeNotMatch) {
+		t.Fatalf("expected ErrOwnerTypeNotMatch, got %v", err)
+	}
+}
+
 // ============================================================
 // SetResourcePermission / DeleteResourcePermission
 // ============================================================
@@ -740,6 +877,7 @@ func TestSetResourcePermission_DisableOnlyDeletes(t *testing.T) {
 	var writesCalled int
 
 	fga := &mockFGA{
+		readFn: existingRolesReadFn("reader"),
 		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
 			writesCalled++
 			if req.Writes != nil {
@@ -763,6 +901,7 @@ func TestDeleteResourcePermission_DeletesAllStandardRoles(t *testing.T) {
 	var deletedRoles []string
 
 	fga := &mockFGA{
+		readFn: existingRolesReadFn("owner", "admin", "writer", "executor", "reader"),
 		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
 			if req.Deletes != nil {
 				for _, tk := range req.Deletes.TupleKeys {
@@ -781,6 +920,9 @@ func TestDeleteResourcePermission_DeletesAllStandardRoles(t *testing.T) {
 
 	expected := map[string]bool{"admin": true, "writer": true, "executor": true, "reader": true}
 	for _, role := range deletedRoles {
+		if role == "owner" {
+			t.Error("owner is not a standard role and must not be deleted")
+		}
 		delete(expected, role)
 	}
 	if len(expected) > 0 {
@@ -1295,6 +1437,7 @@ func TestDeletePublicPermission_RemovesBothUserAndVisitor(t *testing.T) {
 	var deletedUsers []string
 
 	fga := &mockFGA{
+		readFn: existingRolesReadFn("reader"),
 		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
 			if req.Deletes != nil {
 				for _, tk := range req.Deletes.TupleKeys {
@@ -1395,11 +1538,9 @@ func TestGetClient_PinnedUserReadsFromPrimary(t *testing.T) {
 	c := &ACLClient{
 		writeClient:  primary,
 		readClient:   replica,
-		redisClient:  rc,
+		cache:        NewRedisCache(rc, CacheConfig{TTL: 60}),
 		storeID:      testStoreID,
-		modelID:      testModelID,
 		cacheEnabled: true,
-		cacheTTL:     60 * time.Second,
 		config:       Config{Replica: ReplicaConfig{ReplicationTimeFrame: 30}},
 	}
 	ctx := userCtx(testUserUID)
@@ -1423,9 +1564,8 @@ func TestGetClient_UnpinnedUserReadsFromReplica(t *testing.T) {
 	c := &ACLClient{
 		writeClient: primary,
 		readClient:  replica,
-		redisClient: rc,
+		cache:       NewRedisCache(rc, CacheConfig{}),
 		storeID:     testStoreID,
-		modelID:     testModelID,
 	}
 	ctx := userCtx(testUserUID)
 
@@ -1443,7 +1583,6 @@ func TestGetClient_WriteAlwaysGoesToPrimary(t *testing.T) {
 		writeClient: primary,
 		readClient:  replica,
 		storeID:     testStoreID,
-		modelID:     testModelID,
 	}
 	ctx := userCtx(testUserUID)
 
@@ -1610,7 +1749,7 @@ func TestGetStoreID_ReturnsCachedValue(t *testing.T) {
 }
 
 func TestGetAuthorizationModelID_EmptyReturnsError(t *testing.T) {
-	c := &ACLClient{modelID: ""}
+	c := &ACLClient{}
 	_, err := c.getAuthorizationModelID(context.Background())
 	if err == nil {
 		t.Fatal("empty model ID should return error")
@@ -0,0 +1,140 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// Names used by GrantUntil. The authorization model must declare the
+// condition and use it on the granted relations, e.g.:
+//
+//	condition not_expired(current_time: timestamp, expires_at: timestamp) {
+//	  current_time < expires_at
+//	}
+//
+//	define reader: [user, user with not_expired]
+const (
+	ExpiryConditionName       = "not_expired"
+	ConditionParamCurrentTime = "current_time"
+	ConditionParamExpiresAt   = "expires_at"
+)
+
+// Condition is an OpenFGA condition attached to a tuple. The tuple only
+// grants access when the condition, evaluated with Context merged with
+// the request context of the check, holds.
+type Condition struct {
+	// Name is the name of a condition of the authorization model.
+	Name string
+	// Context holds the parameter values stored with the tuple. Values
+	// must be representable as JSON.
+	Context map[string]any
+}
+
+// ExpiresAt returns the condition GrantUntil writes: the tuple stops
+// granting access at expiry.
+func ExpiresAt(expiry time.Time) *Condition {
+	return &Condition{
+		Name:    ExpiryConditionName,
+		Context: map[string]any{ConditionParamExpiresAt: expiry.UTC().Format(time.RFC3339Nano)},
+	}
+}
+
+// conditionExpiry returns the expiry stored in an ExpiryConditionName
+// condition, or the zero time.
+func conditionExpiry(rc *openfga.RelationshipCondition) time.Time {
+	if rc.GetName() != ExpiryConditionName {
+		return time.Time{}
+	}
+	expiry, _ := time.Parse(time.RFC3339Nano, rc.GetContext().GetFields()[ConditionParamExpiresAt].GetStringValue())
+	return expiry
+}
+
+func (cond *Condition) toFGA() (*openfga.RelationshipCondition, error) {
+	if cond == nil {
+		return nil, nil
+	}
+	if cond.Name == "" {
+		return nil, fmt.Errorf("%w: condition name is empty", errorsx.ErrInvalidArgument)
+	}
+	rc := &openfga.RelationshipCondition{Name: cond.Name}
+	if len(cond.Context) > 0 {
+		s, err := structpb.NewStruct(cond.Context)
+		if err != nil {
+			return nil, fmt.Errorf("%w: condition %s context: %v", errorsx.ErrInvalidArgument, cond.Name, err)
+		}
+		rc.Context = s
+	}
+	return rc, nil
+}
+
+type checkContextKey struct{}
+
+// WithCheckContext returns a context whose permission checks pass params
+// to OpenFGA as request context, e.g. the caller's IP address for an
+// allowlist condition. Params are merged with any set earlier on ctx.
+//
+// Results of checks carrying request context depend on it, so they
+// bypass the permission and list caches.
+func WithCheckContext(ctx context.Context, params map[string]any) context.Context {
+	merged := map[string]any{}
+	if prev, ok := ctx.Value(checkContextKey{}).(map[string]any); ok {
+		maps.Copy(merged, prev)
+	}
+	maps.Copy(merged, params)
+	return context.WithValue(ctx, checkContextKey{}, merged)
+}
+
+// hasCheckContext reports whether the caller set request context with
+// WithCheckContext.
+func hasCheckContext(ctx context.Context) bool {
+	params, ok := ctx.Value(checkContextKey{}).(map[string]any)
+	return ok && len(params) > 0
+}
+
+// requestContext returns the request context sent with Check, ListObjects
+// and ListUsers calls: the parameters set with WithCheckContext and
+// current_time, unless the caller set it.
+func requestContext(ctx context.Context) (*structpb.Struct, error) {
+	params := map[string]any{ConditionParamCurrentTime: time.Now().UTC().Format(time.RFC3339Nano)}
+	if custom, ok := ctx.Value(checkContextKey{}).(map[string]any); ok {
+		maps.Copy(params, custom)
+	}
+	s, err := structpb.NewStruct(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: check context: %v", errorsx.ErrInvalidArgument, err)
+	}
+	return s, nil
+}
+
+// GrantUntil grants role on the object to user until expiry, replacing
+// any standard role the user holds like SetResourcePermission. The tuple
+// is written with the ExpiryConditionName condition, so OpenFGA stops
+// honouring it at expiry without a cleanup job; the expired tuple stays
+// in the store until the grant is changed or deleted. Extending a
+// previous GrantUntil of the same role deletes and writes the grant in
+// two requests, as described on SetResourcePermission.
+//
+// Cached check results are not expired with the grant: an allowed
+// result cached just before expiry is served until its cache TTL ends.
+func (c *ACLClient) GrantUntil(ctx context.Context, objectType string, objectUID uuid.UUID, user, role string, expiry time.Time) error {
+	if !expiry.After(time.Now()) {
+		return fmt.Errorf("%w: expiry %s is in the past", errorsx.ErrInvalidArgument, expiry.Format(time.RFC3339))
+	}
+	return c.SetResourcePermissionWithCondition(ctx, objectType, objectUID, user, role, ExpiresAt(expiry))
+}
+
+// SetResourcePermissionWithCondition grants role on the object to user
+// under cond, replacing any standard role the user holds. A nil cond
+// grants the role unconditionally, like SetResourcePermission.
+func (c *ACLClient) SetResourcePermissionWithCondition(ctx context.Context, objectType string, objectUID uuid.UUID, user, role string, cond *Condition) error {
+	return c.setResourcePermission(ctx, AuditSetResourcePermission, objectType, objectUID, user, role, true, cond)
+}
@@ -0,0 +1,271 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// recordWrites returns a Write mock that appends every request to
+// *writes.
+func recordWrites(writes *[]*openfga.WriteRequest) func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+	return func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+		*writes = append(*writes, req)
+		return &openfga.WriteResponse{}, nil
+	}
+}
+
+// ============================================================
+// GrantUntil — conditional tuples
+// ============================================================
+
+func TestGrantUntil_WritesExpiryCondition(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c := newTestClient(&mockFGA{readFn: existingRolesReadFn("reader"), writeFn: recordWrites(&writes)})
+	expiry := time.Now().Add(time.Hour)
+
+	if err := c.GrantUntil(context.Background(), "file", testObjectUID, "user:"+testUserUID, "writer", expiry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 {
+		t.Fatalf("expected a single Write request, got %d", len(writes))
+	}
+	if len(writes[0].Deletes.GetTupleKeys()) != 1 {
+		t.Errorf("the previous role should be deleted, got %v", writes[0].Deletes.GetTupleKeys())
+	}
+	cond := writes[0].Writes.GetTupleKeys()[0].GetCondition()
+	if cond.GetName() != ExpiryConditionName {
+		t.Fatalf("expected the %s condition, got %v", ExpiryConditionName, cond)
+	}
+	got, err := time.Parse(time.RFC3339Nano, cond.GetContext().GetFields()[ConditionParamExpiresAt].GetStringValue())
+	if err != nil || !got.Equal(expiry) {
+		t.Errorf("expected expires_at %s, got %s (%v)", expiry, got, err)
+	}
+}
+
+func TestGrantUntil_ReplacesConditionalGrantOfSameRole(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c := newTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{{Key: &openfga.TupleKey{
+				Object:    "file:" + testObjectUID.String(),
+				Relation:  "reader",
+				User:      "user:" + testUserUID,
+				Condition: &openfga.RelationshipCondition{Name: ExpiryConditionName},
+			}}}}, nil
+		},
+		writeFn: recordWrites(&writes),
+	})
+
+	if err := c.GrantUntil(context.Background(), "file", testObjectUID, "user:"+testUserUID, "reader", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// OpenFGA rejects deleting and writing the same tuple in one request.
+	if len(writes) != 2 || writes[0].Writes != nil || writes[1].Deletes != nil {
+		t.Fatalf("expected a delete then a write, got %v", writes)
+	}
+	if writes[1].Writes.TupleKeys[0].GetCondition() == nil {
+		t.Error("the new grant should carry the condition")
+	}
+}
+
+func TestSetResourcePermission_MakesConditionalGrantPermanent(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c := newTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{{Key: &openfga.TupleKey{
+				Object:    "file:" + testObjectUID.String(),
+				Relation:  "reader",
+				User:      "user:" + testUserUID,
+				Condition: &openfga.RelationshipCondition{Name: ExpiryConditionName},
+			}}}}, nil
+		},
+		writeFn: recordWrites(&writes),
+	})
+
+	if err := c.SetResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID, "reader", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 2 || writes[1].Writes.TupleKeys[0].GetCondition() != nil {
+		t.Errorf("an expiring grant should be replaced by an unconditional one, got %v", writes)
+	}
+}
+
+func TestSetResourcePermission_InvalidatesCacheWhenOnlyReplacementDeleteSucceeds(t *testing.T) {
+	allowed := true
+	writeCalls := 0
+	fga := &mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{{Key: &openfga.TupleKey{
+				Object:    "file:" + testObjectUID.String(),
+				Relation:  "reader",
+				User:      "user:" + testUserUID,
+				Condition: &openfga.RelationshipCondition{Name: ExpiryConditionName},
+			}}}}, nil
+		},
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return &openfga.CheckResponse{Allowed: allowed}, nil
+		},
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			writeCalls++
+			if writeCalls > 1 {
+				return nil, errors.New("write failed")
+			}
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+	ctx := userCtx(testUserUID)
+
+	if granted, _ := c.CheckPermission(ctx, "file", testObjectUID, "reader"); !granted {
+		t.Fatal("should be allowed initially")
+	}
+
+	// The conditional grant is deleted, then neither the new grant nor
+	// the old one can be written.
+	err := c.SetResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID, "reader", true)
+	if err == nil || !strings.Contains(err.Error(), "could not be restored") {
+		t.Fatalf("expected the removed grant to be reported, got %v", err)
+	}
+	allowed = false
+
+	if granted, _ := c.CheckPermission(ctx, "file", testObjectUID, "reader"); granted {
+		t.Error("the cached answer should be invalidated once the grant is deleted")
+	}
+}
+
+func TestSetResourcePermission_RestoresReplacedGrantOnFailure(t *testing.T) {
+	expiring, err := (&Condition{Name: ExpiryConditionName, Context: map[string]any{
+		ConditionParamExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339Nano),
+	}}).toFGA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var writes []*openfga.WriteRequest
+	c := newTestClient(&mockFGA{
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return &openfga.ReadResponse{Tuples: []*openfga.Tuple{{Key: &openfga.TupleKey{
+				Object:    "file:" + testObjectUID.String(),
+				Relation:  "reader",
+				User:      "user:" + testUserUID,
+				Condition: expiring,
+			}}}}, nil
+		},
+		writeFn: func(ctx context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			writes = append(writes, req)
+			if len(writes) == 2 {
+				return nil, errors.New("write failed")
+			}
+			return &openfga.WriteResponse{}, nil
+		},
+	})
+
+	err = c.SetResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID, "reader", true)
+	if err == nil || strings.Contains(err.Error(), "could not be restored") {
+		t.Fatalf("expected the write error alone, got %v", err)
+	}
+	if len(writes) != 3 || writes[2].Deletes != nil {
+		t.Fatalf("expected the replaced grant to be written back, got %v", writes)
+	}
+	if got := writes[2].Writes.TupleKeys[0].GetCondition(); got.GetName() != ExpiryConditionName ||
+		got.GetContext().GetFields()[ConditionParamExpiresAt].GetStringValue() != expiring.GetContext().GetFields()[ConditionParamExpiresAt].GetStringValue() {
+		t.Errorf("the restored grant should keep its condition, got %v", got)
+	}
+}
+
+func TestGrantUntil_RejectsPastExpiry(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+
+	err := c.GrantUntil(context.Background(), "file", testObjectUID, "user:"+testUserUID, "reader", time.Now().Add(-time.Minute))
+	if !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// ============================================================
+// Request context — Check and list calls
+// ============================================================
+
+func TestCheckShareLinkPermission_PassesCurrentTime(t *testing.T) {
+	var req *openfga.CheckRequest
+	c := newTestClient(&mockFGA{
+		checkFn: func(_ context.Context, r *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			req = r
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	})
+
+	before := time.Now().Add(-time.Second)
+	if _, err := c.CheckShareLinkPermission(context.Background(), "token", "file", testObjectUID, "viewer"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now, err := time.Parse(time.RFC3339Nano, req.GetContext().GetFields()[ConditionParamCurrentTime].GetStringValue())
+	if err != nil || now.Before(before) {
+		t.Errorf("expected current_time in the request context, got %v (%v)", req.GetContext(), err)
+	}
+}
+
+func TestCheckPermission_CheckContextBypassesCache(t *testing.T) {
+	var requests []*openfga.CheckRequest
+	c, mr := newTestClientWithCache(&mockFGA{
+		checkFn: func(_ context.Context, r *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			requests = append(requests, r)
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	})
+	defer mr.Close()
+	ctx := WithCheckContext(userCtx(testUserUID), map[string]any{"ip": "10.0.0.1"})
+
+	for range 2 {
+		if _, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(requests) != 2 {
+		t.Fatalf("checks with request context should not be cached, got %d calls", len(requests))
+	}
+	fields := requests[0].GetContext().GetFields()
+	if fields["ip"].GetStringValue() != "10.0.0.1" || fields[ConditionParamCurrentTime] == nil {
+		t.Errorf("expected the caller's params and current_time, got %v", fields)
+	}
+}
+
+func TestBatchCheckPermission_PassesRequestContext(t *testing.T) {
+	var items []*openfga.BatchCheckItem
+	c := newTestClient(&mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			items = req.Checks
+			result := map[string]*openfga.BatchCheckSingleResult{}
+			for _, item := range req.Checks {
+				result[item.CorrelationId] = &openfga.BatchCheckSingleResult{CheckResult: &openfga.BatchCheckSingleResult_Allowed{Allowed: true}}
+			}
+			return &openfga.BatchCheckResponse{Result: result}, nil
+		},
+	})
+
+	checks := []CheckItem{{ObjectType: "pipeline", ObjectUID: uuid.Must(uuid.NewV4()), Role: "reader"}}
+	if _, err := c.BatchCheckPermission(userCtx(testUserUID), checks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].GetContext().GetFields()[ConditionParamCurrentTime] == nil {
+		t.Errorf("expected current_time in every check item, got %v", items)
+	}
+}
+
+func TestWithCheckContext_RejectsUnsupportedValues(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+	ctx := WithCheckContext(userCtx(testUserUID), map[string]any{"at": time.Now()})
+
+	if _, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
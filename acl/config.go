@@ -1,6 +1,11 @@
 package acl
 
-import "time"
+import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+)
 
 // Config holds the configuration for the ACL client.
 type Config struct {
@@ -16,6 +21,48 @@ type Config struct {
 	// StreamedListObjects code path. Zero values are filled in from
 	// DefaultListObjectsConfig at client construction time.
 	ListObjects ListObjectsConfig
+	// Store selects the OpenFGA store. By default the server must hold
+	// exactly one store.
+	Store StoreConfig
+	// Model selects the authorization model used for checks and writes.
+	Model ModelConfig
+	// Audit receives an AuditEvent for every permission mutation. Auditing
+	// is disabled when nil.
+	Audit AuditSink
+	// Resilience configures timeouts, retries and the circuit breaker of
+	// the OpenFGA calls, and how checks behave during an outage.
+	Resilience ResilienceConfig
+	// Impersonation configures which namespaces a subject may make
+	// requests on behalf of.
+	Impersonation ImpersonationConfig
+}
+
+// StoreConfig holds the OpenFGA store selection, for deployments where
+// several environments or tenants share one OpenFGA server.
+type StoreConfig struct {
+	// ID selects the store by ID. It takes precedence over Name.
+	ID string
+	// Name selects the store by name.
+	Name string
+	// Bootstrap creates the store named Name when it is missing and
+	// writes the model of ModelFile when the store has none. It is
+	// meant for local development and integration tests; in production
+	// the store and model are created by the mgmt-backend migration.
+	Bootstrap bool
+	// ModelFile is the authorization model written by Bootstrap, in
+	// the OpenFGA DSL or, with a .json extension, in JSON.
+	ModelFile string
+}
+
+// validate reports an incomplete bootstrap configuration.
+func (s StoreConfig) validate() error {
+	if !s.Bootstrap {
+		return nil
+	}
+	if s.Name == "" || s.ModelFile == "" {
+		return errors.New("acl: store bootstrap requires Store.Name and Store.ModelFile")
+	}
+	return nil
 }
 
 // ReplicaConfig holds configuration for read replica.
@@ -28,8 +75,16 @@ type ReplicaConfig struct {
 	ReplicationTimeFrame int
 }
 
+// DefaultMemoryCacheSize is the default number of entries held by the
+// memory cache backend.
+const DefaultMemoryCacheSize = 10000
+
 // CacheConfig holds configuration for permission caching.
 type CacheConfig struct {
+	// Backend selects where results and pins are cached. Defaults to
+	// CacheBackendRedis; CacheBackendMemory lets services without Redis
+	// keep both features within a single replica.
+	Backend CacheBackend
 	// Enabled indicates whether CheckPermission Redis caching is enabled.
 	// When OpenFGA's own in-memory cache is already active (OPENFGA_CHECK_QUERY_CACHE_ENABLED),
 	// enabling this adds a Redis hop for negligible benefit — keep it disabled in that case.
@@ -39,8 +94,35 @@ type CacheConfig struct {
 	// so caching the result in Redis provides a significant latency reduction even
 	// when OpenFGA's own cache is active (which only partially covers ListObjects).
 	ListPermissionsEnabled bool
+	// ListSubjectsEnabled indicates whether ListSubjects results are
+	// cached. Entries are invalidated by writes to the listed object;
+	// membership and parent changes are picked up when they expire.
+	ListSubjectsEnabled bool
 	// TTL is the cache time-to-live in seconds (shared by both cache layers).
 	TTL int
+	// LegacyInvalidation additionally deletes cache entries with a SCAN
+	// over the acl:perm: / acl:list: keyspace on every write. Enable it
+	// while replicas running a release without versioned cache keys
+	// share the same Redis, and disable it once the rollout completes:
+	// the SCAN is O(keyspace).
+	LegacyInvalidation bool
+	// Local configures the optional in-process cache in front of Redis.
+	Local LocalCacheConfig
+	// MemorySize bounds the number of entries of the memory backend.
+	// Defaults to DefaultMemoryCacheSize.
+	MemorySize int
+}
+
+// LocalCacheConfig holds configuration for the in-process permission
+// cache. Replicas keep their local caches coherent through Redis
+// pub/sub; the TTL bounds staleness if an invalidation event is lost.
+type LocalCacheConfig struct {
+	// Enabled indicates whether the in-process cache is enabled.
+	Enabled bool
+	// Size is the maximum number of entries. Defaults to 10000.
+	Size int
+	// TTL is the entry time-to-live in seconds. Defaults to 5.
+	TTL int
 }
 
 // DefaultCacheConfig returns the default cache configuration.
@@ -49,6 +131,50 @@ func DefaultCacheConfig() CacheConfig {
 		Enabled:                false, // CheckPermission cache off by default (OpenFGA server cache suffices)
 		ListPermissionsEnabled: true,  // ListPermissions cache on by default (StreamedListObjects is slow)
 		TTL:                    60,
+		Local: LocalCacheConfig{
+			Size: 10000,
+			TTL:  5,
+		},
+	}
+}
+
+// DefaultDiscoveryTimeout bounds how long NewClient retries store
+// discovery and the model lookup before panicking.
+const DefaultDiscoveryTimeout = 30 * time.Second
+
+// DefaultModelRefreshInterval is how often the latest authorization
+// model is re-read when ModelConfig.RefreshInterval is unset.
+const DefaultModelRefreshInterval = time.Minute
+
+// ModelConfig holds the authorization model configuration.
+type ModelConfig struct {
+	// ID pins the client to a specific authorization model. When empty,
+	// the client follows the latest model written to the store.
+	ID string
+	// RefreshInterval is how often the latest authorization model is
+	// re-read so that a model written by a migration is picked up
+	// without a restart. Defaults to DefaultModelRefreshInterval; a
+	// negative value disables the refresh. Ignored when ID is set.
+	RefreshInterval time.Duration
+	// OnChange, when set, is called with the previous and the new model
+	// ID each time the refresh switches models. It runs on the refresh
+	// goroutine and must not block.
+	OnChange func(previousID, newID string)
+	// SkipValidation disables the validation of the object types and
+	// relations passed to the client against the model (see Schema).
+	SkipValidation bool
+}
+
+// refreshInterval returns how often the model watcher runs, or 0 when
+// it is disabled.
+func (m ModelConfig) refreshInterval() time.Duration {
+	switch {
+	case m.ID != "", m.RefreshInterval < 0:
+		return 0
+	case m.RefreshInterval == 0:
+		return DefaultModelRefreshInterval
+	default:
+		return m.RefreshInterval
 	}
 }
 
@@ -76,8 +202,47 @@ type ListObjectsConfig struct {
 	// (Deadline - Slack) and Deadline is still flagged as truncated.
 	// Defaults to 200ms when unset.
 	Slack time.Duration
+	// TruncationStrategy selects how a suspected truncation is handled.
+	// Defaults to TruncationStrategyNone.
+	TruncationStrategy TruncationStrategy
+	// MembershipTypes are the group-like object types whose direct
+	// members TruncationStrategyReadTuples also expands. Defaults to
+	// DefaultMembershipTypes when unset.
+	MembershipTypes []string
+	// MaxFallbackSubjects bounds the number of subjects (the caller and
+	// the usersets it is a direct member of) TruncationStrategyReadTuples
+	// reads grants for. Beyond it the fallback gives up on completeness
+	// and ErrPossiblyTruncated is returned. Defaults to 100 when unset.
+	MaxFallbackSubjects int
 }
 
+// TruncationStrategy selects how ListPermissions and
+// ListPublicPermissions handle a StreamedListObjects result flagged as
+// likely truncated.
+type TruncationStrategy string
+
+const (
+	// TruncationStrategyNone returns the streamed result as is, without
+	// an error. The result is not cached.
+	TruncationStrategyNone TruncationStrategy = ""
+	// TruncationStrategyReadTuples unions the streamed result with the
+	// direct grants read through ReadTuples, for the caller, the
+	// wildcard of its type and the usersets of MembershipTypes the
+	// caller is a direct member of. The grants of the relations the role
+	// inherits from in the model (e.g. owner for reader) are read too.
+	// Objects reachable only through other rewrites (e.g.
+	// `viewer from parent`) are not recovered. When the fallback fails,
+	// hits MaxFallbackSubjects, the role has such rewrites or the model
+	// is unknown (ModelConfig.SkipValidation), the union is returned
+	// with an error wrapping ErrPossiblyTruncated.
+	TruncationStrategyReadTuples TruncationStrategy = "readtuples"
+)
+
+// DefaultMembershipTypes are the object types expanded by
+// TruncationStrategyReadTuples when ListObjectsConfig.MembershipTypes
+// is unset.
+var DefaultMembershipTypes = []string{"organization", "group"}
+
 // DefaultListObjectsConfig returns the truncation-guard defaults that
 // match the OpenFGA server defaults shipped in instill-core.
 func DefaultListObjectsConfig() ListObjectsConfig {
@@ -102,6 +267,12 @@ func (l ListObjectsConfig) resolved() ListObjectsConfig {
 	if l.Slack < 0 {
 		l.Slack = d.Slack
 	}
+	if l.MembershipTypes == nil {
+		l.MembershipTypes = DefaultMembershipTypes
+	}
+	if l.MaxFallbackSubjects <= 0 {
+		l.MaxFallbackSubjects = 100
+	}
 	return l
 }
 
@@ -113,3 +284,169 @@ func (c CacheConfig) CacheTTLDuration() time.Duration {
 	}
 	return time.Duration(c.TTL) * time.Second
 }
+
+// resolved returns a LocalCacheConfig with zero-valued fields filled
+// with their defaults.
+func (l LocalCacheConfig) resolved() LocalCacheConfig {
+	if l.Size <= 0 {
+		l.Size = 10000
+	}
+	if l.TTL <= 0 {
+		l.TTL = 5
+	}
+	return l
+}
+
+// TTLDuration returns the local cache TTL as a time.Duration.
+func (l LocalCacheConfig) TTLDuration() time.Duration {
+	return time.Duration(l.resolved().TTL) * time.Second
+}
+
+// ResilienceConfig holds the configuration of the resilience layer
+// around the OpenFGA calls. Zero-valued fields are filled with their
+// defaults when Enabled is set.
+type ResilienceConfig struct {
+	// Enabled turns the resilience layer on.
+	Enabled bool
+	// Timeout bounds each unary OpenFGA call. Defaults to 2 seconds.
+	// StreamedListObjects is bounded by the OpenFGA list deadline instead.
+	Timeout time.Duration
+	// MaxRetries is how many times a read failing with Unavailable is
+	// retried. Defaults to 2; a negative value disables retries. Writes
+	// are never retried since they may have been applied.
+	MaxRetries int
+	// RetryBackoff is the base delay between retries, doubled on every
+	// attempt and fully jittered. Defaults to 50ms.
+	RetryBackoff time.Duration
+	// BreakerThreshold is the number of consecutive unavailable calls
+	// that opens the circuit breaker. Defaults to 5.
+	BreakerThreshold int
+	// BreakerCooldown is how long the open breaker rejects calls before
+	// letting one probe through. Defaults to 10 seconds.
+	BreakerCooldown time.Duration
+	// FailPolicy is how CheckPermission answers while OpenFGA is
+	// unavailable. Defaults to failing closed.
+	FailPolicy FailPolicy
+	// FailPolicies overrides FailPolicy per object type.
+	FailPolicies map[string]FailPolicy
+	// StaleSize bounds the number of decisions kept for FailModeStale.
+	// Defaults to 10000.
+	StaleSize int
+}
+
+// FailMode selects how CheckPermission answers while OpenFGA is
+// unavailable.
+type FailMode string
+
+const (
+	// FailModeClosed denies the check and returns an error wrapping
+	// errorsx.ErrUnavailable.
+	FailModeClosed FailMode = ""
+	// FailModeStale answers with the last decision OpenFGA returned for
+	// the same check, if it is at most MaxStaleness old, and fails closed
+	// otherwise.
+	FailModeStale FailMode = "stale"
+)
+
+// FailPolicy is the outage behaviour of the checks of an object type.
+type FailPolicy struct {
+	Mode FailMode
+	// MaxStaleness bounds the age of the decisions served by
+	// FailModeStale. Defaults to 5 minutes.
+	MaxStaleness time.Duration
+}
+
+// resolved returns a ResilienceConfig with zero-valued fields filled
+// with their defaults.
+func (r ResilienceConfig) resolved() ResilienceConfig {
+	if r.Timeout <= 0 {
+		r.Timeout = 2 * time.Second
+	}
+	switch {
+	case r.MaxRetries == 0:
+		r.MaxRetries = 2
+	case r.MaxRetries < 0:
+		r.MaxRetries = 0
+	}
+	if r.RetryBackoff <= 0 {
+		r.RetryBackoff = 50 * time.Millisecond
+	}
+	if r.BreakerThreshold <= 0 {
+		r.BreakerThreshold = 5
+	}
+	if r.BreakerCooldown <= 0 {
+		r.BreakerCooldown = 10 * time.Second
+	}
+	if r.StaleSize <= 0 {
+		r.StaleSize = 10000
+	}
+	r.FailPolicy = r.FailPolicy.resolved()
+	policies := make(map[string]FailPolicy, len(r.FailPolicies))
+	for objectType, p := range r.FailPolicies {
+		policies[objectType] = p.resolved()
+	}
+	r.FailPolicies = policies
+	return r
+}
+
+func (p FailPolicy) resolved() FailPolicy {
+	if p.MaxStaleness <= 0 {
+		p.MaxStaleness = 5 * time.Minute
+	}
+	return p
+}
+
+// failPolicy returns the outage behaviour of the checks of objectType.
+func (r ResilienceConfig) failPolicy(objectType string) FailPolicy {
+	if p, ok := r.FailPolicies[objectType]; ok {
+		return p
+	}
+	return r.FailPolicy
+}
+
+// maxStaleness returns the longest staleness of the stale fail
+// policies, or 0 when no object type serves stale decisions.
+func (r ResilienceConfig) maxStaleness() time.Duration {
+	var staleness time.Duration
+	for _, p := range append([]FailPolicy{r.FailPolicy}, slices.Collect(maps.Values(r.FailPolicies))...) {
+		if p.Mode == FailModeStale {
+			staleness = max(staleness, p.MaxStaleness)
+		}
+	}
+	return staleness
+}
+
+// ImpersonationConfig holds the relations a subject must hold on a
+// namespace to make requests on its behalf through the requester
+// headers.
+type ImpersonationConfig struct {
+	// Relation is the relation checked on the requester namespace.
+	// Defaults to "member".
+	Relation string
+	// Relations overrides Relation per namespace type, e.g.
+	// {"user": "delegate"}.
+	Relations map[string]string
+	// MaxHops bounds the number of namespaces of a requester chain.
+	// Defaults to 4.
+	MaxHops int
+}
+
+// relation returns the relation checked on requester namespaces of
+// namespaceType.
+func (i ImpersonationConfig) relation(namespaceType string) string {
+	if relation, ok := i.Relations[namespaceType]; ok {
+		return relation
+	}
+	if i.Relation == "" {
+		return string(RoleMember)
+	}
+	return i.Relation
+}
+
+// maxHops returns MaxHops or its default.
+func (i ImpersonationConfig) maxHops() int {
+	if i.MaxHops <= 0 {
+		return 4
+	}
+	return i.MaxHops
+}
@@ -0,0 +1,319 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// ParseModelDSL parses an authorization model written in the OpenFGA
+// DSL (schema 1.1). It covers what the Instill models use: direct type
+// restrictions (`[user, user:*, group#member]`), computed relations,
+// `X from Y`, and the `or`, `and` and `but not` operators with
+// parentheses. Conditions and modular models are not supported;
+// transform those to JSON with the fga CLI instead.
+func ParseModelDSL(src string) (*openfga.AuthorizationModel, error) {
+	model := &openfga.AuthorizationModel{}
+	var current *openfga.TypeDefinition
+	inRelations := false
+
+	for i, raw := range strings.Split(src, "\n") {
+		line := strings.TrimSpace(stripDSLComment(raw))
+		if line == "" {
+			continue
+		}
+		lineErr := func(format string, args ...any) error {
+			return fmt.Errorf("line %d: %s", i+1, fmt.Sprintf(format, args...))
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch keyword {
+		case "model":
+			if rest != "" {
+				return nil, lineErr("unexpected %q after model", rest)
+			}
+		case "schema":
+			if rest != "1.1" {
+				return nil, lineErr("unsupported schema version %q", rest)
+			}
+			model.SchemaVersion = rest
+		case "type":
+			if !isDSLIdentifier(rest) {
+				return nil, lineErr("invalid type name %q", rest)
+			}
+			current = &openfga.TypeDefinition{Type: rest, Relations: map[string]*openfga.Userset{}}
+			model.TypeDefinitions = append(model.TypeDefinitions, current)
+			inRelations = false
+		case "relations":
+			if current == nil {
+				return nil, lineErr("relations outside of a type")
+			}
+			inRelations = true
+		case "define":
+			if !inRelations {
+				return nil, lineErr("define outside of a relations block")
+			}
+			name, expr, ok := strings.Cut(rest, ":")
+			name = strings.TrimSpace(name)
+			if !ok || !isDSLIdentifier(name) {
+				return nil, lineErr("expected `define <relation>: <expression>`")
+			}
+			if _, dup := current.Relations[name]; dup {
+				return nil, lineErr("relation %s defined twice on type %s", name, current.Type)
+			}
+			userset, direct, err := parseDSLRelation(expr)
+			if err != nil {
+				return nil, lineErr("%v", err)
+			}
+			current.Relations[name] = userset
+			if direct != nil {
+				if current.Metadata == nil {
+					current.Metadata = &openfga.Metadata{Relations: map[string]*openfga.RelationMetadata{}}
+				}
+				current.Metadata.Relations[name] = &openfga.RelationMetadata{DirectlyRelatedUserTypes: direct}
+			}
+		case "condition", "module", "extend":
+			return nil, lineErr("%s is not supported, provide the model as JSON", keyword)
+		default:
+			return nil, lineErr("unexpected %q", keyword)
+		}
+	}
+
+	if model.SchemaVersion == "" {
+		return nil, fmt.Errorf("missing schema version")
+	}
+	if len(model.TypeDefinitions) == 0 {
+		return nil, fmt.Errorf("model defines no types")
+	}
+	return model, nil
+}
+
+// stripDSLComment removes a trailing comment. `#` also separates a
+// type from a relation (`group#member`), so it only starts a comment
+// at the beginning of a line or after whitespace.
+func stripDSLComment(line string) string {
+	for i, r := range line {
+		if r == '#' && (i == 0 || unicode.IsSpace(rune(line[i-1]))) {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func isDSLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// dslParser is a recursive-descent parser over the tokens of a
+// relation definition.
+type dslParser struct {
+	tokens  []string
+	pos     int
+	direct  []*openfga.RelationReference
+	hasThis bool
+}
+
+// parseDSLRelation parses the expression of a relation definition and
+// returns its userset along with the directly related user types, if
+// the expression has a direct assignment.
+func parseDSLRelation(expr string) (*openfga.Userset, []*openfga.RelationReference, error) {
+	tokens, err := tokenizeDSL(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &dslParser{tokens: tokens}
+	userset, err := p.parseExpr()
+	if err != nil {
+		return nil, nil, err
+	}
+	if tok := p.peek(); tok != "" {
+		return nil, nil, fmt.Errorf("unexpected %q", tok)
+	}
+	return userset, p.direct, nil
+}
+
+// tokenizeDSL splits a relation expression into brackets, parentheses
+// and words. A type restriction list is kept as a single token.
+func tokenizeDSL(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated type restriction")
+			}
+			tokens = append(tokens, expr[i:i+end+1])
+			i += end + 1
+		default:
+			j := i
+			for j < len(expr) && !strings.ContainsRune(" \t()[]", rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty relation definition")
+	}
+	return tokens, nil
+}
+
+func (p *dslParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *dslParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+// parseExpr parses terms joined by a single kind of operator. As in
+// the OpenFGA grammar, mixing operators requires parentheses and
+// `but not` takes exactly two operands.
+func (p *dslParser) parseExpr() (*openfga.Userset, error) {
+	first, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	operator := ""
+	children := []*openfga.Userset{first}
+	for {
+		op := p.peek()
+		if op == "but" {
+			p.next()
+			if p.next() != "not" {
+				return nil, fmt.Errorf("expected `but not`")
+			}
+			op = "but not"
+		} else if op == "or" || op == "and" {
+			p.next()
+		} else {
+			break
+		}
+
+		if operator != "" && (op != operator || op == "but not") {
+			return nil, fmt.Errorf("mixing %q and %q requires parentheses", operator, op)
+		}
+		operator = op
+
+		child, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	switch operator {
+	case "":
+		return first, nil
+	case "or":
+		return &openfga.Userset{Userset: &openfga.Userset_Union{Union: &openfga.Usersets{Child: children}}}, nil
+	case "and":
+		return &openfga.Userset{Userset: &openfga.Userset_Intersection{Intersection: &openfga.Usersets{Child: children}}}, nil
+	default:
+		return &openfga.Userset{Userset: &openfga.Userset_Difference{Difference: &openfga.Difference{
+			Base:     children[0],
+			Subtract: children[1],
+		}}}, nil
+	}
+}
+
+// parseTerm parses a type restriction, a parenthesized expression, a
+// computed relation or a `X from Y` tupleset rewrite.
+func (p *dslParser) parseTerm() (*openfga.Userset, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of definition")
+	case tok == "(":
+		userset, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return userset, nil
+	case strings.HasPrefix(tok, "["):
+		if p.hasThis {
+			return nil, fmt.Errorf("a relation has at most one type restriction")
+		}
+		refs, err := parseDSLTypeRestrictions(tok[1 : len(tok)-1])
+		if err != nil {
+			return nil, err
+		}
+		p.hasThis = true
+		p.direct = refs
+		return &openfga.Userset{Userset: &openfga.Userset_This{This: &openfga.DirectUserset{}}}, nil
+	case isDSLIdentifier(tok):
+		if p.peek() == "from" {
+			p.next()
+			tupleset := p.next()
+			if !isDSLIdentifier(tupleset) {
+				return nil, fmt.Errorf("expected a relation after `from`, got %q", tupleset)
+			}
+			return &openfga.Userset{Userset: &openfga.Userset_TupleToUserset{TupleToUserset: &openfga.TupleToUserset{
+				Tupleset:        &openfga.ObjectRelation{Relation: tupleset},
+				ComputedUserset: &openfga.ObjectRelation{Relation: tok},
+			}}}, nil
+		}
+		return &openfga.Userset{Userset: &openfga.Userset_ComputedUserset{ComputedUserset: &openfga.ObjectRelation{Relation: tok}}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+}
+
+// parseDSLTypeRestrictions parses the content of `[...]`.
+func parseDSLTypeRestrictions(list string) ([]*openfga.RelationReference, error) {
+	var refs []*openfga.RelationReference
+	for item := range strings.SplitSeq(list, ",") {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, " with ") {
+			return nil, fmt.Errorf("conditional type restriction %q is not supported, provide the model as JSON", item)
+		}
+
+		ref := &openfga.RelationReference{}
+		switch {
+		case strings.HasSuffix(item, ":*"):
+			ref.Type = strings.TrimSuffix(item, ":*")
+			ref.RelationOrWildcard = &openfga.RelationReference_Wildcard{Wildcard: &openfga.Wildcard{}}
+		case strings.Contains(item, "#"):
+			typ, relation, _ := strings.Cut(item, "#")
+			if !isDSLIdentifier(relation) {
+				return nil, fmt.Errorf("invalid type restriction %q", item)
+			}
+			ref.Type = typ
+			ref.RelationOrWildcard = &openfga.RelationReference_Relation{Relation: relation}
+		default:
+			ref.Type = item
+		}
+		if !isDSLIdentifier(ref.Type) {
+			return nil, fmt.Errorf("invalid type restriction %q", item)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
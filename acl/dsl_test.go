@@ -0,0 +1,155 @@
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+const testModelDSL = `model
+  schema 1.1
+
+# Subjects
+type user
+
+type organization
+  relations
+    define owner: [user]
+    define member: [user, organization#member] or owner
+
+type pipeline
+  relations
+    define owner: [user, organization] # direct owners
+    define reader: [user, user:*] or owner or member from owner
+    define banned: [user]
+    define executor: (reader and owner) but not banned
+`
+
+func computed(relation string) *openfga.Userset {
+	return &openfga.Userset{Userset: &openfga.Userset_ComputedUserset{ComputedUserset: &openfga.ObjectRelation{Relation: relation}}}
+}
+
+func direct() *openfga.Userset {
+	return &openfga.Userset{Userset: &openfga.Userset_This{This: &openfga.DirectUserset{}}}
+}
+
+// ============================================================
+// ParseModelDSL
+// ============================================================
+
+func TestParseModelDSL_Rewrites(t *testing.T) {
+	model, err := ParseModelDSL(testModelDSL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model.SchemaVersion != "1.1" || len(model.TypeDefinitions) != 3 {
+		t.Fatalf("unexpected model: %v", model)
+	}
+
+	pipeline := model.TypeDefinitions[2]
+	wantReader := &openfga.Userset{Userset: &openfga.Userset_Union{Union: &openfga.Usersets{Child: []*openfga.Userset{
+		direct(),
+		computed("owner"),
+		{Userset: &openfga.Userset_TupleToUserset{TupleToUserset: &openfga.TupleToUserset{
+			Tupleset:        &openfga.ObjectRelation{Relation: "owner"},
+			ComputedUserset: &openfga.ObjectRelation{Relation: "member"},
+		}}},
+	}}}}
+	if !proto.Equal(pipeline.Relations["reader"], wantReader) {
+		t.Errorf("unexpected reader rewrite: %v", pipeline.Relations["reader"])
+	}
+
+	wantExecutor := &openfga.Userset{Userset: &openfga.Userset_Difference{Difference: &openfga.Difference{
+		Base: &openfga.Userset{Userset: &openfga.Userset_Intersection{Intersection: &openfga.Usersets{Child: []*openfga.Userset{
+			computed("reader"), computed("owner"),
+		}}}},
+		Subtract: computed("banned"),
+	}}}
+	if !proto.Equal(pipeline.Relations["executor"], wantExecutor) {
+		t.Errorf("unexpected executor rewrite: %v", pipeline.Relations["executor"])
+	}
+}
+
+func TestParseModelDSL_DirectlyRelatedTypes(t *testing.T) {
+	model, err := ParseModelDSL(testModelDSL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	member := model.TypeDefinitions[1].Metadata.Relations["member"].DirectlyRelatedUserTypes
+	if len(member) != 2 || member[1].Type != "organization" || member[1].GetRelation() != "member" {
+		t.Errorf("unexpected member types: %v", member)
+	}
+	reader := model.TypeDefinitions[2].Metadata.Relations["reader"].DirectlyRelatedUserTypes
+	if len(reader) != 2 || reader[1].GetWildcard() == nil {
+		t.Errorf("user:* should be a wildcard reference: %v", reader)
+	}
+	if _, ok := model.TypeDefinitions[2].Metadata.Relations["executor"]; ok {
+		t.Error("relations without direct assignment have no metadata")
+	}
+}
+
+func TestParseModelDSL_Errors(t *testing.T) {
+	cases := map[string]string{
+		"mixed operators": "model\n schema 1.1\ntype doc\n relations\n  define a: [user]\n  define b: a or a and a\n",
+		"conditions":      "model\n schema 1.1\ntype doc\n relations\n  define a: [user with in_office]\n",
+		"define outside":  "model\n schema 1.1\ntype doc\n define a: [user]\n",
+		"no schema":       "model\ntype user\n",
+		"unbalanced":      "model\n schema 1.1\ntype doc\n relations\n  define a: ([user] or b\n",
+		"duplicate":       "model\n schema 1.1\ntype doc\n relations\n  define a: [user]\n  define a: [user]\n",
+	}
+	for name, src := range cases {
+		if _, err := ParseModelDSL(src); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// ============================================================
+// LoadAuthorizationModel
+// ============================================================
+
+func TestLoadAuthorizationModel_JSONMatchesDSL(t *testing.T) {
+	fromDSL, err := ParseModelDSL(testModelDSL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := protojson.Marshal(fromDSL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "model.json")
+	dslPath := filepath.Join(dir, "model.fga")
+	_ = os.WriteFile(jsonPath, data, 0o600)
+	_ = os.WriteFile(dslPath, []byte(testModelDSL), 0o600)
+
+	fromJSON, err := LoadAuthorizationModel(jsonPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromFile, err := LoadAuthorizationModel(dslPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !proto.Equal(fromJSON, fromDSL) || !proto.Equal(fromFile, fromDSL) {
+		t.Error("JSON and DSL files should load the same model")
+	}
+}
+
+func TestLoadAuthorizationModel_ReportsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.fga")
+	_ = os.WriteFile(path, []byte("type user\n"), 0o600)
+
+	_, err := LoadAuthorizationModel(path)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("error should name the file, got %v", err)
+	}
+}
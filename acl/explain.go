@@ -0,0 +1,363 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// Bounds of the Expand walk of ExplainPermission. OpenFGA resolves
+// checks up to a depth of 25; explanations stop earlier since a deeper
+// tree is unreadable anyway.
+const (
+	maxExplainDepth   = 12
+	maxExplainExpands = 200
+)
+
+// ExplainNodeKind is the kind of a node of an explanation tree.
+type ExplainNodeKind string
+
+const (
+	// ExplainUnion is a union rewrite ("a or b"): it matches if any
+	// child matches.
+	ExplainUnion ExplainNodeKind = "union"
+	// ExplainIntersection is an intersection rewrite ("a and b"): it
+	// matches if every child matches.
+	ExplainIntersection ExplainNodeKind = "intersection"
+	// ExplainExclusion is an exclusion rewrite ("a but not b"): it
+	// matches if its first child matches and its second doesn't.
+	ExplainExclusion ExplainNodeKind = "exclusion"
+	// ExplainDirect lists the subjects directly assigned to a relation,
+	// wildcards and usersets included. Usersets are expanded as
+	// children.
+	ExplainDirect ExplainNodeKind = "direct"
+	// ExplainComputed is a relation inherited from another relation of
+	// the same object ("define viewer: editor").
+	ExplainComputed ExplainNodeKind = "computed"
+	// ExplainTupleToUserset is a relation inherited from a related
+	// object ("viewer from parent").
+	ExplainTupleToUserset ExplainNodeKind = "tuple_to_userset"
+	// ExplainTruncated marks a userset that was not expanded, because
+	// of a cycle or the size bounds of the walk.
+	ExplainTruncated ExplainNodeKind = "truncated"
+)
+
+// ExplainNode is a node of the tree built by ExplainPermission.
+type ExplainNode struct {
+	Kind ExplainNodeKind
+	// Name is the userset the node belongs to, e.g.
+	// "pipeline:<uid>#reader".
+	Name string
+	// Via is the userset a computed node points to, or the tupleset of a
+	// tuple-to-userset node, e.g. "file:<uid>#parent_collection".
+	Via string
+	// Users are the subjects of a direct node. Share-link tokens and
+	// codes are redacted.
+	Users []string
+	// Matched reports whether the subject was found under the node.
+	Matched bool
+	// Error is set when the node could not be expanded.
+	Error    string
+	Children []*ExplainNode
+}
+
+// Explanation is the result of ExplainPermission.
+type Explanation struct {
+	// Subject is the FGA subject the permission was resolved for, e.g.
+	// "user:<uid>".
+	Subject  string
+	Object   string
+	Relation string
+	// Allowed is the answer of an uncached, HIGHER_CONSISTENCY Check.
+	Allowed bool
+	// OutOfScope reports that the scopes of the machine subject exclude
+	// the relation, so that CheckPermission denies it whatever Allowed
+	// says.
+	OutOfScope bool
+	// CacheHit reports whether CheckPermission would currently answer
+	// from the cache, and CachedAllowed what it would answer. A cached
+	// answer that differs from Allowed is stale.
+	CacheHit      bool
+	CachedAllowed bool
+	// Tree is the relation expanded through OpenFGA Expand, with the
+	// branches that contain the subject marked as matched.
+	Tree *ExplainNode
+}
+
+// ExplainPermission explains the answer of CheckPermission for the
+// subject of the request: it runs an uncached Check, reports whether
+// the permission cache holds an answer, and walks OpenFGA Expand from
+// the relation down through rewrites, parent relations and usersets to
+// show which grants did or did not match.
+//
+// Expand ignores conditions and the walk is bounded, so Allowed, not
+// the tree, is authoritative. Share-link tokens in the tree are
+// redacted, but the tree lists the subjects holding the relation: it is
+// meant for admin-only debugging endpoints.
+func (c *ACLClient) ExplainPermission(ctx context.Context, objectType string, objectUID uuid.UUID, relation string) (*Explanation, error) {
+	log, _ := logx.GetZapLogger(ctx)
+
+	if err := c.validate(objectType, relation); err != nil {
+		return nil, err
+	}
+
+	userType, userUID, err := resolveACLSubject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	modelID, err := c.getAuthorizationModelID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting authorization model: %w", err)
+	}
+
+	exp := &Explanation{
+		Subject:    userType + ":" + userUID,
+		Object:     fmt.Sprintf("%s:%s", objectType, objectUID.String()),
+		Relation:   relation,
+		OutOfScope: outOfScope(ctx, userType, objectType, relation),
+	}
+
+	if c.cacheEnabled && c.cache != nil {
+		cached, err := c.cache.Get(ctx, []*CacheEntry{checkCacheEntry(userType, userUID, objectType, objectUID.String(), relation)})
+		if err != nil {
+			log.Warn("ExplainPermission cache error", zap.Error(err))
+		} else if cached[0] != "" {
+			exp.CacheHit = true
+			exp.CachedAllowed = cached[0] == "1"
+		}
+	}
+
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.getClient(ctx, ReadMode).Check(ctx, &openfga.CheckRequest{
+		StoreId:              c.storeID,
+		AuthorizationModelId: modelID,
+		TupleKey: &openfga.CheckRequestTupleKey{
+			User:     exp.Subject,
+			Relation: relation,
+			Object:   exp.Object,
+		},
+		Context:     reqContext,
+		Consistency: openfga.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checking permission: %w", err)
+	}
+	exp.Allowed = data.GetAllowed()
+
+	e := &explainer{c: c, modelID: modelID, subject: exp.Subject, subjectType: userType, done: map[string]*ExplainNode{}}
+	root, err := e.expandRoot(ctx, exp.Object+"#"+relation)
+	if err != nil {
+		return nil, err
+	}
+	exp.Tree = root
+
+	return exp, nil
+}
+
+// String renders the explanation as an indented tree.
+func (e *Explanation) String() string {
+	var b strings.Builder
+	verdict := "denied"
+	if e.Allowed {
+		verdict = "allowed"
+	}
+	if e.OutOfScope {
+		verdict += ", out of scope"
+	}
+	cache := "miss"
+	if e.CacheHit {
+		cache = "denied"
+		if e.CachedAllowed {
+			cache = "allowed"
+		}
+	}
+	fmt.Fprintf(&b, "%s %s %s: %s (cache: %s)\n", e.Subject, e.Relation, e.Object, verdict, cache)
+	if e.Tree != nil {
+		e.Tree.render(&b, 0)
+	}
+	return b.String()
+}
+
+func (n *ExplainNode) render(b *strings.Builder, depth int) {
+	mark := "[ ]"
+	if n.Matched {
+		mark = "[x]"
+	}
+	fmt.Fprintf(b, "%s%s %s %s", strings.Repeat("  ", depth), mark, n.Kind, n.Name)
+	if n.Via != "" {
+		fmt.Fprintf(b, " via %s", n.Via)
+	}
+	if len(n.Users) > 0 {
+		fmt.Fprintf(b, " [%s]", strings.Join(n.Users, ", "))
+	}
+	if n.Error != "" {
+		fmt.Fprintf(b, " error: %s", n.Error)
+	}
+	b.WriteByte('\n')
+	for _, child := range n.Children {
+		child.render(b, depth+1)
+	}
+}
+
+// explainer walks OpenFGA Expand trees for one subject.
+type explainer struct {
+	c           *ACLClient
+	modelID     string
+	subject     string
+	subjectType string
+	expands     int
+	// done holds the usersets expanded so far; a nil entry marks one
+	// being expanded, i.e. a cycle.
+	done map[string]*ExplainNode
+}
+
+// expandRoot expands the checked relation; unlike nested usersets, its
+// failure fails the explanation.
+func (e *explainer) expandRoot(ctx context.Context, userset string) (*ExplainNode, error) {
+	tree, err := e.callExpand(ctx, userset)
+	if err != nil {
+		return nil, fmt.Errorf("expanding %s: %w", userset, err)
+	}
+	e.done[userset] = nil
+	root := e.node(ctx, tree.GetRoot(), 0)
+	e.done[userset] = root
+	return root, nil
+}
+
+// expand expands a nested userset such as "group:<uid>#member".
+func (e *explainer) expand(ctx context.Context, userset string, depth int) *ExplainNode {
+	if node, seen := e.done[userset]; seen {
+		if node == nil {
+			return &ExplainNode{Kind: ExplainTruncated, Name: userset, Error: "cycle"}
+		}
+		return node
+	}
+	if depth > maxExplainDepth || e.expands >= maxExplainExpands {
+		return &ExplainNode{Kind: ExplainTruncated, Name: userset, Error: "explanation size limit reached"}
+	}
+
+	tree, err := e.callExpand(ctx, userset)
+	if err != nil {
+		return &ExplainNode{Kind: ExplainTruncated, Name: userset, Error: err.Error()}
+	}
+	e.done[userset] = nil
+	node := e.node(ctx, tree.GetRoot(), depth)
+	e.done[userset] = node
+	return node
+}
+
+func (e *explainer) callExpand(ctx context.Context, userset string) (*openfga.UsersetTree, error) {
+	e.expands++
+	object, relation, _ := strings.Cut(userset, "#")
+	resp, err := e.c.getClient(ctx, ReadMode).Expand(ctx, &openfga.ExpandRequest{
+		StoreId:              e.c.storeID,
+		AuthorizationModelId: e.modelID,
+		TupleKey:             &openfga.ExpandRequestTupleKey{Object: object, Relation: relation},
+		Consistency:          openfga.ConsistencyPreference_HIGHER_CONSISTENCY,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetTree(), nil
+}
+
+// node converts an Expand node, expanding the usersets it references.
+func (e *explainer) node(ctx context.Context, n *openfga.UsersetTree_Node, depth int) *ExplainNode {
+	out := &ExplainNode{Name: n.GetName()}
+
+	switch {
+	case n.GetUnion() != nil:
+		out.Kind = ExplainUnion
+		for _, child := range n.GetUnion().GetNodes() {
+			node := e.node(ctx, child, depth)
+			out.Children = append(out.Children, node)
+			out.Matched = out.Matched || node.Matched
+		}
+	case n.GetIntersection() != nil:
+		out.Kind = ExplainIntersection
+		out.Matched = len(n.GetIntersection().GetNodes()) > 0
+		for _, child := range n.GetIntersection().GetNodes() {
+			node := e.node(ctx, child, depth)
+			out.Children = append(out.Children, node)
+			out.Matched = out.Matched && node.Matched
+		}
+	case n.GetDifference() != nil:
+		out.Kind = ExplainExclusion
+		base := e.node(ctx, n.GetDifference().GetBase(), depth)
+		subtract := e.node(ctx, n.GetDifference().GetSubtract(), depth)
+		out.Children = []*ExplainNode{base, subtract}
+		out.Matched = base.Matched && !subtract.Matched
+	case n.GetLeaf().GetUsers() != nil:
+		out.Kind = ExplainDirect
+		for _, user := range n.GetLeaf().GetUsers().GetUsers() {
+			out.Users = append(out.Users, redactSubject(user))
+			switch {
+			case user == e.subject || user == e.subjectType+":*":
+				out.Matched = true
+			case strings.Contains(user, "#"):
+				node := e.expand(ctx, user, depth+1)
+				out.Children = append(out.Children, node)
+				out.Matched = out.Matched || node.Matched
+			}
+		}
+	case n.GetLeaf().GetComputed() != nil:
+		out.Kind = ExplainComputed
+		out.Via = n.GetLeaf().GetComputed().GetUserset()
+		node := e.expand(ctx, out.Via, depth+1)
+		out.Children = []*ExplainNode{node}
+		out.Matched = node.Matched
+	case n.GetLeaf().GetTupleToUserset() != nil:
+		ttu := n.GetLeaf().GetTupleToUserset()
+		out.Kind = ExplainTupleToUserset
+		out.Via = ttu.GetTupleset()
+		for _, computed := range ttu.GetComputed() {
+			node := e.expand(ctx, computed.GetUserset(), depth+1)
+			out.Children = append(out.Children, node)
+			out.Matched = out.Matched || node.Matched
+		}
+	default:
+		out.Kind = ExplainDirect
+	}
+
+	return out
+}
+
+// redactSubject hides the secret part of share-link and link-code
+// subjects, keeping a short prefix to tell them apart.
+func redactSubject(user string) string {
+	typ, id, ok := strings.Cut(user, ":")
+	if !ok || (typ != shareLinkType && typ != "code") || id == "*" {
+		return user
+	}
+	id, relation, _ := strings.Cut(id, "#")
+	if len(id) > 4 {
+		id = id[:4]
+	}
+	redacted := typ + ":" + id + "…"
+	if relation != "" {
+		redacted += "#" + relation
+	}
+	return redacted
+}
+
+// secretSubjectPattern matches the share-link and link-code subjects
+// embedded in free text, such as OpenFGA error messages.
+var secretSubjectPattern = regexp.MustCompile(`\b(?:` + shareLinkType + `|code):[^\s#'",;\])]+(?:#[A-Za-z0-9_]+)?`)
+
+// redactSubjects applies redactSubject to every share-link and link-code
+// subject found in s.
+func redactSubjects(s string) string {
+	return secretSubjectPattern.ReplaceAllStringFunc(s, redactSubject)
+}
@@ -0,0 +1,155 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// expandTrees returns an Expand mock answering with the tree registered
+// for the request's "object#relation".
+func expandTrees(trees map[string]*openfga.UsersetTree_Node) func(context.Context, *openfga.ExpandRequest) (*openfga.ExpandResponse, error) {
+	return func(_ context.Context, req *openfga.ExpandRequest) (*openfga.ExpandResponse, error) {
+		key := req.TupleKey.Object + "#" + req.TupleKey.Relation
+		root, ok := trees[key]
+		if !ok {
+			return nil, fmt.Errorf("no tree for %s", key)
+		}
+		return &openfga.ExpandResponse{Tree: &openfga.UsersetTree{Root: root}}, nil
+	}
+}
+
+func usersLeaf(name string, users ...string) *openfga.UsersetTree_Node {
+	return &openfga.UsersetTree_Node{Name: name, Value: &openfga.UsersetTree_Node_Leaf{Leaf: &openfga.UsersetTree_Leaf{
+		Value: &openfga.UsersetTree_Leaf_Users{Users: &openfga.UsersetTree_Users{Users: users}},
+	}}}
+}
+
+func computedLeaf(name, userset string) *openfga.UsersetTree_Node {
+	return &openfga.UsersetTree_Node{Name: name, Value: &openfga.UsersetTree_Node_Leaf{Leaf: &openfga.UsersetTree_Leaf{
+		Value: &openfga.UsersetTree_Leaf_Computed{Computed: &openfga.UsersetTree_Computed{Userset: userset}},
+	}}}
+}
+
+func unionNode(name string, nodes ...*openfga.UsersetTree_Node) *openfga.UsersetTree_Node {
+	return &openfga.UsersetTree_Node{Name: name, Value: &openfga.UsersetTree_Node_Union{Union: &openfga.UsersetTree_Nodes{Nodes: nodes}}}
+}
+
+// explainModel models "define reader: [user, share_link, organization#member] or writer"
+// on pipeline, with the caller a member of the organization.
+func explainModel() map[string]*openfga.UsersetTree_Node {
+	pipeline := "pipeline:" + testObjectUID.String()
+	org := "organization:" + testOrgUID
+	return map[string]*openfga.UsersetTree_Node{
+		pipeline + "#reader": unionNode(pipeline+"#reader",
+			usersLeaf(pipeline+"#reader", "user:someone-else", "share_link:secret-token", org+"#member"),
+			computedLeaf(pipeline+"#reader", pipeline+"#writer"),
+		),
+		pipeline + "#writer": usersLeaf(pipeline + "#writer"),
+		org + "#member":      usersLeaf(org+"#member", "user:"+testUserUID),
+	}
+}
+
+// ============================================================
+// ExplainPermission — Expand walk
+// ============================================================
+
+func TestExplainPermission_MarksMatchingBranch(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		expandFn: expandTrees(explainModel()),
+		checkFn: func(_ context.Context, req *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			if req.Consistency != openfga.ConsistencyPreference_HIGHER_CONSISTENCY {
+				t.Errorf("explanation should check with HIGHER_CONSISTENCY, got %s", req.Consistency)
+			}
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	})
+
+	exp, err := c.ExplainPermission(userCtx(testUserUID), "pipeline", testObjectUID, "reader")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exp.Allowed || exp.CacheHit || exp.Subject != "user:"+testUserUID {
+		t.Errorf("unexpected explanation %+v", exp)
+	}
+
+	root := exp.Tree
+	if root.Kind != ExplainUnion || !root.Matched || len(root.Children) != 2 {
+		t.Fatalf("expected a matched union, got %+v", root)
+	}
+	direct, computed := root.Children[0], root.Children[1]
+	if !direct.Matched || len(direct.Children) != 1 || !direct.Children[0].Matched {
+		t.Errorf("the organization userset should be the matching branch, got %+v", direct)
+	}
+	if computed.Kind != ExplainComputed || computed.Matched || computed.Via != "pipeline:"+testObjectUID.String()+"#writer" {
+		t.Errorf("the writer rewrite should not match, got %+v", computed)
+	}
+}
+
+func TestExplainPermission_RedactsShareLinkTokens(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		expandFn: expandTrees(explainModel()),
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return &openfga.CheckResponse{}, nil
+		},
+	})
+
+	exp, err := c.ExplainPermission(userCtx(testUserUID), "pipeline", testObjectUID, "reader")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rendered := exp.String()
+	if strings.Contains(rendered, "secret-token") || !strings.Contains(rendered, "share_link:secr…") {
+		t.Errorf("share-link tokens should be redacted:\n%s", rendered)
+	}
+	if !strings.HasPrefix(rendered, "user:"+testUserUID+" reader pipeline:"+testObjectUID.String()+": denied (cache: miss)") {
+		t.Errorf("unexpected header:\n%s", rendered)
+	}
+}
+
+func TestExplainPermission_ReportsCachedAnswer(t *testing.T) {
+	c, mr := newTestClientWithCache(&mockFGA{
+		expandFn: expandTrees(explainModel()),
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	})
+	defer mr.Close()
+	ctx := userCtx(testUserUID)
+
+	if _, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp, err := c.ExplainPermission(ctx, "pipeline", testObjectUID, "reader")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exp.CacheHit || !exp.CachedAllowed {
+		t.Errorf("the cached answer should be reported, got %+v", exp)
+	}
+}
+
+func TestExplainPermission_StopsOnCycles(t *testing.T) {
+	a, b := "group:a#member", "group:b#member"
+	c := newTestClient(&mockFGA{
+		expandFn: expandTrees(map[string]*openfga.UsersetTree_Node{
+			"pipeline:" + testObjectUID.String() + "#reader": usersLeaf("pipeline#reader", a),
+			a: usersLeaf(a, b),
+			b: usersLeaf(b, a),
+		}),
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return &openfga.CheckResponse{}, nil
+		},
+	})
+
+	exp, err := c.ExplainPermission(userCtx(testUserUID), "pipeline", testObjectUID, "reader")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.String(), "truncated group:a#member error: cycle") {
+		t.Errorf("the cycle should be cut:\n%s", exp)
+	}
+}
@@ -0,0 +1,86 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/constant"
+	"github.com/instill-ai/x/resource"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// namespace is a hop of a requester chain.
+type namespace struct {
+	Type string
+	UID  uuid.UUID
+}
+
+func (n namespace) String() string {
+	return n.Type + ":" + n.UID.String()
+}
+
+// requesterChain returns the namespaces the request is made on behalf
+// of, from the one the authenticated subject acts as first to the final
+// requester. It reads Instill-Requester-Chain or, without it, the
+// single hop of Instill-Requester-Uid and Instill-Requester-Type.
+func requesterChain(ctx context.Context) ([]namespace, error) {
+	if chain := resource.GetRequestSingleHeader(ctx, constant.HeaderRequesterChainKey); chain != "" {
+		var hops []namespace
+		for _, hop := range strings.Split(chain, ",") {
+			hop = strings.TrimSpace(hop)
+			namespaceType, uid, _ := strings.Cut(hop, ":")
+			namespaceUID, err := uuid.FromString(uid)
+			if namespaceType == "" || err != nil {
+				return nil, fmt.Errorf("%w: invalid requester %q", errorsx.ErrInvalidArgument, hop)
+			}
+			hops = append(hops, namespace{Type: namespaceType, UID: namespaceUID})
+		}
+		return hops, nil
+	}
+
+	requester := resource.GetRequestSingleHeader(ctx, constant.HeaderRequesterUIDKey)
+	if requester == "" {
+		return nil, nil
+	}
+	namespaceType := resource.GetRequestSingleHeader(ctx, constant.HeaderRequesterTypeKey)
+	if namespaceType == "" {
+		namespaceType = string(OwnerTypeOrganization)
+	}
+	return []namespace{{Type: namespaceType, UID: uuid.FromStringOrNil(requester)}}, nil
+}
+
+// checkSubject checks whether subject, e.g. "organization:<uid>", holds
+// relation on the object. Unlike CheckPermission, the subject isn't the
+// one of the request, so the result isn't cached.
+func (c *ACLClient) checkSubject(ctx context.Context, subject, objectType string, objectUID uuid.UUID, relation string) (bool, error) {
+	modelID, err := c.getAuthorizationModelID(ctx)
+	if err != nil {
+		return false, fmt.Errorf("getting authorization model: %w", err)
+	}
+
+	data, err := c.getClient(ctx, ReadMode).Check(ctx, &openfga.CheckRequest{
+		StoreId:              c.storeID,
+		AuthorizationModelId: modelID,
+		TupleKey: &openfga.CheckRequestTupleKey{
+			User:     subject,
+			Relation: relation,
+			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
+		},
+	})
+	if err != nil {
+		// A namespace type the model doesn't define holds nothing.
+		if status.Code(err) == codes.Code(openfga.ErrorCode_type_not_found) {
+			return false, nil
+		}
+		return false, err
+	}
+	return data.Allowed, nil
+}
@@ -0,0 +1,208 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/constant"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+const testServiceAccountUID = "f3a4b5c6-d7e8-49f0-a1b2-c3d4e5f6a7b8"
+
+func serviceAccountCtx(scopes string, extra map[string]string) context.Context {
+	headers := map[string]string{
+		constant.HeaderAuthTypeKey:          SubjectTypeServiceAccount,
+		constant.HeaderServiceAccountUIDKey: testServiceAccountUID,
+		constant.HeaderScopesKey:            scopes,
+	}
+	for k, v := range extra {
+		headers[k] = v
+	}
+	return ctxWithHeaders(headers)
+}
+
+// grantedChecks answers Check requests from a set of "user relation
+// object" keys and records them.
+func grantedChecks(checks *[]string, granted ...string) func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+	return func(_ context.Context, req *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+		key := req.TupleKey.User + " " + req.TupleKey.Relation + " " + req.TupleKey.Object
+		*checks = append(*checks, key)
+		return &openfga.CheckResponse{Allowed: slices.Contains(granted, key)}, nil
+	}
+}
+
+// ============================================================
+// Service accounts and scopes
+// ============================================================
+
+func TestResolveACLSubject_ServiceAccount(t *testing.T) {
+	userType, userUID, err := resolveACLSubject(serviceAccountCtx("*", nil))
+	if err != nil || userType != SubjectTypeServiceAccount || userUID != testServiceAccountUID {
+		t.Errorf("unexpected subject %s:%s, %v", userType, userUID, err)
+	}
+
+	_, _, err = resolveACLSubject(ctxWithHeaders(map[string]string{constant.HeaderAuthTypeKey: SubjectTypeAPIToken}))
+	if !errors.Is(err, errorsx.ErrUnauthenticated) {
+		t.Errorf("an API token without UID should be unauthenticated, got %v", err)
+	}
+}
+
+func TestScopeAllows(t *testing.T) {
+	testcases := []struct {
+		scopes  []string
+		allowed bool
+	}{
+		{scopes: []string{"pipeline:reader"}, allowed: true},
+		{scopes: []string{"pipeline:*"}, allowed: true},
+		{scopes: []string{"*:reader"}, allowed: true},
+		{scopes: []string{"*"}, allowed: true},
+		{scopes: []string{"model:reader", "pipeline:writer"}},
+		{scopes: []string{"pipeline"}},
+		{},
+	}
+	for _, tc := range testcases {
+		if got := scopeAllows(tc.scopes, "pipeline", "reader"); got != tc.allowed {
+			t.Errorf("scopeAllows(%v) = %v, want %v", tc.scopes, got, tc.allowed)
+		}
+	}
+}
+
+func TestCheckPermission_ServiceAccountScopes(t *testing.T) {
+	var checks []string
+	c := newTestClient(&mockFGA{
+		checkFn: grantedChecks(&checks, "serviceaccount:"+testServiceAccountUID+" reader pipeline:"+testObjectUID.String()),
+	})
+
+	ctx := serviceAccountCtx("pipeline:reader", nil)
+	if allowed, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil || !allowed {
+		t.Fatalf("expected the service account to be allowed, got %v, %v", allowed, err)
+	}
+
+	ctx = serviceAccountCtx("model:*", nil)
+	if allowed, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil || allowed {
+		t.Fatalf("expected an out-of-scope check to be denied, got %v, %v", allowed, err)
+	}
+	if len(checks) != 1 {
+		t.Errorf("an out-of-scope check should not reach OpenFGA, got %v", checks)
+	}
+}
+
+func TestBatchCheckPermission_ServiceAccountScopes(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			if len(req.Checks) != 1 || req.Checks[0].TupleKey.Relation != "reader" {
+				t.Errorf("expected only the in-scope item, got %v", req.Checks)
+			}
+			result := map[string]*openfga.BatchCheckSingleResult{}
+			for _, check := range req.Checks {
+				result[check.CorrelationId] = &openfga.BatchCheckSingleResult{CheckResult: &openfga.BatchCheckSingleResult_Allowed{Allowed: true}}
+			}
+			return &openfga.BatchCheckResponse{Result: result}, nil
+		},
+	})
+
+	reader := CheckItem{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "reader"}
+	writer := CheckItem{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "writer"}
+	results, err := c.BatchCheckPermission(serviceAccountCtx("pipeline:reader", nil), []CheckItem{reader, writer})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !results[reader] || results[writer] {
+		t.Errorf("unexpected results %v", results)
+	}
+}
+
+// ============================================================
+// Requester impersonation
+// ============================================================
+
+func TestCheckRequesterPermission_ServiceAccountActsForNamespace(t *testing.T) {
+	var checks []string
+	c := newTestClient(&mockFGA{
+		checkFn: grantedChecks(&checks, "serviceaccount:"+testServiceAccountUID+" member organization:"+testOrgUID),
+	})
+
+	ctx := serviceAccountCtx("organization:member", map[string]string{constant.HeaderRequesterUIDKey: testOrgUID})
+	if err := c.CheckRequesterPermission(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Without the scope, the service account can't act as the organization.
+	ctx = serviceAccountCtx("pipeline:*", map[string]string{constant.HeaderRequesterUIDKey: testOrgUID})
+	if err := c.CheckRequesterPermission(ctx); !errors.Is(err, errorsx.ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+}
+
+func TestCheckRequesterPermission_NamespaceType(t *testing.T) {
+	var checks []string
+	c := newTestClient(&mockFGA{checkFn: grantedChecks(&checks)})
+	c.config.Impersonation.Relations = map[string]string{"user": "delegate"}
+
+	ctx := ctxWithHeaders(map[string]string{
+		constant.HeaderAuthTypeKey:      "user",
+		constant.HeaderUserUIDKey:       testUserUID,
+		constant.HeaderRequesterUIDKey:  testVisitorUID,
+		constant.HeaderRequesterTypeKey: "user",
+	})
+	if err := c.CheckRequesterPermission(ctx); !errors.Is(err, errorsx.ErrPermissionDenied) {
+		t.Fatalf("expected ErrPermissionDenied, got %v", err)
+	}
+	if want := []string{"user:" + testUserUID + " delegate user:" + testVisitorUID}; !slices.Equal(checks, want) {
+		t.Errorf("expected %v, got %v", want, checks)
+	}
+}
+
+func TestCheckRequesterPermission_MultiHop(t *testing.T) {
+	groupUID := uuid.Must(uuid.NewV4())
+	chain := "organization:" + testOrgUID + ", group:" + groupUID.String()
+
+	var checks []string
+	c := newTestClient(&mockFGA{
+		checkFn: grantedChecks(&checks,
+			"user:"+testUserUID+" member organization:"+testOrgUID,
+			"organization:"+testOrgUID+" member group:"+groupUID.String(),
+		),
+	})
+	ctx := ctxWithHeaders(map[string]string{
+		constant.HeaderAuthTypeKey:       "user",
+		constant.HeaderUserUIDKey:        testUserUID,
+		constant.HeaderRequesterChainKey: chain,
+	})
+	if err := c.CheckRequesterPermission(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(checks) != 2 {
+		t.Errorf("expected a check per hop, got %v", checks)
+	}
+
+	// A hop the previous namespace can't act for breaks the chain.
+	checks = nil
+	c.readClient = &mockFGA{checkFn: grantedChecks(&checks, "user:"+testUserUID+" member organization:"+testOrgUID)}
+	c.writeClient = c.readClient
+	if err := c.CheckRequesterPermission(ctx); !errors.Is(err, errorsx.ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+
+	c.config.Impersonation.MaxHops = 1
+	if err := c.CheckRequesterPermission(ctx); !errors.Is(err, errorsx.ErrPermissionDenied) {
+		t.Errorf("expected a chain over MaxHops to be denied, got %v", err)
+	}
+
+	bad := ctxWithHeaders(map[string]string{
+		constant.HeaderAuthTypeKey:       "user",
+		constant.HeaderUserUIDKey:        testUserUID,
+		constant.HeaderRequesterChainKey: "organization",
+	})
+	if err := c.CheckRequesterPermission(bad); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("expected an invalid argument, got %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package acl
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/paginate"
+
+	errorsx "github.com/instill-ai/x/errors"
+	logx "github.com/instill-ai/x/log"
+)
+
+// DefaultListPermissionsPageSize is the page size used by
+// ListPermissionsPage when the caller passes a non-positive one.
+const DefaultListPermissionsPageSize int32 = 100
+
+// MaxListPermissionsPageSize caps the page size of ListPermissionsPage.
+const MaxListPermissionsPageSize int32 = 1000
+
+// ListPermissionsIter streams the objects of a type that the current
+// user has a role for, as ListPermissions does, without materialising
+// the result. Iteration stops at the first error, which is yielded with
+// uuid.Nil; breaking out of the loop closes the underlying stream.
+//
+// Cached results are served from the cache, but results streamed from
+// OpenFGA are not written back: caching requires the full result set,
+// which is what the iterator avoids holding.
+//
+// A stream that looks truncated is handled as in ListPermissions: with
+// TruncationStrategyReadTuples the direct grants missed by the stream
+// are yielded after it, followed by an error wrapping
+// ErrPossiblyTruncated if the fallback can't vouch for the result. The
+// streamed objects are then held until the end of the stream, which
+// the fallback needs to skip them.
+func (c *ACLClient) ListPermissionsIter(ctx context.Context, objectType string, role string) iter.Seq2[uuid.UUID, error] {
+	return c.listPermissionsIter(ctx, objectType, role, false)
+}
+
+// listPermissionsIter implements ListPermissionsIter. When strict is
+// set, a truncated stream the fallback didn't recover ends with an
+// error wrapping ErrPossiblyTruncated whatever the TruncationStrategy.
+func (c *ACLClient) listPermissionsIter(ctx context.Context, objectType, role string, strict bool) iter.Seq2[uuid.UUID, error] {
+	return func(yield func(uuid.UUID, error) bool) {
+		userType, userUIDStr, err := resolveACLSubject(ctx)
+		if err != nil {
+			yield(uuid.Nil, err)
+			return
+		}
+		if outOfScope(ctx, userType, objectType, role) {
+			return
+		}
+		c.iterObjectsForSubject(ctx, objectType, role, userType, userUIDStr, strict)(yield)
+	}
+}
+
+// ListPermissionsPage returns one page of the objects of a type that
+// the current user has a role for, in ascending UUID order, and the
+// token of the next page ("" on the last page). Pass "" as pageToken
+// for the first page.
+//
+// OpenFGA streams objects in no particular order, so every page walks
+// the full stream, keeping only the pageSize smallest UUIDs past the
+// cursor in memory. Page tokens are built with paginate.EncodeToken
+// from the zero time and the last UUID of the page, so callers merging
+// ACL results with database pagination can decode them with
+// paginate.DecodeToken.
+//
+// Since every page walks the stream again, a truncated walk would skip
+// objects without the caller noticing, so a stream that looks truncated
+// fails the call with an error wrapping ErrPossiblyTruncated unless
+// TruncationStrategyReadTuples recovered it.
+func (c *ACLClient) ListPermissionsPage(ctx context.Context, objectType string, role string, pageSize int32, pageToken string) ([]uuid.UUID, string, error) {
+	switch {
+	case pageSize <= 0:
+		pageSize = DefaultListPermissionsPageSize
+	case pageSize > MaxListPermissionsPageSize:
+		pageSize = MaxListPermissionsPageSize
+	}
+
+	var after uuid.UUID
+	if pageToken != "" {
+		_, uid, err := paginate.DecodeToken(pageToken)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: invalid page token: %v", errorsx.ErrInvalidArgument, err)
+		}
+		if after, err = uuid.FromString(uid); err != nil {
+			return nil, "", fmt.Errorf("%w: invalid page token: %v", errorsx.ErrInvalidArgument, err)
+		}
+	}
+
+	// Keep the pageSize+1 smallest UUIDs after the cursor; the extra
+	// one tells whether another page follows.
+	page := &uuidMaxHeap{}
+	inPage := map[uuid.UUID]struct{}{}
+	for uid, err := range c.listPermissionsIter(ctx, objectType, role, true) {
+		if err != nil {
+			return nil, "", err
+		}
+		if _, dup := inPage[uid]; dup {
+			continue
+		}
+		if pageToken != "" && bytes.Compare(uid.Bytes(), after.Bytes()) <= 0 {
+			continue
+		}
+		switch {
+		case page.Len() <= int(pageSize):
+			heap.Push(page, uid)
+		case bytes.Compare(uid.Bytes(), (*page)[0].Bytes()) < 0:
+			delete(inPage, (*page)[0])
+			(*page)[0] = uid
+			heap.Fix(page, 0)
+		default:
+			continue
+		}
+		inPage[uid] = struct{}{}
+	}
+
+	uids := slices.SortedFunc(slices.Values(*page), func(a, b uuid.UUID) int {
+		return bytes.Compare(a.Bytes(), b.Bytes())
+	})
+	if len(uids) <= int(pageSize) {
+		return uids, "", nil
+	}
+	uids = uids[:pageSize]
+	return uids, paginate.EncodeToken(time.Time{}, uids[len(uids)-1].String()), nil
+}
+
+// iterObjectsForSubject is the streaming counterpart of
+// listObjectsForSubject. See listPermissionsIter for strict.
+func (c *ACLClient) iterObjectsForSubject(ctx context.Context, objectType, role, userType, userUIDStr string, strict bool) iter.Seq2[uuid.UUID, error] {
+	return func(yield func(uuid.UUID, error) bool) {
+		if err := c.validate(objectType, role); err != nil {
+			yield(uuid.Nil, err)
+			return
+		}
+		modelID, err := c.getAuthorizationModelID(ctx)
+		if err != nil {
+			yield(uuid.Nil, fmt.Errorf("getting authorization model: %w", err))
+			return
+		}
+
+		consistency, forceConsistency := c.listConsistency(ctx)
+		if c.listPermissionsCacheOn && c.cache != nil && !forceConsistency && !hasCheckContext(ctx) {
+			entries := []*CacheEntry{listCacheEntry(userType, userUIDStr, objectType, role)}
+			if uids, ok := c.readListCache(ctx, entries); ok {
+				for _, uid := range uids {
+					if !yield(uid, nil) {
+						return
+					}
+				}
+				return
+			}
+		}
+
+		// Cancelling the context closes the stream when the caller
+		// stops iterating early.
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.openListStream(ctx, modelID, objectType, role, userType, userUIDStr, consistency)
+		if err != nil {
+			yield(uuid.Nil, err)
+			return
+		}
+		if stream == nil {
+			return
+		}
+
+		// The truncation heuristic times the stream, so the time spent
+		// in the caller's loop body is left out.
+		startedAt := time.Now()
+		var inCaller time.Duration
+		readTuples := c.listObjectsCfg.TruncationStrategy == TruncationStrategyReadTuples
+		var streamed []uuid.UUID
+		returned := 0
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				yield(uuid.Nil, fmt.Errorf("receiving from stream: %w", err))
+				return
+			}
+			uid := objectUIDFromFGA(resp.GetObject())
+			returned++
+			if readTuples {
+				streamed = append(streamed, uid)
+			}
+			yieldedAt := time.Now()
+			if !yield(uid, nil) {
+				return
+			}
+			inCaller += time.Since(yieldedAt)
+		}
+
+		elapsed := time.Since(startedAt) - inCaller
+		if !c.detectTruncation(ctx, objectType, role, userType, userUIDStr, elapsed, returned) {
+			return
+		}
+		if !readTuples {
+			if strict {
+				yield(uuid.Nil, fmt.Errorf("listing %s %s objects: %w", objectType, role, ErrPossiblyTruncated))
+			}
+			return
+		}
+		subject := fmt.Sprintf("%s:%s", userType, userUIDStr)
+		recovered, err := c.recoverTruncatedList(ctx, objectType, role, subject, streamed)
+		// The fallback appends the grants the stream missed.
+		for _, uid := range recovered[len(streamed):] {
+			if !yield(uid, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(uuid.Nil, fmt.Errorf("listing %s %s objects: %w", objectType, role, err))
+		}
+	}
+}
+
+// listConsistency returns the consistency preference of a list call
+// and whether it was raised to HIGHER_CONSISTENCY, in which case the
+// cache must be bypassed.
+func (c *ACLClient) listConsistency(ctx context.Context) (openfga.ConsistencyPreference, bool) {
+	// Use HIGHER_CONSISTENCY when the caller forced it via context (object-level
+	// visibility change) or when the user is pinned (per-user read-after-write).
+	if forceHC, ok := ctx.Value(ContextKeyForceHigherConsistency).(bool); ok && forceHC {
+		return openfga.ConsistencyPreference_HIGHER_CONSISTENCY, true
+	}
+	if c.IsUserPinned(ctx) {
+		metrics.recordPinnedRead(ctx, "list")
+		return openfga.ConsistencyPreference_HIGHER_CONSISTENCY, true
+	}
+	return openfga.ConsistencyPreference_MINIMIZE_LATENCY, false
+}
+
+// readListCache returns the cached result of a list call, if any.
+// Cache errors are logged and reported as a miss.
+func (c *ACLClient) readListCache(ctx context.Context, entries []*CacheEntry) ([]uuid.UUID, bool) {
+	log, _ := logx.GetZapLogger(ctx)
+
+	cached, err := c.cache.Get(ctx, entries)
+	if err != nil {
+		log.Warn("ListPermissions cache read error", zap.Error(err))
+		return nil, false
+	}
+	if cached[0] == "" {
+		return nil, false
+	}
+
+	var uidStrs []string
+	if err := json.Unmarshal([]byte(cached[0]), &uidStrs); err != nil {
+		log.Warn("ListPermissions cache unmarshal error, falling through to FGA", zap.Error(err))
+		return nil, false
+	}
+	uids := make([]uuid.UUID, 0, len(uidStrs))
+	for _, s := range uidStrs {
+		uids = append(uids, uuid.FromStringOrNil(s))
+	}
+	log.Debug("ListPermissions cache hit",
+		zap.String("cacheKey", entries[0].Key),
+		zap.Int("count", len(uids)),
+	)
+	return uids, true
+}
+
+// openListStream starts a StreamedListObjects call for the given
+// subject. It returns a nil stream when the object type is not part of
+// the authorization model.
+func (c *ACLClient) openListStream(ctx context.Context, modelID, objectType, role, userType, userUIDStr string, consistency openfga.ConsistencyPreference) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.getClient(ctx, ReadMode).StreamedListObjects(ctx, &openfga.StreamedListObjectsRequest{
+		StoreId:              c.storeID,
+		AuthorizationModelId: modelID,
+		User:                 fmt.Sprintf("%s:%s", userType, userUIDStr),
+		Relation:             role,
+		Type:                 objectType,
+		Context:              reqContext,
+		Consistency:          consistency,
+	})
+	if err != nil {
+		if statusErr, ok := status.FromError(err); ok {
+			if statusErr.Code() == codes.Code(openfga.ErrorCode_type_not_found) {
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("starting streamed list objects: %w", err)
+	}
+	return stream, nil
+}
+
+// objectUIDFromFGA extracts the UID of an FGA object string such as
+// "pipeline:<uid>".
+func objectUIDFromFGA(object string) uuid.UUID {
+	_, uid, _ := strings.Cut(object, ":")
+	return uuid.FromStringOrNil(uid)
+}
+
+// uuidMaxHeap is a max-heap of UUIDs in byte order, used to keep the
+// smallest UUIDs of a stream.
+type uuidMaxHeap []uuid.UUID
+
+func (h uuidMaxHeap) Len() int           { return len(h) }
+func (h uuidMaxHeap) Less(i, j int) bool { return bytes.Compare(h[i].Bytes(), h[j].Bytes()) > 0 }
+func (h uuidMaxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *uuidMaxHeap) Push(x any)        { *h = append(*h, x.(uuid.UUID)) }
+func (h *uuidMaxHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,197 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// objectStream returns a StreamedListObjects mock that streams objects
+// of the given UIDs, and counts calls in *calls when it is not nil.
+func objectStream(objectType string, calls *int, uids ...uuid.UUID) func(context.Context, *openfga.StreamedListObjectsRequest) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+	return func(context.Context, *openfga.StreamedListObjectsRequest) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+		if calls != nil {
+			*calls++
+		}
+		items := make([]*openfga.StreamedListObjectsResponse, 0, len(uids))
+		for _, uid := range uids {
+			items = append(items, &openfga.StreamedListObjectsResponse{Object: fmt.Sprintf("%s:%s", objectType, uid)})
+		}
+		return &mockStream{items: items}, nil
+	}
+}
+
+func testUIDs(n int) []uuid.UUID {
+	uids := make([]uuid.UUID, n)
+	for i := range uids {
+		uids[i] = uuid.Must(uuid.NewV4())
+	}
+	return uids
+}
+
+// ============================================================
+// ListPermissionsIter
+// ============================================================
+
+func TestListPermissionsIter_StreamsObjects(t *testing.T) {
+	uids := testUIDs(3)
+	c := newTestClient(&mockFGA{streamedListObjectsFn: objectStream("pipeline", nil, uids...)})
+
+	var got []uuid.UUID
+	for uid, err := range c.ListPermissionsIter(userCtx(testUserUID), "pipeline", "reader") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, uid)
+	}
+	if !slices.Equal(got, uids) {
+		t.Errorf("expected %v, got %v", uids, got)
+	}
+}
+
+func TestListPermissionsIter_StopsOnBreak(t *testing.T) {
+	stream := &mockStream{items: []*openfga.StreamedListObjectsResponse{
+		{Object: "pipeline:" + testObjectUID.String()},
+		{Object: "pipeline:" + testObjectUID.String()},
+	}}
+	c := newTestClient(&mockFGA{
+		streamedListObjectsFn: func(context.Context, *openfga.StreamedListObjectsRequest) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+			return stream, nil
+		},
+	})
+
+	for range c.ListPermissionsIter(userCtx(testUserUID), "pipeline", "reader") {
+		break
+	}
+	if stream.pos != 1 {
+		t.Errorf("iteration should stop reading after break, read %d", stream.pos)
+	}
+}
+
+func TestListPermissionsIter_YieldsStreamError(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		streamedListObjectsFn: func(context.Context, *openfga.StreamedListObjectsRequest) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+			return &mockErrorStream{
+				items:    []*openfga.StreamedListObjectsResponse{{Object: "pipeline:" + testObjectUID.String()}},
+				errAfter: 1,
+			}, nil
+		},
+	})
+
+	var got []uuid.UUID
+	var lastErr error
+	for uid, err := range c.ListPermissionsIter(userCtx(testUserUID), "pipeline", "reader") {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		got = append(got, uid)
+	}
+	if len(got) != 1 || lastErr == nil {
+		t.Errorf("expected one object then an error, got %v / %v", got, lastErr)
+	}
+}
+
+func TestListPermissionsIter_ServesCachedResult(t *testing.T) {
+	uids := testUIDs(2)
+	var calls int
+	c, mr := newTestClientWithCache(&mockFGA{streamedListObjectsFn: objectStream("pipeline", &calls, uids...)})
+	defer mr.Close()
+	ctx := userCtx(testUserUID)
+
+	if _, err := c.ListPermissions(ctx, "pipeline", "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []uuid.UUID
+	for uid, err := range c.ListPermissionsIter(ctx, "pipeline", "reader") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, uid)
+	}
+	if calls != 1 || !slices.Equal(got, uids) {
+		t.Errorf("iterator should be served from the cache, calls=%d got=%v", calls, got)
+	}
+}
+
+func TestListPermissionsIter_RequiresSubject(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+	var yielded int
+	for _, err := range c.ListPermissionsIter(context.Background(), "pipeline", "reader") {
+		yielded++
+		if !errors.Is(err, errorsx.ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	}
+	if yielded != 1 {
+		t.Errorf("expected a single error, got %d values", yielded)
+	}
+}
+
+// ============================================================
+// ListPermissionsPage
+// ============================================================
+
+func TestListPermissionsPage_WalksAllPagesInOrder(t *testing.T) {
+	uids := testUIDs(7)
+	// Duplicates in the stream must not be returned twice.
+	stream := append(slices.Clone(uids), uids[0], uids[3])
+	c := newTestClient(&mockFGA{streamedListObjectsFn: objectStream("pipeline", nil, stream...)})
+	ctx := userCtx(testUserUID)
+
+	var got []uuid.UUID
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("too many pages")
+		}
+		page, next, err := c.ListPermissionsPage(ctx, "pipeline", "reader", 3, token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page) > 3 {
+			t.Fatalf("page exceeds the page size: %d", len(page))
+		}
+		got = append(got, page...)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+
+	want := slices.SortedFunc(slices.Values(uids), func(a, b uuid.UUID) int {
+		return slices.Compare(a.Bytes(), b.Bytes())
+	})
+	if !slices.Equal(got, want) {
+		t.Errorf("pages should cover every object once in UUID order:\n  got:  %v\n  want: %v", got, want)
+	}
+}
+
+func TestListPermissionsPage_LastPageHasNoToken(t *testing.T) {
+	c := newTestClient(&mockFGA{streamedListObjectsFn: objectStream("pipeline", nil, testUIDs(3)...)})
+
+	page, next, err := c.ListPermissionsPage(userCtx(testUserUID), "pipeline", "reader", 3, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 3 || next != "" {
+		t.Errorf("a full last page should not return a token, got %d / %q", len(page), next)
+	}
+}
+
+func TestListPermissionsPage_InvalidToken(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+
+	_, _, err := c.ListPermissionsPage(userCtx(testUserUID), "pipeline", "reader", 10, "not-a-token")
+	if !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package acl
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localCache is a size-bounded, in-process LRU that sits in front of
+// the Redis permission caches. Each entry carries the invalidation
+// tags of its Redis counterpart so that an invalidation event, local
+// or received over pub/sub, drops exactly the affected entries.
+type localCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+	tags  map[string]map[*list.Element]struct{}
+
+	// epoch is bumped on every invalidation. A value computed from a
+	// read that started before an invalidation must not be stored, so
+	// writers pass the epoch they observed before reading.
+	epoch uint64
+
+	now func() time.Time
+}
+
+type localEntry struct {
+	key       string
+	value     string
+	tags      []string
+	expiresAt time.Time
+}
+
+func newLocalCache(size int, ttl time.Duration) *localCache {
+	return &localCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: map[string]*list.Element{},
+		tags:  map[string]map[*list.Element]struct{}{},
+		now:   time.Now,
+	}
+}
+
+// snapshot returns the current invalidation epoch.
+func (l *localCache) snapshot() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.epoch
+}
+
+func (l *localCache) get(key string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*localEntry)
+	if l.now().After(entry.expiresAt) {
+		l.remove(el)
+		return "", false
+	}
+	l.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// set stores value under key unless an invalidation happened since
+// epoch was observed.
+func (l *localCache) set(key, value string, tags []string, epoch uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if epoch != l.epoch {
+		return
+	}
+	if el, ok := l.items[key]; ok {
+		l.remove(el)
+	}
+
+	el := l.ll.PushFront(&localEntry{key: key, value: value, tags: tags, expiresAt: l.now().Add(l.ttl)})
+	l.items[key] = el
+	for _, tag := range tags {
+		if l.tags[tag] == nil {
+			l.tags[tag] = map[*list.Element]struct{}{}
+		}
+		l.tags[tag][el] = struct{}{}
+	}
+
+	for l.ll.Len() > l.size {
+		l.remove(l.ll.Back())
+	}
+}
+
+// invalidate drops every entry carrying one of tags.
+func (l *localCache) invalidate(tags ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.epoch++
+	for _, tag := range tags {
+		for el := range l.tags[tag] {
+			l.remove(el)
+		}
+	}
+}
+
+// delete drops the entry stored under key, if any.
+func (l *localCache) delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		l.remove(el)
+	}
+}
+
+func (l *localCache) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *localCache) remove(el *list.Element) {
+	entry := el.Value.(*localEntry)
+	l.ll.Remove(el)
+	delete(l.items, entry.key)
+	for _, tag := range entry.tags {
+		delete(l.tags[tag], el)
+		if len(l.tags[tag]) == 0 {
+			delete(l.tags, tag)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package acl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	l := newLocalCache(2, time.Minute)
+
+	l.set("a", "1", nil, 0)
+	l.set("b", "1", nil, 0)
+	l.get("a")
+	l.set("c", "1", nil, 0)
+
+	if _, ok := l.get("b"); ok {
+		t.Error("least recently used entry should be evicted")
+	}
+	if _, ok := l.get("a"); !ok {
+		t.Error("recently used entry should be kept")
+	}
+}
+
+func TestLocalCache_ExpiresEntries(t *testing.T) {
+	now := time.Now()
+	l := newLocalCache(10, time.Second)
+	l.now = func() time.Time { return now }
+
+	l.set("a", "1", nil, 0)
+	now = now.Add(2 * time.Second)
+
+	if _, ok := l.get("a"); ok {
+		t.Error("expired entry should not be served")
+	}
+	if l.len() != 0 {
+		t.Error("expired entry should be removed")
+	}
+}
+
+func TestLocalCache_InvalidateDropsTaggedEntries(t *testing.T) {
+	l := newLocalCache(10, time.Minute)
+
+	l.set("a", "1", []string{"obj:file:1", "subj:user:1"}, 0)
+	l.set("b", "1", []string{"obj:file:2", "subj:user:1"}, 0)
+	l.set("c", "1", []string{"obj:file:2", "subj:user:2"}, 0)
+
+	l.invalidate("subj:user:1")
+
+	if _, ok := l.get("a"); ok {
+		t.Error("a is tagged with the invalidated subject")
+	}
+	if _, ok := l.get("b"); ok {
+		t.Error("b is tagged with the invalidated subject")
+	}
+	if _, ok := l.get("c"); !ok {
+		t.Error("c should survive")
+	}
+}
+
+func TestLocalCache_SkipsWritesAcrossInvalidation(t *testing.T) {
+	l := newLocalCache(10, time.Minute)
+
+	epoch := l.snapshot()
+	l.invalidate("obj:file:1")
+	l.set("a", "1", []string{"obj:file:1"}, epoch)
+
+	if _, ok := l.get("a"); ok {
+		t.Error("a value read before an invalidation must not be stored")
+	}
+}
@@ -0,0 +1,354 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	errorsx "github.com/instill-ai/x/errors"
+	logx "github.com/instill-ai/x/log"
+)
+
+// MembershipRoles are the relations that make a subject a member of an
+// organization or a group, from the highest to the lowest. A member
+// holds exactly one of them.
+var MembershipRoles = []string{"owner", "admin", "member"}
+
+// Membership is the role a subject holds in an organization or a group.
+type Membership struct {
+	// GroupType is the object type of the group, e.g. "organization".
+	GroupType string
+	GroupUID  uuid.UUID
+	// User is the FGA subject of the member, e.g. "user:<uid>" or
+	// "group:<uid>#member".
+	User string
+	Role string
+}
+
+// AddMember adds user to the group with role. Adding a member with the
+// role they already hold is a no-op; a member holding another role
+// fails with errorsx.ErrAlreadyExists, use SetMemberRole instead.
+func (c *ACLClient) AddMember(ctx context.Context, groupType string, groupUID uuid.UUID, user, role string) error {
+	if err := c.validateMembershipRole(groupType, role); err != nil {
+		return err
+	}
+	members, err := c.readMembers(ctx, groupType, groupUID, user)
+	if err != nil {
+		return err
+	}
+	if current := memberRole(members); current != "" {
+		if current == role && len(members) == 1 {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is already a %s of %s:%s", errorsx.ErrAlreadyExists, user, current, groupType, groupUID)
+	}
+
+	tx := c.Begin()
+	tx.op, tx.relation = AuditAddMember, role
+	tx.Grant(groupType, groupUID, user, role)
+	return c.commitMembership(ctx, tx)
+}
+
+// RemoveMember removes user from the group, whatever their role. It
+// fails with errorsx.ErrMembershipNotFound when user is not a member,
+// and with errorsx.ErrCanNotRemoveOwnerFromOrganization when user is
+// the last owner of the group.
+//
+// OpenFGA has no conditional writes, so two owners removed concurrently
+// could both pass the last-owner check. When an owner is removed, the
+// owners are read again from the primary after the write, and the
+// change is rolled back, failing with
+// errorsx.ErrCanNotRemoveOwnerFromOrganization, if none is left. The
+// group is briefly without an owner in that case; callers that can't
+// afford it serialise the membership changes of a group.
+func (c *ACLClient) RemoveMember(ctx context.Context, groupType string, groupUID uuid.UUID, user string) error {
+	if err := c.validate(groupType); err != nil {
+		return err
+	}
+	members, err := c.readMembers(ctx, groupType, groupUID, "")
+	if err != nil {
+		return err
+	}
+	held := membersOf(members, user)
+	if len(held) == 0 {
+		return fmt.Errorf("%w: %s is not a member of %s:%s", errorsx.ErrMembershipNotFound, user, groupType, groupUID)
+	}
+	if err := checkLastOwner(members, user, ""); err != nil {
+		return err
+	}
+
+	tx := c.Begin()
+	tx.op, tx.before = AuditRemoveMember, held
+	for _, t := range held {
+		tx.Revoke(groupType, groupUID, user, t.Relation)
+	}
+	if err := c.commitMembership(ctx, tx); err != nil {
+		return err
+	}
+	return c.keepOwner(ctx, AuditRemoveMember, groupType, groupUID, user, held, "")
+}
+
+// SetMemberRole replaces the role of a member of the group in a single
+// write. It fails with errorsx.ErrMembershipNotFound when user is not a
+// member, and with errorsx.ErrCanNotRemoveOwnerFromOrganization when it
+// would demote the last owner of the group. Concurrent demotions are
+// handled as in RemoveMember.
+func (c *ACLClient) SetMemberRole(ctx context.Context, groupType string, groupUID uuid.UUID, user, role string) error {
+	if err := c.validateMembershipRole(groupType, role); err != nil {
+		return err
+	}
+	members, err := c.readMembers(ctx, groupType, groupUID, "")
+	if err != nil {
+		return err
+	}
+	held := membersOf(members, user)
+	if len(held) == 0 {
+		return fmt.Errorf("%w: %s is not a member of %s:%s", errorsx.ErrMembershipNotFound, user, groupType, groupUID)
+	}
+	if err := checkLastOwner(members, user, role); err != nil {
+		return err
+	}
+
+	tx := c.Begin()
+	tx.op, tx.relation, tx.before = AuditSetMemberRole, role, held
+	for _, t := range held {
+		if t.Relation != role {
+			tx.Revoke(groupType, groupUID, user, t.Relation)
+		}
+	}
+	if memberRole(held) == role && tx.Len() == 0 {
+		return nil
+	}
+	tx.Grant(groupType, groupUID, user, role)
+	if err := c.commitMembership(ctx, tx); err != nil {
+		return err
+	}
+	return c.keepOwner(ctx, AuditSetMemberRole, groupType, groupUID, user, held, role)
+}
+
+// ListMembers lists the direct members of the group with their role.
+// Members reached through a userset, e.g. the members of an
+// organization added to a group as "organization:<uid>#member", are
+// not expanded.
+func (c *ACLClient) ListMembers(ctx context.Context, groupType string, groupUID uuid.UUID) ([]Membership, error) {
+	if err := c.validate(groupType); err != nil {
+		return nil, err
+	}
+	members, err := c.readMembers(ctx, groupType, groupUID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Membership
+	seen := map[string]bool{}
+	for _, t := range members {
+		if seen[t.User] {
+			continue
+		}
+		seen[t.User] = true
+		out = append(out, Membership{
+			GroupType: groupType,
+			GroupUID:  groupUID,
+			User:      t.User,
+			Role:      memberRole(membersOf(members, t.User)),
+		})
+	}
+	return out, nil
+}
+
+// ListMemberships lists the groups of groupType user is a direct
+// member of, e.g. the organizations of a user, with their role.
+func (c *ACLClient) ListMemberships(ctx context.Context, user, groupType string) ([]Membership, error) {
+	if err := c.validate(groupType); err != nil {
+		return nil, err
+	}
+	tuples, err := c.ReadTuples(ctx, ReadTupleFilter{Object: groupType + ":", User: user})
+	if err != nil {
+		return nil, err
+	}
+
+	byGroup := map[uuid.UUID][]ReadTuple{}
+	var groups []uuid.UUID
+	for _, t := range tuples {
+		if !slices.Contains(MembershipRoles, t.Relation) {
+			continue
+		}
+		groupUID, err := uuid.FromString(strings.TrimPrefix(t.Object, groupType+":"))
+		if err != nil {
+			continue
+		}
+		if _, ok := byGroup[groupUID]; !ok {
+			groups = append(groups, groupUID)
+		}
+		byGroup[groupUID] = append(byGroup[groupUID], t)
+	}
+
+	out := make([]Membership, 0, len(groups))
+	for _, groupUID := range groups {
+		out = append(out, Membership{
+			GroupType: groupType,
+			GroupUID:  groupUID,
+			User:      user,
+			Role:      memberRole(byGroup[groupUID]),
+		})
+	}
+	return out, nil
+}
+
+// keepOwner rolls back a committed op that took the held roles of user
+// away and granted role, if any, when the group is left without owners,
+// e.g. because another owner was removed concurrently.
+func (c *ACLClient) keepOwner(ctx context.Context, op AuditOperation, groupType string, groupUID uuid.UUID, user string, held []ReadTuple, role string) error {
+	if role == "owner" || !slices.ContainsFunc(held, func(t ReadTuple) bool { return t.Relation == "owner" }) {
+		return nil
+	}
+
+	// The replicas may not have seen the write yet.
+	filter := ReadTupleFilter{Object: fmt.Sprintf("%s:%s", groupType, groupUID.String()), Relation: "owner"}
+	for _, err := range c.scanTuples(ctx, filter, WriteMode) {
+		if err != nil {
+			return fmt.Errorf("checking the owners of %s:%s: %w", groupType, groupUID, err)
+		}
+		return nil
+	}
+
+	tx := c.Begin()
+	tx.op, tx.relation = op, role
+	if role != "" && !slices.ContainsFunc(held, func(t ReadTuple) bool { return t.Relation == role }) {
+		tx.Revoke(groupType, groupUID, user, role)
+	}
+	for _, t := range held {
+		if t.Relation != role {
+			tx.Grant(groupType, groupUID, user, t.Relation)
+		}
+	}
+	if err := c.commitMembership(ctx, tx); err != nil {
+		return fmt.Errorf("%w: %s was the last owner, and %s:%s is left without one: %v", errorsx.ErrCanNotRemoveOwnerFromOrganization, user, groupType, groupUID, err)
+	}
+	return fmt.Errorf("%w: %s was the last owner", errorsx.ErrCanNotRemoveOwnerFromOrganization, user)
+}
+
+// validateMembershipRole checks that role is one of MembershipRoles and
+// that the model defines it on groupType.
+func (c *ACLClient) validateMembershipRole(groupType, role string) error {
+	if !slices.Contains(MembershipRoles, role) {
+		return fmt.Errorf("%w: %q is not a membership role%s", errorsx.ErrInvalidArgument, role, suggest(role, MembershipRoles))
+	}
+	return c.validate(groupType, role)
+}
+
+// readMembers reads the membership tuples of the group, restricted to
+// user unless it is empty.
+func (c *ACLClient) readMembers(ctx context.Context, groupType string, groupUID uuid.UUID, user string) ([]ReadTuple, error) {
+	tuples, err := c.ReadTuples(ctx, ReadTupleFilter{
+		Object: fmt.Sprintf("%s:%s", groupType, groupUID.String()),
+		User:   user,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(tuples, func(t ReadTuple) bool {
+		return !slices.Contains(MembershipRoles, t.Relation)
+	}), nil
+}
+
+// commitMembership commits a membership change and invalidates the
+// caches of the members of the usersets it touches, whose permissions
+// change along with the group's. The caches of the direct subjects are
+// invalidated by the transaction itself.
+func (c *ACLClient) commitMembership(ctx context.Context, tx *TupleTx) error {
+	log, _ := logx.GetZapLogger(ctx)
+	log.Debug("Changing membership",
+		zap.String("operation", string(tx.op)),
+		zap.String("role", tx.relation),
+		zap.Int("tuples", tx.Len()),
+	)
+
+	err := tx.Commit(ctx)
+	visited := map[string]bool{}
+	for _, op := range tx.ops {
+		c.invalidateUsersetMembers(ctx, op.tuple.User, visited)
+	}
+	return err
+}
+
+// invalidateUsersetMembers invalidates the caches of every subject
+// related to the object of a userset subject such as
+// "organization:<uid>#member", recursively. Every relation is followed
+// since the model may derive the userset relation from others, e.g.
+// owners being members; invalidating a few more subjects than needed
+// is harmless.
+func (c *ACLClient) invalidateUsersetMembers(ctx context.Context, subject string, visited map[string]bool) {
+	object, _, isUserset := strings.Cut(subject, "#")
+	if !isUserset || visited[object] {
+		return
+	}
+	visited[object] = true
+
+	tuples, err := c.ReadTuples(ctx, ReadTupleFilter{Object: object})
+	if err != nil {
+		log, _ := logx.GetZapLogger(ctx)
+		log.Warn("Failed to read userset members", zap.Error(err), zap.String("userset", subject))
+		return
+	}
+	for _, t := range tuples {
+		if isWildcardSubject(t.User) {
+			continue
+		}
+		c.invalidateListPermissionsCacheForUser(ctx, t.User)
+		c.invalidateUsersetMembers(ctx, t.User, visited)
+	}
+}
+
+// membersOf returns the membership tuples of user.
+func membersOf(members []ReadTuple, user string) []ReadTuple {
+	var out []ReadTuple
+	for _, t := range members {
+		if t.User == user {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// memberRole returns the highest of the roles held in tuples, or ""
+// when there are none.
+func memberRole(tuples []ReadTuple) string {
+	for _, role := range MembershipRoles {
+		for _, t := range tuples {
+			if t.Relation == role {
+				return role
+			}
+		}
+	}
+	return ""
+}
+
+// checkLastOwner refuses a change leaving the group without owners:
+// user is given newRole, or removed when newRole is empty. Groups that
+// have no owners at all, such as groups whose type defines none, are
+// not constrained.
+func checkLastOwner(members []ReadTuple, user, newRole string) error {
+	if newRole == "owner" {
+		return nil
+	}
+	isOwner, otherOwners := false, false
+	for _, t := range members {
+		if t.Relation != "owner" {
+			continue
+		}
+		if t.User == user {
+			isOwner = true
+		} else {
+			otherOwners = true
+		}
+	}
+	if isOwner && !otherOwners {
+		return fmt.Errorf("%w: %s is the last owner", errorsx.ErrCanNotRemoveOwnerFromOrganization, user)
+	}
+	return nil
+}
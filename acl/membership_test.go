@@ -0,0 +1,279 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+var testGroupUID = uuid.Must(uuid.FromString("e1f2a3b4-c5d6-7890-abcd-0123456789ab"))
+
+// tuplesReadFn serves Read requests from tuples, matching the object
+// exactly or by type when the filter names only a type.
+func tuplesReadFn(tuples ...*openfga.TupleKey) func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+	return func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+		filter := req.TupleKey
+		var out []*openfga.Tuple
+		for _, k := range tuples {
+			if filter.Object != "" && k.Object != filter.Object &&
+				!(strings.HasSuffix(filter.Object, ":") && strings.HasPrefix(k.Object, filter.Object)) {
+				continue
+			}
+			if filter.Relation != "" && k.Relation != filter.Relation {
+				continue
+			}
+			if filter.User != "" && k.User != filter.User {
+				continue
+			}
+			out = append(out, &openfga.Tuple{Key: k})
+		}
+		return &openfga.ReadResponse{Tuples: out}, nil
+	}
+}
+
+func orgTuple(user, role string) *openfga.TupleKey {
+	return &openfga.TupleKey{User: user, Relation: role, Object: "organization:" + testOrgUID}
+}
+
+var testOrg = uuid.Must(uuid.FromString(testOrgUID))
+
+// ============================================================
+// AddMember
+// ============================================================
+
+func TestAddMember(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c := newTestClient(&mockFGA{
+		readFn:  tuplesReadFn(orgTuple("user:"+testVisitorUID, "admin")),
+		writeFn: recordWrites(&writes),
+	})
+	ctx := context.Background()
+
+	if err := c.AddMember(ctx, "organization", testOrg, "user:"+testUserUID, "member"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 || writes[0].Writes.TupleKeys[0].Relation != "member" {
+		t.Fatalf("expected the member tuple to be written, got %v", writes)
+	}
+
+	if err := c.AddMember(ctx, "organization", testOrg, "user:"+testVisitorUID, "admin"); err != nil {
+		t.Errorf("adding a member with their role should be a no-op, got %v", err)
+	}
+	err := c.AddMember(ctx, "organization", testOrg, "user:"+testVisitorUID, "member")
+	if !errors.Is(err, errorsx.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+	if err := c.AddMember(ctx, "organization", testOrg, "user:"+testUserUID, "reader"); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("expected an invalid argument, got %v", err)
+	}
+	if len(writes) != 1 {
+		t.Errorf("expected no further write, got %d", len(writes))
+	}
+}
+
+// ============================================================
+// RemoveMember / SetMemberRole
+// ============================================================
+
+func TestRemoveMember_KeepsLastOwner(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		readFn: tuplesReadFn(
+			orgTuple("user:"+testUserUID, "owner"),
+			orgTuple("user:"+testVisitorUID, "member"),
+		),
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			t.Fatal("should not write")
+			return nil, nil
+		},
+	})
+	ctx := context.Background()
+
+	err := c.RemoveMember(ctx, "organization", testOrg, "user:"+testUserUID)
+	if !errors.Is(err, errorsx.ErrCanNotRemoveOwnerFromOrganization) {
+		t.Errorf("expected ErrCanNotRemoveOwnerFromOrganization, got %v", err)
+	}
+	err = c.SetMemberRole(ctx, "organization", testOrg, "user:"+testUserUID, "admin")
+	if !errors.Is(err, errorsx.ErrCanNotRemoveOwnerFromOrganization) {
+		t.Errorf("expected ErrCanNotRemoveOwnerFromOrganization, got %v", err)
+	}
+	err = c.RemoveMember(ctx, "organization", testOrg, "user:"+testOrgUID)
+	if !errors.Is(err, errorsx.ErrMembershipNotFound) {
+		t.Errorf("expected ErrMembershipNotFound, got %v", err)
+	}
+}
+
+// concurrentOwnerRemoval serves the group tuples from a store that
+// Write requests update, and removes the other owner right after the
+// first write, as a concurrent RemoveMember would.
+func concurrentOwnerRemoval(other string) (*mockFGA, *[]*openfga.TupleKey) {
+	tuples := []*openfga.TupleKey{orgTuple("user:"+testUserUID, "owner"), orgTuple(other, "owner")}
+	fga := &mockFGA{
+		readFn: func(ctx context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return tuplesReadFn(tuples...)(ctx, req)
+		},
+	}
+	fga.writeFn = func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+		for _, d := range req.GetDeletes().GetTupleKeys() {
+			tuples = slices.DeleteFunc(tuples, func(k *openfga.TupleKey) bool {
+				return k.User == d.User && k.Relation == d.Relation && k.Object == d.Object
+			})
+		}
+		tuples = append(tuples, req.GetWrites().GetTupleKeys()...)
+		tuples = slices.DeleteFunc(tuples, func(k *openfga.TupleKey) bool { return k.User == other })
+		return &openfga.WriteResponse{}, nil
+	}
+	return fga, &tuples
+}
+
+func TestRemoveMember_RollsBackConcurrentLastOwnerRemoval(t *testing.T) {
+	fga, tuples := concurrentOwnerRemoval("user:" + testVisitorUID)
+	c := newTestClient(fga)
+
+	err := c.RemoveMember(context.Background(), "organization", testOrg, "user:"+testUserUID)
+	if !errors.Is(err, errorsx.ErrCanNotRemoveOwnerFromOrganization) {
+		t.Fatalf("expected ErrCanNotRemoveOwnerFromOrganization, got %v", err)
+	}
+	if len(*tuples) != 1 || (*tuples)[0].User != "user:"+testUserUID || (*tuples)[0].Relation != "owner" {
+		t.Errorf("expected the owner to be restored, got %v", *tuples)
+	}
+}
+
+func TestSetMemberRole_RollsBackConcurrentLastOwnerDemotion(t *testing.T) {
+	fga, tuples := concurrentOwnerRemoval("user:" + testVisitorUID)
+	c := newTestClient(fga)
+
+	err := c.SetMemberRole(context.Background(), "organization", testOrg, "user:"+testUserUID, "admin")
+	if !errors.Is(err, errorsx.ErrCanNotRemoveOwnerFromOrganization) {
+		t.Fatalf("expected ErrCanNotRemoveOwnerFromOrganization, got %v", err)
+	}
+	if len(*tuples) != 1 || (*tuples)[0].Relation != "owner" {
+		t.Errorf("expected the owner role to be restored, got %v", *tuples)
+	}
+}
+
+func TestRemoveMember_InvalidatesMemberCaches(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c, mr := newTestClientWithCache(&mockFGA{
+		readFn: tuplesReadFn(
+			orgTuple("user:"+testUserUID, "owner"),
+			orgTuple("user:"+testVisitorUID, "owner"),
+		),
+		writeFn: recordWrites(&writes),
+	})
+	defer mr.Close()
+
+	entry := checkCacheEntry("user", testVisitorUID, "pipeline", testObjectUID.String(), "reader")
+	seedCache(t, c, []*CacheEntry{entry}, []string{"1"})
+
+	if err := c.RemoveMember(context.Background(), "organization", testOrg, "user:"+testVisitorUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 || writes[0].Deletes.TupleKeys[0].User != "user:"+testVisitorUID {
+		t.Fatalf("expected the owner tuple to be deleted, got %v", writes)
+	}
+	if isCached(t, c, entry) {
+		t.Error("the removed member's checks should be invalidated")
+	}
+}
+
+func TestSetMemberRole_SwapsRoleInSingleWrite(t *testing.T) {
+	var writes []*openfga.WriteRequest
+	c := newTestClient(&mockFGA{
+		readFn: tuplesReadFn(
+			orgTuple("user:"+testUserUID, "owner"),
+			orgTuple("user:"+testVisitorUID, "member"),
+		),
+		writeFn: recordWrites(&writes),
+	})
+	ctx := context.Background()
+
+	if err := c.SetMemberRole(ctx, "organization", testOrg, "user:"+testVisitorUID, "member"); err != nil || len(writes) != 0 {
+		t.Fatalf("setting the current role should be a no-op, got %v, %d writes", err, len(writes))
+	}
+	if err := c.SetMemberRole(ctx, "organization", testOrg, "user:"+testVisitorUID, "owner"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 {
+		t.Fatalf("expected a single Write request, got %d", len(writes))
+	}
+	req := writes[0]
+	if req.Deletes.TupleKeys[0].Relation != "member" || req.Writes.TupleKeys[0].Relation != "owner" {
+		t.Errorf("expected member to be swapped for owner, got %v", req)
+	}
+}
+
+func TestMembership_InvalidatesUsersetMembers(t *testing.T) {
+	orgMembers := "organization:" + testOrgUID + "#member"
+	c, mr := newTestClientWithCache(&mockFGA{
+		readFn: tuplesReadFn(
+			&openfga.TupleKey{User: orgMembers, Relation: "member", Object: "group:" + testGroupUID.String()},
+			orgTuple("user:"+testUserUID, "admin"),
+		),
+		writeFn: recordWrites(new([]*openfga.WriteRequest)),
+	})
+	defer mr.Close()
+
+	entry := listCacheEntry("user", testUserUID, "pipeline", "reader")
+	seedCache(t, c, []*CacheEntry{entry}, []string{"[]"})
+
+	if err := c.RemoveMember(context.Background(), "group", testGroupUID, orgMembers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isCached(t, c, entry) {
+		t.Error("the caches of the organization's members should be invalidated")
+	}
+}
+
+// ============================================================
+// ListMembers / ListMemberships
+// ============================================================
+
+func TestListMembers(t *testing.T) {
+	c := newTestClient(&mockFGA{readFn: tuplesReadFn(
+		orgTuple("user:"+testUserUID, "owner"),
+		orgTuple("user:"+testUserUID, "member"),
+		orgTuple("user:"+testVisitorUID, "member"),
+		orgTuple("user:*", "reader"),
+	)})
+
+	members, err := c.ListMembers(context.Background(), "organization", testOrg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Membership{
+		{GroupType: "organization", GroupUID: testOrg, User: "user:" + testUserUID, Role: "owner"},
+		{GroupType: "organization", GroupUID: testOrg, User: "user:" + testVisitorUID, Role: "member"},
+	}
+	if !slices.Equal(members, want) {
+		t.Errorf("expected %v, got %v", want, members)
+	}
+}
+
+func TestListMemberships(t *testing.T) {
+	otherOrg := uuid.Must(uuid.NewV4())
+	c := newTestClient(&mockFGA{readFn: tuplesReadFn(
+		orgTuple("user:"+testUserUID, "admin"),
+		&openfga.TupleKey{User: "user:" + testUserUID, Relation: "member", Object: "organization:" + otherOrg.String()},
+		&openfga.TupleKey{User: "user:" + testUserUID, Relation: "member", Object: "group:" + testGroupUID.String()},
+	)})
+
+	memberships, err := c.ListMemberships(context.Background(), "user:"+testUserUID, "organization")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Membership{
+		{GroupType: "organization", GroupUID: testOrg, User: "user:" + testUserUID, Role: "admin"},
+		{GroupType: "organization", GroupUID: otherOrg, User: "user:" + testUserUID, Role: "member"},
+	}
+	if !slices.Equal(memberships, want) {
+		t.Errorf("expected %v, got %v", want, memberships)
+	}
+}
@@ -0,0 +1,297 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// Metric names of the instruments recorded by the ACL client. They are
+// registered against the global MeterProvider (see otel.SetupMetrics),
+// so they are exported once the service sets it up; before that they
+// are no-ops.
+const (
+	// MetricCheckDuration is a histogram of the CheckPermission latency in
+	// seconds, by object type, relation and outcome (allowed, denied or
+	// error).
+	MetricCheckDuration = "acl.check.duration"
+	// MetricListPermissionsDuration is a histogram of the ListPermissions
+	// and ListPublicPermissions latency in seconds, by object type,
+	// relation and outcome (ok or error).
+	MetricListPermissionsDuration = "acl.list_permissions.duration"
+	// MetricCacheRequests counts the cache lookups by cache layer (local,
+	// redis or memory) and result (hit, miss or error).
+	MetricCacheRequests = "acl.cache.requests"
+	// MetricListTruncations counts the StreamedListObjects results
+	// suspected to be truncated, by object type and relation.
+	MetricListTruncations = "acl.list.truncation_suspected"
+	// MetricPinnedReads counts the reads of pinned users, which bypass the
+	// caches and use HIGHER_CONSISTENCY, by operation (check or list).
+	MetricPinnedReads = "acl.pinned_reads"
+	// MetricOpenFGAErrors counts the errors returned by OpenFGA, by RPC
+	// method and error code.
+	MetricOpenFGAErrors = "acl.openfga.errors"
+)
+
+// Attribute keys of the ACL metrics.
+const (
+	attrObjectType  = attribute.Key("acl.object_type")
+	attrRelation    = attribute.Key("acl.relation")
+	attrOutcome     = attribute.Key("acl.outcome")
+	attrCacheLayer  = attribute.Key("acl.cache.layer")
+	attrCacheResult = attribute.Key("acl.cache.result")
+	attrOperation   = attribute.Key("acl.operation")
+	attrRPCMethod   = attribute.Key("rpc.method")
+	attrErrorCode   = attribute.Key("acl.openfga.code")
+)
+
+// Cache layers of MetricCacheRequests.
+const (
+	cacheLayerLocal  = "local"
+	cacheLayerRedis  = "redis"
+	cacheLayerMemory = "memory"
+)
+
+type aclMetrics struct {
+	checkDuration metric.Float64Histogram
+	listDuration  metric.Float64Histogram
+	cacheRequests metric.Int64Counter
+	truncations   metric.Int64Counter
+	pinnedReads   metric.Int64Counter
+	fgaErrors     metric.Int64Counter
+}
+
+// metrics holds the instruments of the package. The global meter
+// delegates to the provider set later by otel.SetupMetrics.
+var metrics = newACLMetrics(otel.Meter("github.com/instill-ai/x/acl"))
+
+// newACLMetrics creates the instruments on meter. Creation errors are
+// ignored: the returned instruments are then no-ops.
+func newACLMetrics(meter metric.Meter) *aclMetrics {
+	m := &aclMetrics{}
+	m.checkDuration, _ = meter.Float64Histogram(MetricCheckDuration,
+		metric.WithDescription("Latency of CheckPermission."),
+		metric.WithUnit("s"))
+	m.listDuration, _ = meter.Float64Histogram(MetricListPermissionsDuration,
+		metric.WithDescription("Latency of ListPermissions and ListPublicPermissions."),
+		metric.WithUnit("s"))
+	m.cacheRequests, _ = meter.Int64Counter(MetricCacheRequests,
+		metric.WithDescription("Cache lookups by layer and result."),
+		metric.WithUnit("{request}"))
+	m.truncations, _ = meter.Int64Counter(MetricListTruncations,
+		metric.WithDescription("StreamedListObjects results suspected to be truncated."),
+		metric.WithUnit("{response}"))
+	m.pinnedReads, _ = meter.Int64Counter(MetricPinnedReads,
+		metric.WithDescription("Reads of users pinned to the primary."),
+		metric.WithUnit("{read}"))
+	m.fgaErrors, _ = meter.Int64Counter(MetricOpenFGAErrors,
+		metric.WithDescription("Errors returned by OpenFGA."),
+		metric.WithUnit("{error}"))
+	return m
+}
+
+func (m *aclMetrics) recordCheck(ctx context.Context, startedAt time.Time, objectType, role string, allowed bool, err error) {
+	outcome := "denied"
+	switch {
+	case err != nil:
+		outcome = "error"
+	case allowed:
+		outcome = "allowed"
+	}
+	m.checkDuration.Record(ctx, time.Since(startedAt).Seconds(), metric.WithAttributes(
+		attrObjectType.String(objectType),
+		attrRelation.String(role),
+		attrOutcome.String(outcome),
+	))
+}
+
+// recordList records the latency of a list call. A result reported as
+// possibly truncated is usable and counts as ok.
+func (m *aclMetrics) recordList(ctx context.Context, startedAt time.Time, objectType, role string, err error) {
+	outcome := "ok"
+	if err != nil && !errors.Is(err, ErrPossiblyTruncated) {
+		outcome = "error"
+	}
+	m.listDuration.Record(ctx, time.Since(startedAt).Seconds(), metric.WithAttributes(
+		attrObjectType.String(objectType),
+		attrRelation.String(role),
+		attrOutcome.String(outcome),
+	))
+}
+
+// recordCache records the lookups of n entries in a cache layer.
+func (m *aclMetrics) recordCache(ctx context.Context, layer, result string, n int) {
+	if n == 0 {
+		return
+	}
+	m.cacheRequests.Add(ctx, int64(n), metric.WithAttributes(
+		attrCacheLayer.String(layer),
+		attrCacheResult.String(result),
+	))
+}
+
+func (m *aclMetrics) recordTruncation(ctx context.Context, objectType, role string) {
+	m.truncations.Add(ctx, 1, metric.WithAttributes(
+		attrObjectType.String(objectType),
+		attrRelation.String(role),
+	))
+}
+
+func (m *aclMetrics) recordPinnedRead(ctx context.Context, operation string) {
+	m.pinnedReads.Add(ctx, 1, metric.WithAttributes(attrOperation.String(operation)))
+}
+
+func (m *aclMetrics) recordFGAError(ctx context.Context, method string, err error) {
+	if err == nil {
+		return
+	}
+	m.fgaErrors.Add(ctx, 1, metric.WithAttributes(
+		attrRPCMethod.String(method),
+		attrErrorCode.String(fgaErrorCode(err)),
+	))
+}
+
+// fgaErrorCodeNames are the OpenFGA error code enums, whose values are
+// carried as the gRPC status code of the errors.
+var fgaErrorCodeNames = []map[int32]string{
+	openfga.ErrorCode_name,
+	openfga.AuthErrorCode_name,
+	openfga.UnprocessableContentErrorCode_name,
+	openfga.InternalErrorCode_name,
+	openfga.NotFoundErrorCode_name,
+}
+
+// fgaErrorCode returns the OpenFGA error code of err, e.g.
+// "validation_error", or its gRPC code when OpenFGA didn't set one,
+// e.g. "Unavailable".
+func fgaErrorCode(err error) string {
+	code := status.Code(err)
+	// The OpenFGA codes start above the range of the gRPC codes.
+	if code > codes.Unauthenticated {
+		for _, names := range fgaErrorCodeNames {
+			if name, ok := names[int32(code)]; ok {
+				return name
+			}
+		}
+	}
+	return code.String()
+}
+
+// instrumentedFGA records the errors of the OpenFGA calls made by the
+// client.
+type instrumentedFGA struct {
+	openfga.OpenFGAServiceClient
+}
+
+func instrumentFGA(client openfga.OpenFGAServiceClient) openfga.OpenFGAServiceClient {
+	if _, ok := client.(instrumentedFGA); ok {
+		return client
+	}
+	return instrumentedFGA{client}
+}
+
+func (f instrumentedFGA) Check(ctx context.Context, in *openfga.CheckRequest, opts ...grpc.CallOption) (*openfga.CheckResponse, error) {
+	resp, err := f.OpenFGAServiceClient.Check(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "Check", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) BatchCheck(ctx context.Context, in *openfga.BatchCheckRequest, opts ...grpc.CallOption) (*openfga.BatchCheckResponse, error) {
+	resp, err := f.OpenFGAServiceClient.BatchCheck(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "BatchCheck", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) Expand(ctx context.Context, in *openfga.ExpandRequest, opts ...grpc.CallOption) (*openfga.ExpandResponse, error) {
+	resp, err := f.OpenFGAServiceClient.Expand(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "Expand", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) Read(ctx context.Context, in *openfga.ReadRequest, opts ...grpc.CallOption) (*openfga.ReadResponse, error) {
+	resp, err := f.OpenFGAServiceClient.Read(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "Read", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) Write(ctx context.Context, in *openfga.WriteRequest, opts ...grpc.CallOption) (*openfga.WriteResponse, error) {
+	resp, err := f.OpenFGAServiceClient.Write(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "Write", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) ListUsers(ctx context.Context, in *openfga.ListUsersRequest, opts ...grpc.CallOption) (*openfga.ListUsersResponse, error) {
+	resp, err := f.OpenFGAServiceClient.ListUsers(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "ListUsers", err)
+	return resp, err
+}
+
+// StreamedListObjects records the errors opening the stream and those
+// received from it, except io.EOF.
+func (f instrumentedFGA) StreamedListObjects(ctx context.Context, in *openfga.StreamedListObjectsRequest, opts ...grpc.CallOption) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+	stream, err := f.OpenFGAServiceClient.StreamedListObjects(ctx, in, opts...)
+	if err != nil {
+		metrics.recordFGAError(ctx, "StreamedListObjects", err)
+		return nil, err
+	}
+	return instrumentedListStream{OpenFGAService_StreamedListObjectsClient: stream, ctx: ctx}, nil
+}
+
+func (f instrumentedFGA) ReadAuthorizationModel(ctx context.Context, in *openfga.ReadAuthorizationModelRequest, opts ...grpc.CallOption) (*openfga.ReadAuthorizationModelResponse, error) {
+	resp, err := f.OpenFGAServiceClient.ReadAuthorizationModel(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "ReadAuthorizationModel", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) ReadAuthorizationModels(ctx context.Context, in *openfga.ReadAuthorizationModelsRequest, opts ...grpc.CallOption) (*openfga.ReadAuthorizationModelsResponse, error) {
+	resp, err := f.OpenFGAServiceClient.ReadAuthorizationModels(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "ReadAuthorizationModels", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) WriteAuthorizationModel(ctx context.Context, in *openfga.WriteAuthorizationModelRequest, opts ...grpc.CallOption) (*openfga.WriteAuthorizationModelResponse, error) {
+	resp, err := f.OpenFGAServiceClient.WriteAuthorizationModel(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "WriteAuthorizationModel", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) ListStores(ctx context.Context, in *openfga.ListStoresRequest, opts ...grpc.CallOption) (*openfga.ListStoresResponse, error) {
+	resp, err := f.OpenFGAServiceClient.ListStores(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "ListStores", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) GetStore(ctx context.Context, in *openfga.GetStoreRequest, opts ...grpc.CallOption) (*openfga.GetStoreResponse, error) {
+	resp, err := f.OpenFGAServiceClient.GetStore(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "GetStore", err)
+	return resp, err
+}
+
+func (f instrumentedFGA) CreateStore(ctx context.Context, in *openfga.CreateStoreRequest, opts ...grpc.CallOption) (*openfga.CreateStoreResponse, error) {
+	resp, err := f.OpenFGAServiceClient.CreateStore(ctx, in, opts...)
+	metrics.recordFGAError(ctx, "CreateStore", err)
+	return resp, err
+}
+
+type instrumentedListStream struct {
+	openfga.OpenFGAService_StreamedListObjectsClient
+	ctx context.Context
+}
+
+func (s instrumentedListStream) Recv() (*openfga.StreamedListObjectsResponse, error) {
+	resp, err := s.OpenFGAService_StreamedListObjectsClient.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		metrics.recordFGAError(s.ctx, "StreamedListObjects", err)
+	}
+	return resp, err
+}
@@ -0,0 +1,237 @@
+package acl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// useTestMetrics records the metrics of the package in a manual reader
+// for the duration of the test.
+func useTestMetrics(t *testing.T) *sdkmetric.ManualReader {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	prev := metrics
+	metrics = newACLMetrics(provider.Meter("test"))
+	t.Cleanup(func() { metrics = prev })
+	return reader
+}
+
+// metricValue returns the sum of a counter, or the count of a histogram,
+// over the data points carrying all attrs.
+func metricValue(t *testing.T, reader *sdkmetric.ManualReader, name string, attrs ...attribute.KeyValue) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collecting metrics: %v", err)
+	}
+
+	matches := func(set attribute.Set) bool {
+		for _, kv := range attrs {
+			if v, ok := set.Value(kv.Key); !ok || v != kv.Value {
+				return false
+			}
+		}
+		return true
+	}
+
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if matches(dp.Attributes) {
+						total += dp.Value
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					if matches(dp.Attributes) {
+						total += int64(dp.Count)
+					}
+				}
+			}
+		}
+	}
+	return total
+}
+
+// ============================================================
+// Permission calls
+// ============================================================
+
+func TestMetrics_CheckPermission(t *testing.T) {
+	reader := useTestMetrics(t)
+	c, _ := newTestClientWithCache(&mockFGA{
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	})
+
+	for range 2 {
+		if _, err := c.CheckPermission(userCtx(testUserUID), "pipeline", testObjectUID, "reader"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := metricValue(t, reader, MetricCheckDuration,
+		attrObjectType.String("pipeline"), attrRelation.String("reader"), attrOutcome.String("allowed")); got != 2 {
+		t.Errorf("expected 2 allowed checks, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricCacheRequests, attrCacheLayer.String(cacheLayerRedis), attrCacheResult.String("miss")); got != 1 {
+		t.Errorf("expected 1 cache miss, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricCacheRequests, attrCacheLayer.String(cacheLayerRedis), attrCacheResult.String("hit")); got != 1 {
+		t.Errorf("expected 1 cache hit, got %d", got)
+	}
+}
+
+func TestMetrics_CheckPermissionError(t *testing.T) {
+	reader := useTestMetrics(t)
+	c := newTestClient(&mockFGA{
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return nil, status.Error(codes.Unavailable, "down")
+		},
+	})
+
+	if _, err := c.CheckPermission(userCtx(testUserUID), "pipeline", testObjectUID, "reader"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := metricValue(t, reader, MetricCheckDuration, attrOutcome.String("error")); got != 1 {
+		t.Errorf("expected 1 failed check, got %d", got)
+	}
+}
+
+func TestMetrics_BatchCheckPermission(t *testing.T) {
+	reader := useTestMetrics(t)
+	c := newTestClient(&mockFGA{
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			return allowedBatchResponse(req, func(k *openfga.CheckRequestTupleKey) bool { return k.GetRelation() == "reader" }), nil
+		},
+	})
+
+	items := []CheckItem{
+		{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "reader"},
+		{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "reader"},
+		{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "admin"},
+	}
+	if _, err := c.BatchCheckPermission(userCtx(testUserUID), items); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Duplicates are checked, and recorded, once.
+	if got := metricValue(t, reader, MetricCheckDuration, attrRelation.String("reader"), attrOutcome.String("allowed")); got != 1 {
+		t.Errorf("expected 1 allowed check, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricCheckDuration, attrRelation.String("admin"), attrOutcome.String("denied")); got != 1 {
+		t.Errorf("expected 1 denied check, got %d", got)
+	}
+}
+
+func TestMetrics_PinnedCheck(t *testing.T) {
+	reader := useTestMetrics(t)
+	c, _ := newTestClientWithCache(&mockFGA{})
+	ctx := userCtx(testUserUID)
+	if err := c.cache.Pin(ctx, testUserUID, time.Minute); err != nil {
+		t.Fatalf("pinning: %v", err)
+	}
+
+	if _, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := metricValue(t, reader, MetricPinnedReads, attrOperation.String("check")); got != 1 {
+		t.Errorf("expected 1 pinned read, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricCacheRequests); got != 0 {
+		t.Errorf("a pinned read should bypass the cache, got %d lookups", got)
+	}
+}
+
+func TestMetrics_ListPermissionsTruncation(t *testing.T) {
+	reader := useTestMetrics(t)
+	c := newTestClient(&mockFGA{
+		streamedListObjectsFn: func(context.Context, *openfga.StreamedListObjectsRequest) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+			return &mockStream{items: []*openfga.StreamedListObjectsResponse{
+				{Object: "pipeline:" + testObjectUID.String()},
+			}}, nil
+		},
+	})
+	c.listObjectsCfg = ListObjectsConfig{MaxResults: 1}.resolved()
+
+	if _, err := c.ListPermissions(userCtx(testUserUID), "pipeline", "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := metricValue(t, reader, MetricListTruncations, attrObjectType.String("pipeline"), attrRelation.String("reader")); got != 1 {
+		t.Errorf("expected 1 truncation, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricListPermissionsDuration, attrOutcome.String("ok")); got != 1 {
+		t.Errorf("expected 1 list call, got %d", got)
+	}
+}
+
+// ============================================================
+// Caches and OpenFGA errors
+// ============================================================
+
+func TestMetrics_MemoryCache(t *testing.T) {
+	reader := useTestMetrics(t)
+	ctx := context.Background()
+	cache := NewMemoryCache(CacheConfig{TTL: 60})
+
+	entries := []*CacheEntry{{Key: "a"}, {Key: "b"}}
+	if _, err := cache.Get(ctx, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cache.Set(ctx, entries[:1], []string{"1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cache.Get(ctx, entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := metricValue(t, reader, MetricCacheRequests, attrCacheLayer.String(cacheLayerMemory), attrCacheResult.String("hit")); got != 1 {
+		t.Errorf("expected 1 hit, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricCacheRequests, attrCacheLayer.String(cacheLayerMemory), attrCacheResult.String("miss")); got != 3 {
+		t.Errorf("expected 3 misses, got %d", got)
+	}
+}
+
+func TestMetrics_OpenFGAErrors(t *testing.T) {
+	reader := useTestMetrics(t)
+	fga := instrumentFGA(&mockFGA{
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return nil, status.Error(codes.Code(openfga.ErrorCode_validation_error), "invalid relation")
+		},
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return nil, status.Error(codes.Unavailable, "down")
+		},
+	})
+
+	_, _ = fga.Check(context.Background(), &openfga.CheckRequest{})
+	_, _ = fga.Write(context.Background(), &openfga.WriteRequest{})
+	_, _ = fga.Read(context.Background(), &openfga.ReadRequest{})
+
+	if got := metricValue(t, reader, MetricOpenFGAErrors, attrRPCMethod.String("Check"), attrErrorCode.String("validation_error")); got != 1 {
+		t.Errorf("expected 1 validation error, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricOpenFGAErrors, attrRPCMethod.String("Write"), attrErrorCode.String("Unavailable")); got != 1 {
+		t.Errorf("expected 1 unavailable error, got %d", got)
+	}
+	if got := metricValue(t, reader, MetricOpenFGAErrors); got != 2 {
+		t.Errorf("successful calls should not be counted, got %d errors", got)
+	}
+}
@@ -0,0 +1,170 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// discoveryBackoff bounds the delay between store discovery attempts.
+var discoveryBackoff = struct {
+	initial, max time.Duration
+}{
+	initial: 200 * time.Millisecond,
+	max:     5 * time.Second,
+}
+
+// errNoAuthorizationModel is returned when the store holds no
+// authorization model yet.
+var errNoAuthorizationModel = errors.New("no authorization model found")
+
+// permanentError marks a discovery failure that retrying cannot fix.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// retryDiscovery runs fn until it succeeds, returns a permanent error
+// or ctx is done, doubling the delay between attempts.
+func retryDiscovery(ctx context.Context, fn func() error) error {
+	log, _ := logx.GetZapLogger(ctx)
+
+	delay := discoveryBackoff.initial
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if perm := (*permanentError)(nil); errors.As(err, &perm) {
+			return perm.err
+		}
+
+		log.Warn("OpenFGA discovery failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (after %d attempts: %v)", err, attempt, ctx.Err())
+		case <-timer.C:
+		}
+		delay = min(2*delay, discoveryBackoff.max)
+	}
+}
+
+// resolveModel returns the pinned authorization model after checking
+// that it exists, or the latest model of the store.
+func (c *ACLClient) resolveModel(ctx context.Context) (*openfga.AuthorizationModel, error) {
+	if pinned := c.config.Model.ID; pinned != "" {
+		resp, err := c.writeClient.ReadAuthorizationModel(ctx, &openfga.ReadAuthorizationModelRequest{
+			StoreId: c.storeID,
+			Id:      pinned,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pinned OpenFGA authorization model %s: %w", pinned, err)
+		}
+		if resp.GetAuthorizationModel() == nil {
+			return &openfga.AuthorizationModel{Id: pinned}, nil
+		}
+		return resp.GetAuthorizationModel(), nil
+	}
+
+	model, err := c.latestModel(ctx)
+	if errors.Is(err, errNoAuthorizationModel) && c.config.Store.Bootstrap {
+		return c.bootstrapModel(ctx)
+	}
+	return model, err
+}
+
+// latestModel returns the most recently written authorization model of
+// the store.
+func (c *ACLClient) latestModel(ctx context.Context) (*openfga.AuthorizationModel, error) {
+	modelResp, err := c.writeClient.ReadAuthorizationModels(ctx, &openfga.ReadAuthorizationModelsRequest{
+		StoreId:  c.storeID,
+		PageSize: wrapperspb.Int32(1),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenFGA authorization models: %w", err)
+	}
+	if len(modelResp.AuthorizationModels) == 0 {
+		return nil, fmt.Errorf("%w in OpenFGA store %s", errNoAuthorizationModel, c.storeID)
+	}
+	return modelResp.AuthorizationModels[0], nil
+}
+
+// startModelWatcher re-reads the latest authorization model every
+// interval until stopModelWatcher is called.
+func (c *ACLClient) startModelWatcher(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopWatch = cancel
+	c.watchDone = make(chan struct{})
+
+	go func() {
+		defer close(c.watchDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.refreshModel(ctx)
+			}
+		}
+	}()
+}
+
+// stopModelWatcher stops the model watcher, if running, and waits for
+// it to return.
+func (c *ACLClient) stopModelWatcher() {
+	if c.stopWatch == nil {
+		return
+	}
+	c.stopWatch()
+	<-c.watchDone
+}
+
+// refreshModel switches the client to the latest authorization model
+// and its schema. Failures are logged and the current model is kept.
+func (c *ACLClient) refreshModel(ctx context.Context) {
+	model, err := c.latestModel(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log, _ := logx.GetZapLogger(ctx)
+			log.Warn("failed to refresh OpenFGA authorization model", zap.Error(err))
+		}
+		return
+	}
+	c.setSchema(model)
+	c.setModelID(model.GetId())
+}
+
+// setModelID atomically replaces the authorization model ID and
+// notifies Config.Model.OnChange when it changed.
+func (c *ACLClient) setModelID(modelID string) {
+	previous := c.modelID.Swap(&modelID)
+	if previous == nil || *previous == modelID {
+		return
+	}
+
+	log, _ := logx.GetZapLogger(context.Background())
+	log.Info("OpenFGA authorization model changed",
+		zap.String("previousModelID", *previous),
+		zap.String("modelID", modelID))
+
+	if c.config.Model.OnChange != nil {
+		c.config.Model.OnChange(*previous, modelID)
+	}
+}
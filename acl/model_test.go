@@ -0,0 +1,221 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// fastDiscovery shortens the discovery backoff for the duration of t.
+func fastDiscovery(t *testing.T) {
+	t.Helper()
+	saved := discoveryBackoff
+	discoveryBackoff.initial, discoveryBackoff.max = time.Millisecond, time.Millisecond
+	t.Cleanup(func() { discoveryBackoff = saved })
+}
+
+func singleStore(context.Context, *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+	return &openfga.ListStoresResponse{Stores: []*openfga.Store{{Id: testStoreID}}}, nil
+}
+
+func modelsResponse(ids ...string) *openfga.ReadAuthorizationModelsResponse {
+	resp := &openfga.ReadAuthorizationModelsResponse{}
+	for _, id := range ids {
+		resp.AuthorizationModels = append(resp.AuthorizationModels, &openfga.AuthorizationModel{Id: id})
+	}
+	return resp
+}
+
+// ============================================================
+// NewClientWithContext — discovery
+// ============================================================
+
+func TestNewClientWithContext_RetriesStoreDiscovery(t *testing.T) {
+	fastDiscovery(t)
+	var attempts int
+	fga := &mockFGA{
+		listStoresFn: func(ctx context.Context, req *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			attempts++
+			if attempts < 3 {
+				return &openfga.ListStoresResponse{}, nil
+			}
+			return singleStore(ctx, req)
+		},
+		readModelsFn: func(_ context.Context, req *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+			if req.StoreId != testStoreID {
+				t.Errorf("unexpected store %s", req.StoreId)
+			}
+			return modelsResponse(testModelID), nil
+		},
+	}
+
+	c, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{Model: ModelConfig{RefreshInterval: -1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if attempts != 3 {
+		t.Errorf("expected 3 discovery attempts, got %d", attempts)
+	}
+	if c.GetStoreID() != testStoreID || c.GetModelID() != testModelID {
+		t.Errorf("unexpected store/model %s/%s", c.GetStoreID(), c.GetModelID())
+	}
+}
+
+func TestNewClientWithContext_ReturnsErrorWhenContextExpires(t *testing.T) {
+	fastDiscovery(t)
+	fga := &mockFGA{
+		listStoresFn: func(context.Context, *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	c, err := NewClientWithContext(ctx, fga, nil, nil, Config{})
+	if err == nil || c != nil {
+		t.Fatal("expected an error once the context expires")
+	}
+	if !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("error should carry the last failure, got %v", err)
+	}
+}
+
+func TestNewClientWithContext_MultipleStoresIsNotRetried(t *testing.T) {
+	fastDiscovery(t)
+	var attempts int
+	fga := &mockFGA{
+		listStoresFn: func(context.Context, *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			attempts++
+			return &openfga.ListStoresResponse{Stores: []*openfga.Store{{Id: "a"}, {Id: "b"}}}, nil
+		},
+	}
+
+	if _, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{}); err == nil {
+		t.Fatal("multiple stores should be reported")
+	}
+	if attempts != 1 {
+		t.Errorf("a configuration problem should not be retried, got %d attempts", attempts)
+	}
+}
+
+func TestNewClient_PanicsOnConfigurationError(t *testing.T) {
+	fga := &mockFGA{
+		listStoresFn: func(context.Context, *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			return &openfga.ListStoresResponse{Stores: []*openfga.Store{{Id: "a"}, {Id: "b"}}}, nil
+		},
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewClient should keep panicking on errors")
+		}
+	}()
+	NewClient(fga, nil, nil, Config{})
+}
+
+func TestNewClientWithContext_PinnedModel(t *testing.T) {
+	fga := &mockFGA{
+		listStoresFn: singleStore,
+		readModelFn: func(_ context.Context, req *openfga.ReadAuthorizationModelRequest) (*openfga.ReadAuthorizationModelResponse, error) {
+			return &openfga.ReadAuthorizationModelResponse{AuthorizationModel: &openfga.AuthorizationModel{Id: req.Id}}, nil
+		},
+	}
+
+	c, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{Model: ModelConfig{ID: "model-pinned"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if c.GetModelID() != "model-pinned" {
+		t.Errorf("expected the pinned model, got %s", c.GetModelID())
+	}
+	if c.stopWatch != nil {
+		t.Error("a pinned model should not be watched")
+	}
+}
+
+// ============================================================
+// Model watcher — hot reload
+// ============================================================
+
+func TestModelWatcher_SwapsModelAndNotifies(t *testing.T) {
+	var latest atomic.Value
+	latest.Store(testModelID)
+	fga := &mockFGA{
+		listStoresFn: singleStore,
+		readModelsFn: func(context.Context, *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+			return modelsResponse(latest.Load().(string)), nil
+		},
+	}
+
+	var mu sync.Mutex
+	var changes [][2]string
+	cfg := Config{Model: ModelConfig{
+		RefreshInterval: 5 * time.Millisecond,
+		OnChange: func(previousID, newID string) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, [2]string{previousID, newID})
+		},
+	}}
+
+	c, err := NewClientWithContext(context.Background(), fga, nil, nil, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	latest.Store("model-02")
+	deadline := time.Now().Add(2 * time.Second)
+	for c.GetModelID() != "model-02" {
+		if time.Now().After(deadline) {
+			t.Fatal("the watcher should pick up the new model")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_ = c.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 1 || changes[0] != [2]string{testModelID, "model-02"} {
+		t.Errorf("OnChange should be called once per switch, got %v", changes)
+	}
+}
+
+func TestModelWatcher_KeepsModelOnError(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		readModelsFn: func(context.Context, *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+			return nil, errors.New("unavailable")
+		},
+	})
+
+	c.refreshModel(context.Background())
+	if c.GetModelID() != testModelID {
+		t.Errorf("a failed refresh should keep the current model, got %s", c.GetModelID())
+	}
+}
+
+func TestModelConfig_RefreshInterval(t *testing.T) {
+	cases := []struct {
+		cfg  ModelConfig
+		want time.Duration
+	}{
+		{ModelConfig{}, DefaultModelRefreshInterval},
+		{ModelConfig{RefreshInterval: time.Second}, time.Second},
+		{ModelConfig{RefreshInterval: -1}, 0},
+		{ModelConfig{ID: "model-pinned", RefreshInterval: time.Second}, 0},
+	}
+	for _, tc := range cases {
+		if got := tc.cfg.refreshInterval(); got != tc.want {
+			t.Errorf("%+v: expected %v, got %v", tc.cfg, tc.want, got)
+		}
+	}
+}
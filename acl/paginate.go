@@ -0,0 +1,152 @@
+package acl
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	"github.com/instill-ai/x/paginate"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// DefaultMaxPageFetches is the number of repository pages
+// PermittedPage fetches at most when PermittedPageOptions.MaxFetches
+// is not set.
+const DefaultMaxPageFetches = 5
+
+// HeavyFilterRatio is the share of filtered items above which a
+// PermittedPageResult reports HeavilyFiltered.
+const HeavyFilterRatio = 0.5
+
+// PageFetcher fetches a page of at most pageSize items from a
+// repository, starting after pageToken, and returns the token of the
+// next page ("" on the last page). Tokens are built with
+// paginate.EncodeToken from the create time and UID of the last item
+// of the page, and "" is the first page.
+type PageFetcher[T any] func(ctx context.Context, pageSize int32, pageToken string) (items []T, nextPageToken string, err error)
+
+// PermittedPageOptions configures PermittedPage.
+type PermittedPageOptions[T any] struct {
+	// ObjectType and Role are checked on every item for the subject of
+	// the request.
+	ObjectType string
+	Role       string
+	// Key returns the create time and UID of an item, which its page
+	// token is built from. The UID is the object the role is checked on.
+	Key func(item T) (createTime time.Time, uid uuid.UUID)
+	// PageSize is the number of items of a page. Non-positive values
+	// fall back to DefaultListPermissionsPageSize, larger ones than
+	// MaxListPermissionsPageSize are capped.
+	PageSize int32
+	// MaxFetches bounds the repository pages fetched for one page.
+	// Defaults to DefaultMaxPageFetches.
+	MaxFetches int
+}
+
+// PermittedPageResult is a page of the items the subject of the
+// request holds a role on.
+type PermittedPageResult[T any] struct {
+	Items []T
+	// NextPageToken resumes right after the last item examined, "" on
+	// the last page.
+	NextPageToken string
+	// Fetches, Scanned and Filtered count the repository pages fetched,
+	// the items they held and the items dropped by the permission
+	// checks.
+	Fetches  int
+	Scanned  int
+	Filtered int
+	// Exhausted reports that MaxFetches was reached before filling the
+	// page, so that Items may be short, or even empty, while
+	// NextPageToken is not.
+	Exhausted bool
+}
+
+// HeavilyFiltered reports whether more than HeavyFilterRatio of the
+// scanned items were filtered out, a hint that listing the permitted
+// objects first (ListPermissions) would be cheaper for this subject.
+func (r *PermittedPageResult[T]) HeavilyFiltered() bool {
+	return r.Scanned > 0 && float64(r.Filtered)/float64(r.Scanned) > HeavyFilterRatio
+}
+
+// PermittedPage returns a page of the items fetch returns that the
+// subject of the request holds opts.Role on, checking each repository
+// page with BatchCheckPermission. It keeps fetching until the page is
+// full, the repository is exhausted or opts.MaxFetches pages were
+// fetched, and returns a token resuming right after the last item it
+// examined, so that filtered pages keep their size and no item is
+// skipped or repeated across pages.
+func PermittedPage[T any](ctx context.Context, c Client, fetch PageFetcher[T], pageToken string, opts PermittedPageOptions[T]) (*PermittedPageResult[T], error) {
+	pageSize := opts.PageSize
+	switch {
+	case pageSize <= 0:
+		pageSize = DefaultListPermissionsPageSize
+	case pageSize > MaxListPermissionsPageSize:
+		pageSize = MaxListPermissionsPageSize
+	}
+	maxFetches := opts.MaxFetches
+	if maxFetches <= 0 {
+		maxFetches = DefaultMaxPageFetches
+	}
+
+	result := &PermittedPageResult[T]{Items: make([]T, 0, pageSize)}
+	token := pageToken
+	for {
+		items, next, err := fetch(ctx, pageSize, token)
+		if err != nil {
+			return nil, err
+		}
+		result.Fetches++
+
+		checks := make([]CheckItem, len(items))
+		for i, item := range items {
+			_, uid := opts.Key(item)
+			checks[i] = CheckItem{ObjectType: opts.ObjectType, ObjectUID: uid, Role: opts.Role}
+		}
+		allowed, err := c.BatchCheckPermission(ctx, checks)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, item := range items {
+			result.Scanned++
+			if !allowed[checks[i]] {
+				result.Filtered++
+			} else {
+				result.Items = append(result.Items, item)
+			}
+
+			if len(result.Items) == int(pageSize) {
+				// Resume after this item, unless it ends the repository.
+				if i < len(items)-1 || next != "" {
+					createTime, uid := opts.Key(item)
+					result.NextPageToken = paginate.EncodeToken(createTime, uid.String())
+				}
+				return result, nil
+			}
+		}
+
+		token = next
+		if token == "" {
+			return result, nil
+		}
+		if result.Fetches >= maxFetches {
+			result.Exhausted = true
+			result.NextPageToken = token
+
+			log, _ := logx.GetZapLogger(ctx)
+			log.Warn("PermittedPage reached the fetch limit",
+				zap.String("objectType", opts.ObjectType),
+				zap.String("role", opts.Role),
+				zap.Int("fetches", result.Fetches),
+				zap.Int("scanned", result.Scanned),
+				zap.Int("filtered", result.Filtered),
+				zap.Int("items", len(result.Items)),
+			)
+			return result, nil
+		}
+	}
+}
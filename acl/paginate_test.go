@@ -0,0 +1,145 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/paginate"
+)
+
+type testRow struct {
+	CreateTime time.Time
+	UID        uuid.UUID
+}
+
+func testRowKey(r testRow) (time.Time, uuid.UUID) {
+	return r.CreateTime, r.UID
+}
+
+// testRepository returns n rows ordered by create time and a fetcher
+// paging through them with paginate tokens.
+func testRepository(n int) ([]testRow, PageFetcher[testRow]) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]testRow, n)
+	for i := range rows {
+		rows[i] = testRow{CreateTime: start.Add(time.Duration(i) * time.Minute), UID: uuid.Must(uuid.NewV4())}
+	}
+
+	fetch := func(_ context.Context, pageSize int32, pageToken string) ([]testRow, string, error) {
+		from := 0
+		if pageToken != "" {
+			createTime, uid, err := paginate.DecodeToken(pageToken)
+			if err != nil {
+				return nil, "", err
+			}
+			from = slices.IndexFunc(rows, func(r testRow) bool {
+				return r.CreateTime.Equal(createTime) && r.UID.String() == uid
+			}) + 1
+		}
+		to := min(from+int(pageSize), len(rows))
+		page := rows[from:to]
+		if to == len(rows) {
+			return page, "", nil
+		}
+		return page, paginate.EncodeToken(page[len(page)-1].CreateTime, page[len(page)-1].UID.String()), nil
+	}
+	return rows, fetch
+}
+
+// permittedChecks allows the batch checks of the UIDs in permitted.
+func permittedChecks(permitted map[uuid.UUID]bool) func(context.Context, *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+	return func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+		result := map[string]*openfga.BatchCheckSingleResult{}
+		for _, check := range req.Checks {
+			uid := uuid.FromStringOrNil(check.TupleKey.Object[len("pipeline:"):])
+			result[check.CorrelationId] = &openfga.BatchCheckSingleResult{
+				CheckResult: &openfga.BatchCheckSingleResult_Allowed{Allowed: permitted[uid]},
+			}
+		}
+		return &openfga.BatchCheckResponse{Result: result}, nil
+	}
+}
+
+// ============================================================
+// PermittedPage
+// ============================================================
+
+func TestPermittedPage_FillsPagesAndResumes(t *testing.T) {
+	rows, fetch := testRepository(25)
+	permitted := map[uuid.UUID]bool{}
+	var want []testRow
+	for i, r := range rows {
+		if i%3 == 0 {
+			permitted[r.UID] = true
+			want = append(want, r)
+		}
+	}
+	c := newTestClient(&mockFGA{batchCheckFn: permittedChecks(permitted)})
+	ctx := userCtx(testUserUID)
+	opts := PermittedPageOptions[testRow]{ObjectType: "pipeline", Role: "reader", Key: testRowKey, PageSize: 4}
+
+	var got []testRow
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("pagination doesn't terminate")
+		}
+		page, err := PermittedPage(ctx, c, fetch, token, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.NextPageToken != "" && len(page.Items) != 4 {
+			t.Errorf("expected a full page, got %d items", len(page.Items))
+		}
+		got = append(got, page.Items...)
+		if token = page.NextPageToken; token == "" {
+			break
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected every permitted row once in order, got %d of %d", len(got), len(want))
+	}
+}
+
+func TestPermittedPage_CapsFetches(t *testing.T) {
+	rows, fetch := testRepository(50)
+	c := newTestClient(&mockFGA{batchCheckFn: permittedChecks(map[uuid.UUID]bool{rows[49].UID: true})})
+
+	page, err := PermittedPage(userCtx(testUserUID), c, fetch, "", PermittedPageOptions[testRow]{
+		ObjectType: "pipeline", Role: "reader", Key: testRowKey, PageSize: 5, MaxFetches: 3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !page.Exhausted || page.Fetches != 3 || len(page.Items) != 0 {
+		t.Fatalf("expected an empty exhausted page after 3 fetches, got %+v", page)
+	}
+	if page.Scanned != 15 || page.Filtered != 15 || !page.HeavilyFiltered() {
+		t.Errorf("unexpected counters %+v", page)
+	}
+	if _, uid, _ := paginate.DecodeToken(page.NextPageToken); uid != rows[14].UID.String() {
+		t.Errorf("expected to resume after the last scanned row, got %s", uid)
+	}
+}
+
+func TestPermittedPage_PropagatesErrors(t *testing.T) {
+	_, fetch := testRepository(10)
+	boom := errors.New("boom")
+	c := newTestClient(&mockFGA{
+		batchCheckFn: func(context.Context, *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			return nil, boom
+		},
+	})
+
+	_, err := PermittedPage(userCtx(testUserUID), c, fetch, "", PermittedPageOptions[testRow]{ObjectType: "pipeline", Role: "reader", Key: testRowKey})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected the check error, got %v", err)
+	}
+}
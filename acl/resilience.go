@@ -0,0 +1,345 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+	logx "github.com/instill-ai/x/log"
+)
+
+// ErrCircuitOpen is the cause of the UnavailableError returned while the
+// circuit breaker rejects the OpenFGA calls.
+var ErrCircuitOpen = errors.New("acl: OpenFGA circuit breaker is open")
+
+// UnavailableError reports an OpenFGA call that failed because the
+// server is unavailable: it timed out, returned Unavailable after the
+// retries, or was rejected by the circuit breaker. It wraps both
+// errorsx.ErrUnavailable, which errorsx.ConvertGRPCCode maps to
+// codes.Unavailable, and the cause.
+type UnavailableError struct {
+	// Method is the OpenFGA RPC, e.g. "Check".
+	Method string
+	Err    error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("acl: OpenFGA %s unavailable: %v", e.Method, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() []error {
+	return []error{errorsx.ErrUnavailable, e.Err}
+}
+
+// breakerState is the state of a circuitBreaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// callOutcome is what a call tells the circuit breaker about the server.
+type callOutcome int
+
+const (
+	// outcomeSuccess is any answer of the server, including errors such
+	// as InvalidArgument.
+	outcomeSuccess callOutcome = iota
+	// outcomeFailure is a call the server didn't answer in time.
+	outcomeFailure
+	// outcomeIgnored is a call that says nothing about the server, e.g.
+	// one canceled by the caller.
+	outcomeIgnored
+)
+
+// circuitBreaker rejects calls after threshold consecutive failures.
+// Once cooldown has elapsed a single probe is let through: the breaker
+// closes if it succeeds and opens again otherwise.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+
+	now func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may be made. Every allowed call must be
+// followed by done.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// done records the outcome of an allowed call.
+func (b *circuitBreaker) done(outcome callOutcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.probing = false
+	}
+	switch outcome {
+	case outcomeSuccess:
+		b.state = breakerClosed
+		b.failures = 0
+	case outcomeFailure:
+		b.failures++
+		if b.state == breakerHalfOpen || b.failures >= b.threshold {
+			b.state = breakerOpen
+			b.openedAt = b.now()
+		}
+	}
+}
+
+// resilientFGA bounds, retries and breaks the OpenFGA calls of the
+// client according to a ResilienceConfig.
+type resilientFGA struct {
+	openfga.OpenFGAServiceClient
+	cfg     ResilienceConfig
+	breaker *circuitBreaker
+}
+
+func newResilientFGA(client openfga.OpenFGAServiceClient, cfg ResilienceConfig) *resilientFGA {
+	return &resilientFGA{
+		OpenFGAServiceClient: client,
+		cfg:                  cfg,
+		breaker:              newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// isUnavailable reports whether err means the server didn't answer.
+func isUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// resilientCall makes a unary call through the breaker with the
+// configured timeout, retrying it on Unavailable when retry is set.
+func resilientCall[T any](ctx context.Context, f *resilientFGA, method string, retry bool, call func(context.Context) (T, error)) (T, error) {
+	var zero T
+	for attempt := 0; ; attempt++ {
+		if !f.breaker.allow() {
+			return zero, &UnavailableError{Method: method, Err: ErrCircuitOpen}
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, f.cfg.Timeout)
+		resp, err := call(callCtx)
+		cancel()
+
+		switch {
+		case err == nil || (!isUnavailable(err) && ctx.Err() == nil):
+			f.breaker.done(outcomeSuccess)
+			return resp, err
+		case ctx.Err() != nil:
+			// The caller gave up: the server may be fine.
+			f.breaker.done(outcomeIgnored)
+			return resp, err
+		}
+		f.breaker.done(outcomeFailure)
+
+		if !retry || attempt >= f.cfg.MaxRetries || status.Code(err) != codes.Unavailable {
+			return zero, &UnavailableError{Method: method, Err: err}
+		}
+
+		// Full jitter spreads the retries of concurrent callers.
+		backoff := f.cfg.RetryBackoff << attempt
+		timer := time.NewTimer(rand.N(backoff) + 1)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, &UnavailableError{Method: method, Err: err}
+		case <-timer.C:
+		}
+	}
+}
+
+func (f *resilientFGA) Check(ctx context.Context, in *openfga.CheckRequest, opts ...grpc.CallOption) (*openfga.CheckResponse, error) {
+	return resilientCall(ctx, f, "Check", true, func(ctx context.Context) (*openfga.CheckResponse, error) {
+		return f.OpenFGAServiceClient.Check(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) BatchCheck(ctx context.Context, in *openfga.BatchCheckRequest, opts ...grpc.CallOption) (*openfga.BatchCheckResponse, error) {
+	return resilientCall(ctx, f, "BatchCheck", true, func(ctx context.Context) (*openfga.BatchCheckResponse, error) {
+		return f.OpenFGAServiceClient.BatchCheck(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) Expand(ctx context.Context, in *openfga.ExpandRequest, opts ...grpc.CallOption) (*openfga.ExpandResponse, error) {
+	return resilientCall(ctx, f, "Expand", true, func(ctx context.Context) (*openfga.ExpandResponse, error) {
+		return f.OpenFGAServiceClient.Expand(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) Read(ctx context.Context, in *openfga.ReadRequest, opts ...grpc.CallOption) (*openfga.ReadResponse, error) {
+	return resilientCall(ctx, f, "Read", true, func(ctx context.Context) (*openfga.ReadResponse, error) {
+		return f.OpenFGAServiceClient.Read(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) Write(ctx context.Context, in *openfga.WriteRequest, opts ...grpc.CallOption) (*openfga.WriteResponse, error) {
+	return resilientCall(ctx, f, "Write", false, func(ctx context.Context) (*openfga.WriteResponse, error) {
+		return f.OpenFGAServiceClient.Write(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) ListUsers(ctx context.Context, in *openfga.ListUsersRequest, opts ...grpc.CallOption) (*openfga.ListUsersResponse, error) {
+	return resilientCall(ctx, f, "ListUsers", true, func(ctx context.Context) (*openfga.ListUsersResponse, error) {
+		return f.OpenFGAServiceClient.ListUsers(ctx, in, opts...)
+	})
+}
+
+// StreamedListObjects goes through the breaker but is neither bounded
+// by Timeout nor retried: the stream is bounded by the OpenFGA list
+// deadline and the caller may have consumed part of it.
+func (f *resilientFGA) StreamedListObjects(ctx context.Context, in *openfga.StreamedListObjectsRequest, opts ...grpc.CallOption) (openfga.OpenFGAService_StreamedListObjectsClient, error) {
+	if !f.breaker.allow() {
+		return nil, &UnavailableError{Method: "StreamedListObjects", Err: ErrCircuitOpen}
+	}
+	stream, err := f.OpenFGAServiceClient.StreamedListObjects(ctx, in, opts...)
+	switch {
+	case err == nil || (!isUnavailable(err) && ctx.Err() == nil):
+		f.breaker.done(outcomeSuccess)
+		return stream, err
+	case ctx.Err() != nil:
+		f.breaker.done(outcomeIgnored)
+		return stream, err
+	}
+	f.breaker.done(outcomeFailure)
+	return nil, &UnavailableError{Method: "StreamedListObjects", Err: err}
+}
+
+func (f *resilientFGA) ReadAuthorizationModel(ctx context.Context, in *openfga.ReadAuthorizationModelRequest, opts ...grpc.CallOption) (*openfga.ReadAuthorizationModelResponse, error) {
+	return resilientCall(ctx, f, "ReadAuthorizationModel", true, func(ctx context.Context) (*openfga.ReadAuthorizationModelResponse, error) {
+		return f.OpenFGAServiceClient.ReadAuthorizationModel(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) ReadAuthorizationModels(ctx context.Context, in *openfga.ReadAuthorizationModelsRequest, opts ...grpc.CallOption) (*openfga.ReadAuthorizationModelsResponse, error) {
+	return resilientCall(ctx, f, "ReadAuthorizationModels", true, func(ctx context.Context) (*openfga.ReadAuthorizationModelsResponse, error) {
+		return f.OpenFGAServiceClient.ReadAuthorizationModels(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) WriteAuthorizationModel(ctx context.Context, in *openfga.WriteAuthorizationModelRequest, opts ...grpc.CallOption) (*openfga.WriteAuthorizationModelResponse, error) {
+	return resilientCall(ctx, f, "WriteAuthorizationModel", false, func(ctx context.Context) (*openfga.WriteAuthorizationModelResponse, error) {
+		return f.OpenFGAServiceClient.WriteAuthorizationModel(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) ListStores(ctx context.Context, in *openfga.ListStoresRequest, opts ...grpc.CallOption) (*openfga.ListStoresResponse, error) {
+	return resilientCall(ctx, f, "ListStores", true, func(ctx context.Context) (*openfga.ListStoresResponse, error) {
+		return f.OpenFGAServiceClient.ListStores(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) GetStore(ctx context.Context, in *openfga.GetStoreRequest, opts ...grpc.CallOption) (*openfga.GetStoreResponse, error) {
+	return resilientCall(ctx, f, "GetStore", true, func(ctx context.Context) (*openfga.GetStoreResponse, error) {
+		return f.OpenFGAServiceClient.GetStore(ctx, in, opts...)
+	})
+}
+
+func (f *resilientFGA) CreateStore(ctx context.Context, in *openfga.CreateStoreRequest, opts ...grpc.CallOption) (*openfga.CreateStoreResponse, error) {
+	return resilientCall(ctx, f, "CreateStore", false, func(ctx context.Context) (*openfga.CreateStoreResponse, error) {
+		return f.OpenFGAServiceClient.CreateStore(ctx, in, opts...)
+	})
+}
+
+// staleEpoch returns the invalidation epoch to pass to rememberDecision.
+func (c *ACLClient) staleEpoch() uint64 {
+	if c.stale == nil {
+		return 0
+	}
+	return c.stale.snapshot()
+}
+
+// rememberDecision keeps a decision returned by OpenFGA for
+// FailModeStale.
+func (c *ACLClient) rememberDecision(entry *CacheEntry, allowed bool, epoch uint64) {
+	if c.stale == nil {
+		return
+	}
+	value := "0"
+	if allowed {
+		value = "1"
+	}
+	c.stale.set(entry.Key, value+":"+strconv.FormatInt(time.Now().UnixNano(), 10), entry.Tags, epoch)
+}
+
+// staleDecision returns the remembered decision of a check that failed
+// with err, when OpenFGA is unavailable and the object type fails
+// stale.
+func (c *ACLClient) staleDecision(ctx context.Context, objectType string, entry *CacheEntry, err error) (allowed bool, ok bool) {
+	if c.stale == nil || !errors.Is(err, errorsx.ErrUnavailable) {
+		return false, false
+	}
+	policy := c.config.Resilience.failPolicy(objectType)
+	if policy.Mode != FailModeStale {
+		return false, false
+	}
+
+	v, found := c.stale.get(entry.Key)
+	if !found {
+		return false, false
+	}
+	decision, at, _ := strings.Cut(v, ":")
+	nanos, parseErr := strconv.ParseInt(at, 10, 64)
+	if parseErr != nil {
+		return false, false
+	}
+	age := time.Since(time.Unix(0, nanos))
+	if age > policy.MaxStaleness {
+		return false, false
+	}
+
+	log, _ := logx.GetZapLogger(ctx)
+	log.Warn("OpenFGA unavailable, serving a stale permission decision",
+		zap.String("cacheKey", entry.Key),
+		zap.Duration("age", age),
+		zap.Error(err))
+	return decision == "1", true
+}
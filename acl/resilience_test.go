@@ -0,0 +1,286 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+func testResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		Enabled:          true,
+		Timeout:          50 * time.Millisecond,
+		RetryBackoff:     time.Millisecond,
+		BreakerThreshold: 3,
+		BreakerCooldown:  time.Minute,
+	}.resolved()
+}
+
+// ============================================================
+// Circuit breaker
+// ============================================================
+
+func TestCircuitBreaker_OpensAndProbes(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Second)
+	b.now = func() time.Time { return now }
+
+	for range 2 {
+		if !b.allow() {
+			t.Fatal("a closed breaker should allow calls")
+		}
+		b.done(outcomeFailure)
+	}
+	if b.allow() {
+		t.Fatal("the breaker should open after 2 failures")
+	}
+
+	now = now.Add(time.Second)
+	if !b.allow() {
+		t.Fatal("the breaker should let a probe through after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("only one probe should be in flight")
+	}
+	b.done(outcomeFailure)
+	if b.allow() {
+		t.Fatal("a failed probe should open the breaker again")
+	}
+
+	now = now.Add(time.Second)
+	if !b.allow() {
+		t.Fatal("the breaker should let a probe through after the cooldown")
+	}
+	b.done(outcomeSuccess)
+	if !b.allow() || !b.allow() {
+		t.Error("a successful probe should close the breaker")
+	}
+}
+
+func TestCircuitBreaker_IgnoredProbeReleasesSlot(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(1, time.Second)
+	b.now = func() time.Time { return now }
+
+	b.allow()
+	b.done(outcomeFailure)
+	now = now.Add(time.Second)
+	b.allow()
+	b.done(outcomeIgnored)
+	if !b.allow() {
+		t.Error("a canceled probe should let another one through")
+	}
+}
+
+// ============================================================
+// Resilient calls
+// ============================================================
+
+func TestResilientFGA_RetriesUnavailable(t *testing.T) {
+	var calls atomic.Int32
+	f := newResilientFGA(&mockFGA{
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			if calls.Add(1) < 3 {
+				return nil, status.Error(codes.Unavailable, "down")
+			}
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	}, testResilienceConfig())
+
+	resp, err := f.Check(context.Background(), &openfga.CheckRequest{})
+	if err != nil || !resp.Allowed {
+		t.Fatalf("expected the third attempt to succeed, got %v, %v", resp, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestResilientFGA_WritesAreNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	f := newResilientFGA(&mockFGA{
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			calls.Add(1)
+			return nil, status.Error(codes.Unavailable, "down")
+		},
+	}, testResilienceConfig())
+
+	_, err := f.Write(context.Background(), &openfga.WriteRequest{})
+	if !errors.Is(err, errorsx.ErrUnavailable) {
+		t.Fatalf("expected an unavailable error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls.Load())
+	}
+}
+
+func TestResilientFGA_TimeoutOpensBreaker(t *testing.T) {
+	var calls atomic.Int32
+	f := newResilientFGA(&mockFGA{
+		checkFn: func(ctx context.Context, _ *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			calls.Add(1)
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		},
+	}, testResilienceConfig())
+
+	for range 3 {
+		_, err := f.Check(context.Background(), &openfga.CheckRequest{})
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) || status.Code(unavailable.Err) != codes.DeadlineExceeded {
+			t.Fatalf("expected a timeout, got %v", err)
+		}
+	}
+
+	_, err := f.Check(context.Background(), &openfga.CheckRequest{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the breaker to be open, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("the open breaker should not call OpenFGA, got %d calls", calls.Load())
+	}
+	if code := errorsx.ConvertGRPCCode(err); code != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", code)
+	}
+}
+
+func TestResilientFGA_ServerErrorsPassThrough(t *testing.T) {
+	invalid := status.Error(codes.InvalidArgument, "bad relation")
+	f := newResilientFGA(&mockFGA{
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			return nil, invalid
+		},
+	}, testResilienceConfig())
+
+	for range 5 {
+		if _, err := f.Check(context.Background(), &openfga.CheckRequest{}); err != invalid {
+			t.Fatalf("expected the server error unchanged, got %v", err)
+		}
+	}
+}
+
+// ============================================================
+// Fail policy
+// ============================================================
+
+func newResilientTestClient(t *testing.T, down *atomic.Bool, cfg ResilienceConfig) *ACLClient {
+	t.Helper()
+	fga := &mockFGA{
+		listStoresFn: singleStore,
+		readModelFn: func(_ context.Context, req *openfga.ReadAuthorizationModelRequest) (*openfga.ReadAuthorizationModelResponse, error) {
+			return &openfga.ReadAuthorizationModelResponse{AuthorizationModel: &openfga.AuthorizationModel{Id: req.Id}}, nil
+		},
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			if down.Load() {
+				return nil, status.Error(codes.Unavailable, "down")
+			}
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+		batchCheckFn: func(_ context.Context, req *openfga.BatchCheckRequest) (*openfga.BatchCheckResponse, error) {
+			if down.Load() {
+				return nil, status.Error(codes.Unavailable, "down")
+			}
+			return allowedBatchResponse(req, func(*openfga.CheckRequestTupleKey) bool { return true }), nil
+		},
+	}
+	c, err := NewClientWithCache(context.Background(), fga, nil, nil, Config{
+		Model:      ModelConfig{ID: testModelID},
+		Resilience: cfg,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestFailPolicy_ServesStaleDecision(t *testing.T) {
+	var down atomic.Bool
+	cfg := testResilienceConfig()
+	cfg.MaxRetries = -1
+	cfg.FailPolicies = map[string]FailPolicy{"pipeline": {Mode: FailModeStale, MaxStaleness: time.Minute}}
+	c := newResilientTestClient(t, &down, cfg)
+	ctx := userCtx(testUserUID)
+
+	if allowed, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil || !allowed {
+		t.Fatalf("unexpected result %v, %v", allowed, err)
+	}
+
+	down.Store(true)
+	allowed, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader")
+	if err != nil || !allowed {
+		t.Fatalf("expected the stale decision, got %v, %v", allowed, err)
+	}
+
+	// A decision never fetched fails closed.
+	_, err = c.CheckPermission(ctx, "pipeline", uuid.Must(uuid.NewV4()), "reader")
+	if !errors.Is(err, errorsx.ErrUnavailable) {
+		t.Errorf("expected an unavailable error, got %v", err)
+	}
+
+	// A write to the object drops its stale decisions.
+	c.invalidateObjectCache(ctx, "pipeline", testObjectUID.String())
+	if _, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); !errors.Is(err, errorsx.ErrUnavailable) {
+		t.Errorf("expected an unavailable error after invalidation, got %v", err)
+	}
+}
+
+func TestFailPolicy_ServesStaleBatchDecisions(t *testing.T) {
+	var down atomic.Bool
+	cfg := testResilienceConfig()
+	cfg.MaxRetries = -1
+	cfg.FailPolicies = map[string]FailPolicy{"pipeline": {Mode: FailModeStale, MaxStaleness: time.Minute}}
+	c := newResilientTestClient(t, &down, cfg)
+	ctx := userCtx(testUserUID)
+	known := CheckItem{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "reader"}
+
+	if _, err := c.BatchCheckPermission(ctx, []CheckItem{known}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	down.Store(true)
+	results, err := c.BatchCheckPermission(ctx, []CheckItem{known})
+	if err != nil || !results[known] {
+		t.Fatalf("expected the stale decision, got %v, %v", results, err)
+	}
+
+	// The decision seen by CheckPermission is shared with the batch.
+	if allowed, err := c.CheckPermission(ctx, "pipeline", testObjectUID, "reader"); err != nil || !allowed {
+		t.Errorf("expected the stale decision, got %v, %v", allowed, err)
+	}
+
+	// A batch mixing a decision never fetched fails closed.
+	unknown := CheckItem{ObjectType: "pipeline", ObjectUID: uuid.Must(uuid.NewV4()), Role: "reader"}
+	if _, err := c.BatchCheckPermission(ctx, []CheckItem{known, unknown}); !errors.Is(err, errorsx.ErrUnavailable) {
+		t.Errorf("expected an unavailable error, got %v", err)
+	}
+}
+
+func TestFailPolicy_FailsClosedByDefault(t *testing.T) {
+	var down atomic.Bool
+	cfg := testResilienceConfig()
+	cfg.MaxRetries = -1
+	cfg.FailPolicies = map[string]FailPolicy{"pipeline": {Mode: FailModeStale}}
+	c := newResilientTestClient(t, &down, cfg)
+	ctx := userCtx(testUserUID)
+
+	if _, err := c.CheckPermission(ctx, "model", testObjectUID, "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	down.Store(true)
+	allowed, err := c.CheckPermission(ctx, "model", testObjectUID, "reader")
+	if allowed || errorsx.ConvertGRPCCode(err) != codes.Unavailable {
+		t.Errorf("expected a denied check mapped to Unavailable, got %v, %v", allowed, err)
+	}
+}
@@ -0,0 +1,181 @@
+package acl
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// Schema lists the object types of an authorization model and the
+// relations each of them defines. The client validates the object
+// types and relations it is given against the schema of its model, so
+// that a misspelt name fails before reaching OpenFGA, with a suggestion
+// when it is close to a valid one.
+type Schema struct {
+	relations map[string][]string
+	rewrites  map[string]map[string]*openfga.Userset
+}
+
+// NewSchema returns the schema of model.
+func NewSchema(model *openfga.AuthorizationModel) *Schema {
+	s := &Schema{relations: map[string][]string{}, rewrites: map[string]map[string]*openfga.Userset{}}
+	for _, td := range model.GetTypeDefinitions() {
+		s.relations[td.GetType()] = slices.Sorted(maps.Keys(td.GetRelations()))
+		s.rewrites[td.GetType()] = td.GetRelations()
+	}
+	return s
+}
+
+// ObjectTypes returns the object types of the model, sorted.
+func (s *Schema) ObjectTypes() []string {
+	return slices.Sorted(maps.Keys(s.relations))
+}
+
+// Relations returns the relations of objectType, sorted, or nil when
+// the model has no such type.
+func (s *Schema) Relations(objectType string) []string {
+	return slices.Clone(s.relations[objectType])
+}
+
+// Validate checks that the model defines objectType and each of
+// relations on it. The error wraps errorsx.ErrInvalidArgument.
+func (s *Schema) Validate(objectType string, relations ...string) error {
+	defined, ok := s.relations[objectType]
+	if !ok {
+		return fmt.Errorf("%w: unknown object type %q%s",
+			errorsx.ErrInvalidArgument, objectType, suggest(objectType, s.ObjectTypes()))
+	}
+	for _, relation := range relations {
+		if !slices.Contains(defined, relation) {
+			return fmt.Errorf("%w: unknown relation %q on object type %q%s",
+				errorsx.ErrInvalidArgument, relation, objectType, suggest(relation, defined))
+		}
+	}
+	return nil
+}
+
+// grantingRelations returns the relations of objectType whose direct
+// tuples grant relation, following computed relations and unions, e.g.
+// reader, writer, admin and owner when reader is defined as
+// "[user] or writer" and writer as "[user] or admin", and so on. It
+// reports false when relation is also granted through a rewrite direct
+// tuples can't account for, such as a tuple-to-userset
+// ("reader from parent"), an intersection or an exclusion.
+func (s *Schema) grantingRelations(objectType, relation string) ([]string, bool) {
+	var relations []string
+	seen := map[string]bool{}
+	var walk func(relation string) bool
+	var visit func(u *openfga.Userset, relation string) bool
+	walk = func(relation string) bool {
+		if seen[relation] {
+			return true
+		}
+		seen[relation] = true
+		rewrite, ok := s.rewrites[objectType][relation]
+		return ok && visit(rewrite, relation)
+	}
+	visit = func(u *openfga.Userset, relation string) bool {
+		switch {
+		case u.GetThis() != nil:
+			relations = append(relations, relation)
+			return true
+		case u.GetComputedUserset() != nil:
+			return walk(u.GetComputedUserset().GetRelation())
+		case u.GetUnion() != nil:
+			complete := true
+			for _, child := range u.GetUnion().GetChild() {
+				complete = visit(child, relation) && complete
+			}
+			return complete
+		default:
+			return false
+		}
+	}
+	complete := walk(relation)
+	return relations, complete
+}
+
+// validateSubjectType checks a subject type of ListSubjects, e.g.
+// "user" or "group#member".
+func (s *Schema) validateSubjectType(subjectType string) error {
+	objectType, relation, isUserset := strings.Cut(subjectType, "#")
+	if !isUserset {
+		return s.Validate(objectType)
+	}
+	return s.Validate(objectType, relation)
+}
+
+// suggest returns a ", did you mean ...?" hint naming the candidate
+// closest to name, or "" when none is close enough to be a typo.
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", 0
+	for _, candidate := range candidates {
+		d := editDistance(normalizeName(name), normalizeName(candidate))
+		if best == "" || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	// Allow roughly one typo per four characters.
+	if best == "" || bestDistance > max(1, len(name)/4) {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// normalizeName drops the case and separators, so that
+// "knowledge_base" matches "knowledgebase".
+func normalizeName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// Schema returns the schema of the authorization model the client
+// uses, or nil when argument validation is disabled
+// (ModelConfig.SkipValidation) or the model defines no types.
+func (c *ACLClient) Schema() *Schema {
+	return c.schema.Load()
+}
+
+// setSchema replaces the schema the arguments are validated against.
+func (c *ACLClient) setSchema(model *openfga.AuthorizationModel) {
+	if c.config.Model.SkipValidation || len(model.GetTypeDefinitions()) == 0 {
+		c.schema.Store(nil)
+		return
+	}
+	c.schema.Store(NewSchema(model))
+}
+
+// validate checks objectType and relations against the schema, if
+// any.
+func (c *ACLClient) validate(objectType string, relations ...string) error {
+	s := c.schema.Load()
+	if s == nil {
+		return nil
+	}
+	return s.Validate(objectType, relations...)
+}
@@ -0,0 +1,200 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+const schemaTestDSL = `model
+  schema 1.1
+
+type user
+
+type organization
+  relations
+    define member: [user]
+
+type knowledgebase
+  relations
+    define owner: [user, organization]
+    define reader: [user, user:*] or owner
+
+type pipeline
+  relations
+    define owner: [user, organization]
+    define reader: [user, user:*] or owner
+    define executor: [user, user:*] or owner
+`
+
+func testSchemaModel(t *testing.T) *openfga.AuthorizationModel {
+	t.Helper()
+	model, err := ParseModelDSL(schemaTestDSL)
+	if err != nil {
+		t.Fatalf("parsing model: %v", err)
+	}
+	model.Id = testModelID
+	return model
+}
+
+// ============================================================
+// Schema
+// ============================================================
+
+func TestSchema_Enumerates(t *testing.T) {
+	s := NewSchema(testSchemaModel(t))
+
+	if got := s.ObjectTypes(); !slices.Equal(got, []string{"knowledgebase", "organization", "pipeline", "user"}) {
+		t.Errorf("unexpected object types %v", got)
+	}
+	if got := s.Relations("pipeline"); !slices.Equal(got, []string{"executor", "owner", "reader"}) {
+		t.Errorf("unexpected relations %v", got)
+	}
+	if got := s.Relations("dataset"); got != nil {
+		t.Errorf("expected no relations for an unknown type, got %v", got)
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := NewSchema(testSchemaModel(t))
+
+	testcases := []struct {
+		name       string
+		objectType string
+		relations  []string
+		wantErr    string
+	}{
+		{name: "valid", objectType: "pipeline", relations: []string{"reader", "owner"}},
+		{name: "type only", objectType: "user"},
+		{name: "separator typo", objectType: "knowledge_base", wantErr: `did you mean "knowledgebase"?`},
+		{name: "relation typo", objectType: "pipeline", relations: []string{"reder"}, wantErr: `unknown relation "reder" on object type "pipeline", did you mean "reader"?`},
+		{name: "no near miss", objectType: "dataset", wantErr: `unknown object type "dataset"`},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.Validate(tc.objectType, tc.relations...)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, errorsx.ErrInvalidArgument) || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected an invalid argument error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	if err := s.Validate("dataset"); strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("a far-off name should get no suggestion, got %v", err)
+	}
+}
+
+// ============================================================
+// Client argument validation
+// ============================================================
+
+func newSchemaTestClient(t *testing.T, fga *mockFGA) *ACLClient {
+	t.Helper()
+	c := newTestClient(fga)
+	c.setSchema(testSchemaModel(t))
+	return c
+}
+
+func TestValidation_RejectsBeforeCallingOpenFGA(t *testing.T) {
+	c := newSchemaTestClient(t, &mockFGA{
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			t.Error("OpenFGA should not be called")
+			return &openfga.CheckResponse{}, nil
+		},
+		writeFn: func(context.Context, *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			t.Error("OpenFGA should not be called")
+			return &openfga.WriteResponse{}, nil
+		},
+	})
+	ctx := userCtx(testUserUID)
+
+	if _, err := c.CheckPermission(ctx, "knowledge_base", testObjectUID, "reader"); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("CheckPermission: expected an invalid argument, got %v", err)
+	}
+	if err := c.SetResourcePermission(ctx, "pipeline", testObjectUID, "user:bob", "writer", true); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("SetResourcePermission: expected an invalid argument, got %v", err)
+	}
+	if _, err := c.BatchCheckPermission(ctx, []CheckItem{{ObjectType: "pipeline", ObjectUID: testObjectUID, Role: "raeder"}}); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("BatchCheckPermission: expected an invalid argument, got %v", err)
+	}
+	if _, err := c.ListSubjects(ctx, "pipeline", testObjectUID, "reader", []string{"organization#membr"}); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("ListSubjects: expected an invalid argument, got %v", err)
+	}
+	err := c.Begin().Grant("pipeline", testObjectUID, "user:bob", "reader").Grant("pipelines", testObjectUID, "user:bob", "reader").Commit(ctx)
+	if !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("Commit: expected an invalid argument, got %v", err)
+	}
+}
+
+func TestValidation_DisabledWithoutTypes(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+	c.setSchema(&openfga.AuthorizationModel{Id: testModelID})
+	if c.Schema() != nil {
+		t.Fatal("a model without types should disable validation")
+	}
+	if _, err := c.CheckPermission(userCtx(testUserUID), "anything", testObjectUID, "reader"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	c.config.Model.SkipValidation = true
+	c.setSchema(testSchemaModel(t))
+	if c.Schema() != nil {
+		t.Error("SkipValidation should disable validation")
+	}
+}
+
+func TestValidation_SchemaFollowsModelRefresh(t *testing.T) {
+	model := testSchemaModel(t)
+	model.Id = "model-next"
+	c := newTestClient(&mockFGA{
+		readModelsFn: func(context.Context, *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+			return &openfga.ReadAuthorizationModelsResponse{AuthorizationModels: []*openfga.AuthorizationModel{model}}, nil
+		},
+	})
+
+	c.refreshModel(context.Background())
+	if c.GetModelID() != "model-next" || c.Schema() == nil {
+		t.Fatalf("expected the refreshed model and schema, got %s, %v", c.GetModelID(), c.Schema())
+	}
+	if err := c.validate("pipeline", "executor"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// ============================================================
+// TypedClient
+// ============================================================
+
+func TestTypedClient(t *testing.T) {
+	var got *openfga.CheckRequestTupleKey
+	c := newSchemaTestClient(t, &mockFGA{
+		checkFn: func(_ context.Context, req *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			got = req.TupleKey
+			return &openfga.CheckResponse{Allowed: true}, nil
+		},
+	})
+
+	allowed, err := c.Typed().CheckPermission(userCtx(testUserUID), ObjectTypeKnowledgeBase, testObjectUID, RoleReader)
+	if err != nil || !allowed {
+		t.Fatalf("unexpected result %v, %v", allowed, err)
+	}
+	if got.Object != "knowledgebase:"+testObjectUID.String() || got.Relation != "reader" {
+		t.Errorf("unexpected tuple %v", got)
+	}
+
+	if _, err := c.Typed().CheckPermission(userCtx(testUserUID), ObjectTypeModel, testObjectUID, RoleReader); !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("a type missing from the model should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package acl
+
+import (
+	"context"
+	"strings"
+
+	"github.com/instill-ai/x/constant"
+	"github.com/instill-ai/x/resource"
+)
+
+// Machine subject types. Backends calling each other authenticate as a
+// service account, or with an API token that isn't bound to a user,
+// instead of forwarding a user UID. Their grants are tuples keyed to
+// "serviceaccount:<uid>" and "apitoken:<uid>", so the model must define
+// these types for them to hold any relation.
+const (
+	SubjectTypeServiceAccount = "serviceaccount"
+	SubjectTypeAPIToken       = "apitoken"
+)
+
+// machineSubjectHeaders maps the Instill-Auth-Type of machine subjects
+// to the header carrying their UID.
+var machineSubjectHeaders = map[string]string{
+	SubjectTypeServiceAccount: constant.HeaderServiceAccountUIDKey,
+	SubjectTypeAPIToken:       constant.HeaderAPITokenUIDKey,
+}
+
+// isMachineSubject reports whether userType is a service account or an
+// API token.
+func isMachineSubject(userType string) bool {
+	_, ok := machineSubjectHeaders[userType]
+	return ok
+}
+
+// RequestScopes returns the scopes of the service account or API token
+// of the request, read from the Instill-Scopes header.
+//
+// A scope is "<objectType>:<relation>", where either side may be "*",
+// or "*" alone. Scopes restrict what a machine subject may do on top of
+// its grants: a check outside of them is denied without reaching
+// OpenFGA. A machine subject without scopes is denied everything.
+func RequestScopes(ctx context.Context) []string {
+	header := resource.GetRequestSingleHeader(ctx, constant.HeaderScopesKey)
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// scopeAllows reports whether one of scopes covers relation on
+// objectType.
+func scopeAllows(scopes []string, objectType, relation string) bool {
+	for _, scope := range scopes {
+		if scope == "*" {
+			return true
+		}
+		scopeType, scopeRelation, ok := strings.Cut(scope, ":")
+		if !ok {
+			continue
+		}
+		if (scopeType == "*" || scopeType == objectType) && (scopeRelation == "*" || scopeRelation == relation) {
+			return true
+		}
+	}
+	return false
+}
+
+// outOfScope reports whether the request subject of type userType is a
+// machine subject whose scopes exclude relation on objectType.
+func outOfScope(ctx context.Context, userType, objectType, relation string) bool {
+	return isMachineSubject(userType) && !scopeAllows(RequestScopes(ctx), objectType, relation)
+}
@@ -0,0 +1,230 @@
+package acl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+	logx "github.com/instill-ai/x/log"
+)
+
+// shareLinkType is the FGA type of share-link subjects.
+const shareLinkType = "share_link"
+
+// shareLinkTokenBytes is the entropy of a share-link token.
+const shareLinkTokenBytes = 32
+
+// ShareLink is a capability token granting a relation on an object to
+// whoever presents it, checked with CheckShareLinkPermission.
+type ShareLink struct {
+	// Token is the secret carried by the link. It is the ID of the
+	// "share_link:<token>" subject.
+	Token      string
+	ObjectType string
+	ObjectUID  uuid.UUID
+	Relation   string
+	// ExpiresAt is the expiry of the link, or the zero time for a link
+	// that doesn't expire.
+	ExpiresAt time.Time
+}
+
+// Subject returns the FGA subject of the link, "share_link:<token>".
+func (l ShareLink) Subject() string {
+	return shareLinkType + ":" + l.Token
+}
+
+// newShareLinkToken returns a random, URL-safe share-link token.
+func newShareLinkToken() (string, error) {
+	b := make([]byte, shareLinkTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating share link token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateShareLink mints a share link granting relation on the object.
+// A non-zero expiry writes the grant with the ExpiryConditionName
+// condition, like GrantUntil, so the link stops working at expiry.
+func (c *ACLClient) CreateShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, expiry time.Time) (*ShareLink, error) {
+	if !expiry.IsZero() && !expiry.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expiry %s is in the past", errorsx.ErrInvalidArgument, expiry.Format(time.RFC3339))
+	}
+	if err := c.validate(objectType, relation); err != nil {
+		return nil, err
+	}
+
+	token, err := newShareLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	link := &ShareLink{Token: token, ObjectType: objectType, ObjectUID: objectUID, Relation: relation, ExpiresAt: expiry}
+
+	write, err := link.tupleKey()
+	if err != nil {
+		return nil, err
+	}
+	writes := []*openfga.TupleKey{write}
+	err = c.writeTuples(ctx, writes, nil)
+	c.audit(ctx, AuditCreateShareLink, relation, nil, writes, nil, err)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidateGrant(ctx, objectType, objectUID, link.Subject())
+
+	return link, nil
+}
+
+// ListShareLinks returns the share links granting a relation on the
+// object, expired ones included.
+func (c *ACLClient) ListShareLinks(ctx context.Context, objectType string, objectUID uuid.UUID) ([]ShareLink, error) {
+	if err := c.validate(objectType); err != nil {
+		return nil, err
+	}
+
+	// The Read API filters users by full ID only, so the object's tuples
+	// are read and filtered on the subject type.
+	tuples, err := c.ReadTuples(ctx, ReadTupleFilter{Object: fmt.Sprintf("%s:%s", objectType, objectUID.String())})
+	if err != nil {
+		return nil, err
+	}
+
+	var links []ShareLink
+	for _, t := range tuples {
+		token, ok := strings.CutPrefix(t.User, shareLinkType+":")
+		if !ok {
+			continue
+		}
+		links = append(links, ShareLink{
+			Token:      token,
+			ObjectType: objectType,
+			ObjectUID:  objectUID,
+			Relation:   t.Relation,
+			ExpiresAt:  t.ExpiresAt,
+		})
+	}
+	return links, nil
+}
+
+// RevokeShareLink deletes every grant of the share link token on the
+// object in a single Write request. Revoking an unknown token is not an
+// error.
+func (c *ACLClient) RevokeShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, token string) error {
+	tuples, err := c.readShareLinkTuples(ctx, objectType, objectUID, token)
+	if err != nil {
+		return err
+	}
+	if len(tuples) == 0 {
+		return nil
+	}
+
+	deletes := make([]*openfga.TupleKeyWithoutCondition, len(tuples))
+	for i, t := range tuples {
+		deletes[i] = &openfga.TupleKeyWithoutCondition{User: t.User, Relation: t.Relation, Object: t.Object}
+	}
+	err = c.writeTuples(ctx, nil, deletes)
+	c.audit(ctx, AuditRevokeShareLink, "", tuples, nil, deletes, err)
+
+	c.invalidateGrant(ctx, objectType, objectUID, shareLinkType+":"+token)
+
+	return err
+}
+
+// RotateShareLink replaces the share link token on the object with a
+// new one holding the same relations and expiry, and returns the new
+// links. The old grants are deleted and the new ones written in a
+// single Write request, so there is no window where neither or both
+// tokens work. Rotating an unknown token returns errorsx.ErrNotFound.
+func (c *ACLClient) RotateShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, token string) ([]ShareLink, error) {
+	log, _ := logx.GetZapLogger(ctx)
+
+	tuples, err := c.readShareLinkTuples(ctx, objectType, objectUID, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(tuples) == 0 {
+		return nil, fmt.Errorf("%w: share link on %s:%s", errorsx.ErrNotFound, objectType, objectUID)
+	}
+
+	newToken, err := newShareLinkToken()
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]ShareLink, 0, len(tuples))
+	writes := make([]*openfga.TupleKey, 0, len(tuples))
+	deletes := make([]*openfga.TupleKeyWithoutCondition, 0, len(tuples))
+	for _, t := range tuples {
+		// Only the expiry condition can be carried over: the context of
+		// other conditions isn't exposed by ReadTuples.
+		if t.Condition != "" && t.Condition != ExpiryConditionName {
+			return nil, fmt.Errorf("%w: share link holds %s under condition %s, which can't be rotated", errorsx.ErrInvalidArgument, t.Relation, t.Condition)
+		}
+		link := ShareLink{Token: newToken, ObjectType: objectType, ObjectUID: objectUID, Relation: t.Relation, ExpiresAt: t.ExpiresAt}
+		write, err := link.tupleKey()
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+		writes = append(writes, write)
+		deletes = append(deletes, &openfga.TupleKeyWithoutCondition{User: t.User, Relation: t.Relation, Object: t.Object})
+	}
+
+	err = c.writeTuples(ctx, writes, deletes)
+	c.audit(ctx, AuditRotateShareLink, "", tuples, writes, deletes, err)
+
+	c.invalidateGrant(ctx, objectType, objectUID, shareLinkType+":"+token)
+	c.invalidateGrant(ctx, objectType, objectUID, links[0].Subject())
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("RotateShareLink",
+		zap.String("object", fmt.Sprintf("%s:%s", objectType, objectUID.String())),
+		zap.Int("relations", len(links)),
+	)
+
+	return links, nil
+}
+
+// readShareLinkTuples returns the grants of the share link token on the
+// object.
+func (c *ACLClient) readShareLinkTuples(ctx context.Context, objectType string, objectUID uuid.UUID, token string) ([]ReadTuple, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: share link token is empty", errorsx.ErrInvalidArgument)
+	}
+	if err := c.validate(objectType); err != nil {
+		return nil, err
+	}
+	return c.ReadTuples(ctx, ReadTupleFilter{
+		Object: fmt.Sprintf("%s:%s", objectType, objectUID.String()),
+		User:   shareLinkType + ":" + token,
+	})
+}
+
+// tupleKey returns the tuple granting the link's relation.
+func (l ShareLink) tupleKey() (*openfga.TupleKey, error) {
+	key := &openfga.TupleKey{
+		User:     l.Subject(),
+		Relation: l.Relation,
+		Object:   fmt.Sprintf("%s:%s", l.ObjectType, l.ObjectUID.String()),
+	}
+	if !l.ExpiresAt.IsZero() {
+		condition, err := ExpiresAt(l.ExpiresAt).toFGA()
+		if err != nil {
+			return nil, err
+		}
+		key.Condition = condition
+	}
+	return key, nil
+}
@@ -0,0 +1,178 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// tupleStore is an in-memory tuple set backing the Read and Write mocks
+// of the share-link tests.
+type tupleStore struct {
+	tuples []*openfga.TupleKey
+	writes int
+}
+
+func (s *tupleStore) read(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+	var out []*openfga.Tuple
+	for _, k := range s.tuples {
+		if k.Object == req.TupleKey.Object && (req.TupleKey.User == "" || k.User == req.TupleKey.User) {
+			out = append(out, &openfga.Tuple{Key: k})
+		}
+	}
+	return &openfga.ReadResponse{Tuples: out}, nil
+}
+
+func (s *tupleStore) write(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+	s.writes++
+	for _, d := range req.Deletes.GetTupleKeys() {
+		for i, k := range s.tuples {
+			if k.Object == d.Object && k.Relation == d.Relation && k.User == d.User {
+				s.tuples = append(s.tuples[:i], s.tuples[i+1:]...)
+				break
+			}
+		}
+	}
+	s.tuples = append(s.tuples, req.Writes.GetTupleKeys()...)
+	return &openfga.WriteResponse{}, nil
+}
+
+func newShareLinkClient(store *tupleStore) *ACLClient {
+	return newTestClient(&mockFGA{readFn: store.read, writeFn: store.write})
+}
+
+// ============================================================
+// Share links — create / list / revoke / rotate
+// ============================================================
+
+func TestCreateShareLink_WritesShareLinkTuple(t *testing.T) {
+	store := &tupleStore{}
+	c := newShareLinkClient(store)
+	ctx := context.Background()
+
+	link, err := c.CreateShareLink(ctx, "collection", testObjectUID, "viewer", time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := c.CreateShareLink(ctx, "collection", testObjectUID, "viewer", time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(link.Token) < 40 || link.Token == other.Token {
+		t.Errorf("tokens should be long and random, got %q and %q", link.Token, other.Token)
+	}
+	if len(store.tuples) != 2 || store.tuples[0].User != "share_link:"+link.Token || store.tuples[0].Relation != "viewer" {
+		t.Errorf("unexpected tuples %v", store.tuples)
+	}
+	if store.tuples[0].Condition != nil {
+		t.Error("a link without expiry should be unconditional")
+	}
+}
+
+func TestListShareLinks_ReturnsOnlyShareLinks(t *testing.T) {
+	store := &tupleStore{tuples: []*openfga.TupleKey{
+		{Object: "collection:" + testObjectUID.String(), Relation: "owner", User: "user:" + testUserUID},
+	}}
+	c := newShareLinkClient(store)
+	expiry := time.Now().Add(time.Hour).UTC()
+
+	link, err := c.CreateShareLink(context.Background(), "collection", testObjectUID, "viewer", expiry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	links, err := c.ListShareLinks(context.Background(), "collection", testObjectUID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(links) != 1 || links[0].Token != link.Token || !links[0].ExpiresAt.Equal(expiry) {
+		t.Errorf("expected the expiring link %+v, got %+v", link, links)
+	}
+}
+
+func TestRevokeShareLink_DeletesEveryRelation(t *testing.T) {
+	object := "collection:" + testObjectUID.String()
+	store := &tupleStore{tuples: []*openfga.TupleKey{
+		{Object: object, Relation: "viewer", User: "share_link:tok"},
+		{Object: object, Relation: "commenter", User: "share_link:tok"},
+		{Object: object, Relation: "viewer", User: "share_link:other"},
+	}}
+	c := newShareLinkClient(store)
+
+	if err := c.RevokeShareLink(context.Background(), "collection", testObjectUID, "tok"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.writes != 1 || len(store.tuples) != 1 || store.tuples[0].User != "share_link:other" {
+		t.Errorf("expected a single write leaving the other link, got %d writes / %v", store.writes, store.tuples)
+	}
+
+	if err := c.RevokeShareLink(context.Background(), "collection", testObjectUID, "tok"); err != nil || store.writes != 1 {
+		t.Errorf("revoking an unknown token should be a no-op, got %v", err)
+	}
+}
+
+func TestRotateShareLink_SwapsTokenInSingleWrite(t *testing.T) {
+	store := &tupleStore{}
+	c := newShareLinkClient(store)
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour).UTC()
+
+	link, err := c.CreateShareLink(ctx, "collection", testObjectUID, "viewer", expiry)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.writes = 0
+
+	rotated, err := c.RotateShareLink(ctx, "collection", testObjectUID, link.Token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.writes != 1 {
+		t.Errorf("rotation should be a single Write request, got %d", store.writes)
+	}
+	if len(rotated) != 1 || rotated[0].Token == link.Token || !rotated[0].ExpiresAt.Equal(expiry) {
+		t.Fatalf("expected a new token with the same expiry, got %+v", rotated)
+	}
+	if len(store.tuples) != 1 || store.tuples[0].User != rotated[0].Subject() || store.tuples[0].Condition.GetName() != ExpiryConditionName {
+		t.Errorf("only the new token should remain, with its expiry, got %v", store.tuples)
+	}
+}
+
+func TestRotateShareLink_UnknownToken(t *testing.T) {
+	c := newShareLinkClient(&tupleStore{})
+
+	if _, err := c.RotateShareLink(context.Background(), "collection", testObjectUID, "missing"); !errors.Is(err, errorsx.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCreateShareLink_InvalidatesObjectCache(t *testing.T) {
+	store := &tupleStore{}
+	var checks int
+	c, mr := newTestClientWithCache(&mockFGA{
+		readFn:  store.read,
+		writeFn: store.write,
+		checkFn: func(context.Context, *openfga.CheckRequest) (*openfga.CheckResponse, error) {
+			checks++
+			return &openfga.CheckResponse{Allowed: checks > 1}, nil
+		},
+	})
+	defer mr.Close()
+	ctx := userCtx(testUserUID)
+
+	if allowed, _ := c.CheckPermission(ctx, "collection", testObjectUID, "viewer"); allowed {
+		t.Fatal("first check should be denied")
+	}
+	if _, err := c.CreateShareLink(ctx, "collection", testObjectUID, "viewer", time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed, _ := c.CheckPermission(ctx, "collection", testObjectUID, "viewer"); !allowed || checks != 2 {
+		t.Errorf("the cached denial should be invalidated, checks=%d allowed=%v", checks, allowed)
+	}
+}
@@ -0,0 +1,156 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// listStoresPageSize is the page size used to enumerate OpenFGA stores.
+const listStoresPageSize = 100
+
+// discoverStore returns the ID of the store selected by Config.Store:
+// the store with the configured ID or name, or the single store of the
+// server when neither is set. With Store.Bootstrap, a missing named
+// store is created.
+func (c *ACLClient) discoverStore(ctx context.Context) (string, error) {
+	storeCfg := c.config.Store
+
+	if storeCfg.ID != "" {
+		storeResp, err := c.writeClient.GetStore(ctx, &openfga.GetStoreRequest{StoreId: storeCfg.ID})
+		if err != nil {
+			return "", fmt.Errorf("failed to get OpenFGA store %s: %w", storeCfg.ID, err)
+		}
+		return storeResp.Id, nil
+	}
+
+	stores, err := c.listStores(ctx, storeCfg.Name)
+	if err != nil {
+		return "", err
+	}
+
+	if storeCfg.Name == "" {
+		switch len(stores) {
+		case 0:
+			return "", errors.New("no OpenFGA store found - mgmt-backend migration must run first to create the store")
+		case 1:
+			return stores[0].Id, nil
+		default:
+			return "", &permanentError{fmt.Errorf("multiple OpenFGA stores found (%d) - set Store.ID or Store.Name to select one", len(stores))}
+		}
+	}
+
+	switch len(stores) {
+	case 0:
+		if storeCfg.Bootstrap {
+			return c.bootstrapStore(ctx, storeCfg.Name)
+		}
+		return "", fmt.Errorf("no OpenFGA store named %q found", storeCfg.Name)
+	case 1:
+		return stores[0].Id, nil
+	default:
+		return "", &permanentError{fmt.Errorf("multiple OpenFGA stores named %q found (%d) - set Store.ID to select one", storeCfg.Name, len(stores))}
+	}
+}
+
+// listStores returns every store of the server, or only those named
+// name when it is not empty.
+func (c *ACLClient) listStores(ctx context.Context, name string) ([]*openfga.Store, error) {
+	var stores []*openfga.Store
+	var token string
+	for {
+		resp, err := c.writeClient.ListStores(ctx, &openfga.ListStoresRequest{
+			PageSize:          wrapperspb.Int32(listStoresPageSize),
+			ContinuationToken: token,
+			Name:              name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list OpenFGA stores: %w", err)
+		}
+		for _, store := range resp.Stores {
+			// Servers predating the name filter ignore it.
+			if name == "" || store.Name == name {
+				stores = append(stores, store)
+			}
+		}
+		if resp.ContinuationToken == "" {
+			return stores, nil
+		}
+		token = resp.ContinuationToken
+	}
+}
+
+// bootstrapStore creates the store named name.
+func (c *ACLClient) bootstrapStore(ctx context.Context, name string) (string, error) {
+	resp, err := c.writeClient.CreateStore(ctx, &openfga.CreateStoreRequest{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to create OpenFGA store %q: %w", name, err)
+	}
+
+	log, _ := logx.GetZapLogger(ctx)
+	log.Info("OpenFGA store created", zap.String("storeName", name), zap.String("storeID", resp.Id))
+	return resp.Id, nil
+}
+
+// bootstrapModel writes the authorization model of Store.ModelFile to
+// the store.
+func (c *ACLClient) bootstrapModel(ctx context.Context) (*openfga.AuthorizationModel, error) {
+	model, err := LoadAuthorizationModel(c.config.Store.ModelFile)
+	if err != nil {
+		return nil, &permanentError{err}
+	}
+
+	resp, err := c.writeClient.WriteAuthorizationModel(ctx, &openfga.WriteAuthorizationModelRequest{
+		StoreId:         c.storeID,
+		TypeDefinitions: model.TypeDefinitions,
+		SchemaVersion:   model.SchemaVersion,
+		Conditions:      model.Conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write OpenFGA authorization model: %w", err)
+	}
+
+	log, _ := logx.GetZapLogger(ctx)
+	log.Info("OpenFGA authorization model written",
+		zap.String("storeID", c.storeID),
+		zap.String("modelID", resp.AuthorizationModelId),
+		zap.String("modelFile", c.config.Store.ModelFile))
+	model.Id = resp.AuthorizationModelId
+	return model, nil
+}
+
+// LoadAuthorizationModel reads an authorization model from path. Files
+// ending in .json hold the JSON form of the model (as produced by
+// `fga model transform`); any other file is parsed as the OpenFGA DSL
+// (see ParseModelDSL).
+func LoadAuthorizationModel(path string) (*openfga.AuthorizationModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization model: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		model := &openfga.AuthorizationModel{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, model); err != nil {
+			return nil, fmt.Errorf("failed to parse authorization model %s: %w", path, err)
+		}
+		return model, nil
+	}
+
+	model, err := ParseModelDSL(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse authorization model %s: %w", path, err)
+	}
+	return model, nil
+}
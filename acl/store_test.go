@@ -0,0 +1,150 @@
+package acl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+func staticModels(id string) func(context.Context, *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+	return func(context.Context, *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+		return modelsResponse(id), nil
+	}
+}
+
+// ============================================================
+// Store selection
+// ============================================================
+
+func TestDiscoverStore_ByNameAcrossPages(t *testing.T) {
+	var requests []*openfga.ListStoresRequest
+	fga := &mockFGA{
+		// Behaves like a server without the name filter.
+		listStoresFn: func(_ context.Context, req *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			requests = append(requests, req)
+			if req.ContinuationToken == "" {
+				return &openfga.ListStoresResponse{
+					Stores:            []*openfga.Store{{Id: "store-dev", Name: "dev"}},
+					ContinuationToken: "page-2",
+				}, nil
+			}
+			return &openfga.ListStoresResponse{Stores: []*openfga.Store{{Id: testStoreID, Name: "staging"}}}, nil
+		},
+		readModelsFn: staticModels(testModelID),
+	}
+
+	c, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{
+		Store: StoreConfig{Name: "staging"},
+		Model: ModelConfig{RefreshInterval: -1},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if c.GetStoreID() != testStoreID {
+		t.Errorf("expected the store named staging, got %s", c.GetStoreID())
+	}
+	if len(requests) != 2 || requests[0].Name != "staging" {
+		t.Errorf("stores should be listed page by page with the name filter, got %v", requests)
+	}
+}
+
+func TestDiscoverStore_ByID(t *testing.T) {
+	fga := &mockFGA{
+		getStoreFn: func(_ context.Context, req *openfga.GetStoreRequest) (*openfga.GetStoreResponse, error) {
+			return &openfga.GetStoreResponse{Id: req.StoreId}, nil
+		},
+		readModelsFn: staticModels(testModelID),
+	}
+
+	c, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{
+		Store: StoreConfig{ID: testStoreID, Name: "ignored"},
+		Model: ModelConfig{RefreshInterval: -1},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if c.GetStoreID() != testStoreID {
+		t.Errorf("expected %s, got %s", testStoreID, c.GetStoreID())
+	}
+}
+
+func TestDiscoverStore_DuplicateNameIsNotRetried(t *testing.T) {
+	fastDiscovery(t)
+	var attempts int
+	fga := &mockFGA{
+		listStoresFn: func(context.Context, *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			attempts++
+			return &openfga.ListStoresResponse{Stores: []*openfga.Store{{Id: "a", Name: "dev"}, {Id: "b", Name: "dev"}}}, nil
+		},
+	}
+
+	if _, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{Store: StoreConfig{Name: "dev"}}); err == nil {
+		t.Fatal("duplicate store names should be reported")
+	}
+	if attempts != 1 {
+		t.Errorf("a configuration problem should not be retried, got %d attempts", attempts)
+	}
+}
+
+// ============================================================
+// Store bootstrap
+// ============================================================
+
+func TestBootstrap_CreatesStoreAndWritesModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.fga")
+	_ = os.WriteFile(path, []byte(testModelDSL), 0o600)
+
+	var created string
+	var written *openfga.WriteAuthorizationModelRequest
+	fga := &mockFGA{
+		listStoresFn: func(context.Context, *openfga.ListStoresRequest) (*openfga.ListStoresResponse, error) {
+			return &openfga.ListStoresResponse{}, nil
+		},
+		createStoreFn: func(_ context.Context, req *openfga.CreateStoreRequest) (*openfga.CreateStoreResponse, error) {
+			created = req.Name
+			return &openfga.CreateStoreResponse{Id: testStoreID, Name: req.Name}, nil
+		},
+		readModelsFn: func(context.Context, *openfga.ReadAuthorizationModelsRequest) (*openfga.ReadAuthorizationModelsResponse, error) {
+			return &openfga.ReadAuthorizationModelsResponse{}, nil
+		},
+		writeModelFn: func(_ context.Context, req *openfga.WriteAuthorizationModelRequest) (*openfga.WriteAuthorizationModelResponse, error) {
+			written = req
+			return &openfga.WriteAuthorizationModelResponse{AuthorizationModelId: testModelID}, nil
+		},
+	}
+
+	c, err := NewClientWithContext(context.Background(), fga, nil, nil, Config{
+		Store: StoreConfig{Name: "integration-test", Bootstrap: true, ModelFile: path},
+		Model: ModelConfig{RefreshInterval: -1},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	if created != "integration-test" {
+		t.Errorf("store should be created, got %q", created)
+	}
+	if written == nil || written.StoreId != testStoreID || len(written.TypeDefinitions) != 3 {
+		t.Fatalf("model should be written to the new store, got %v", written)
+	}
+	if c.GetModelID() != testModelID {
+		t.Errorf("expected the written model, got %s", c.GetModelID())
+	}
+}
+
+func TestBootstrap_RequiresNameAndModelFile(t *testing.T) {
+	_, err := NewClientWithContext(context.Background(), &mockFGA{}, nil, nil, Config{
+		Store: StoreConfig{Bootstrap: true},
+	})
+	if err == nil {
+		t.Fatal("an incomplete bootstrap configuration should be rejected")
+	}
+}
@@ -0,0 +1,227 @@
+package acl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/constant"
+	"github.com/instill-ai/x/resource"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// DefaultSubjectTypes are the subject types ListSubjects returns when
+// the caller passes none.
+var DefaultSubjectTypes = []string{"user", "organization"}
+
+// Subject is a subject that holds a relation on an object, as returned
+// by ListSubjects.
+type Subject struct {
+	// Type is the FGA type of the subject, e.g. "user", "organization",
+	// "group" or "share_link".
+	Type string
+	// ID is the subject ID, e.g. a user UID. It is empty for wildcards.
+	ID string
+	// Relation is set for usersets, e.g. "member" for
+	// "group:<uid>#member".
+	Relation string
+	// Wildcard is set for type-bound public access, e.g. "user:*".
+	Wildcard bool
+}
+
+// String returns the FGA representation of the subject.
+func (s Subject) String() string {
+	switch {
+	case s.Wildcard:
+		return s.Type + ":*"
+	case s.Relation != "":
+		return fmt.Sprintf("%s:%s#%s", s.Type, s.ID, s.Relation)
+	default:
+		return s.Type + ":" + s.ID
+	}
+}
+
+// ParseSubject parses an FGA subject string such as "user:<uid>",
+// "user:*" or "group:<uid>#member".
+func ParseSubject(s string) (Subject, error) {
+	typ, rest, ok := strings.Cut(s, ":")
+	if !ok || typ == "" || rest == "" {
+		return Subject{}, fmt.Errorf("invalid subject %q", s)
+	}
+	if rest == "*" {
+		return Subject{Type: typ, Wildcard: true}, nil
+	}
+	id, relation, _ := strings.Cut(rest, "#")
+	return Subject{Type: typ, ID: id, Relation: relation}, nil
+}
+
+// subjectFromFGA converts a ListUsers result.
+func subjectFromFGA(u *openfga.User) (Subject, bool) {
+	switch {
+	case u.GetObject() != nil:
+		return Subject{Type: u.GetObject().GetType(), ID: u.GetObject().GetId()}, true
+	case u.GetUserset() != nil:
+		us := u.GetUserset()
+		return Subject{Type: us.GetType(), ID: us.GetId(), Relation: us.GetRelation()}, true
+	case u.GetWildcard() != nil:
+		return Subject{Type: u.GetWildcard().GetType(), Wildcard: true}, true
+	default:
+		return Subject{}, false
+	}
+}
+
+// ListSubjects returns every subject of subjectTypes that holds
+// relation on the object, directly or through the model's rewrites.
+// Subject types are FGA types, optionally with a relation to list
+// usersets ("group#member"); DefaultSubjectTypes is used when none are
+// given. Unlike ReadTuples it is backed by OpenFGA ListUsers, so it
+// sees transitive access, but it is more expensive.
+//
+// Consistency follows CheckPermission: a pinned caller or a context
+// carrying ContextKeyForceHigherConsistency reads with
+// HIGHER_CONSISTENCY and bypasses the cache. Results are cached when
+// CacheConfig.ListSubjectsEnabled is set and are invalidated by writes
+// to the object. Changes the object's tuples don't show, i.e. to the
+// memberships of the listed usersets or to the grants of its parents,
+// are only picked up when the entry expires, after CacheConfig.TTL:
+// callers that must see them right away set
+// ContextKeyForceHigherConsistency.
+func (c *ACLClient) ListSubjects(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, subjectTypes []string) ([]Subject, error) {
+	log, _ := logx.GetZapLogger(ctx)
+
+	if len(subjectTypes) == 0 {
+		subjectTypes = DefaultSubjectTypes
+	}
+	if err := c.validate(objectType, relation); err != nil {
+		return nil, err
+	}
+	if s := c.Schema(); s != nil {
+		for _, subjectType := range subjectTypes {
+			if err := s.validateSubjectType(subjectType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	callerUID := resource.GetRequestSingleHeader(ctx, constant.HeaderUserUIDKey)
+	consistency, forceConsistency := c.checkConsistency(ctx, callerUID)
+
+	useCache := c.listSubjectsCacheOn && c.cache != nil && !forceConsistency && !hasCheckContext(ctx)
+	cacheEntries := []*CacheEntry{subjectsCacheEntry(objectType, objectUID.String(), relation, subjectTypes)}
+	if useCache {
+		cached, err := c.cache.Get(ctx, cacheEntries)
+		if err != nil {
+			log.Warn("ListSubjects cache error", zap.Error(err))
+		} else if cached[0] != "" {
+			if subjects, err := decodeSubjects(cached[0]); err == nil {
+				return subjects, nil
+			}
+		}
+	}
+
+	modelID, err := c.getAuthorizationModelID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting authorization model: %w", err)
+	}
+
+	reqContext, err := requestContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// ListUsers accepts a single user filter per request.
+	subjects := []Subject{}
+	seen := map[Subject]struct{}{}
+	for _, subjectType := range subjectTypes {
+		typ, typeRelation, _ := strings.Cut(subjectType, "#")
+		resp, err := c.getClient(ctx, ReadMode).ListUsers(ctx, &openfga.ListUsersRequest{
+			StoreId:              c.storeID,
+			AuthorizationModelId: modelID,
+			Object:               &openfga.Object{Type: objectType, Id: objectUID.String()},
+			Relation:             relation,
+			UserFilters:          []*openfga.UserTypeFilter{{Type: typ, Relation: typeRelation}},
+			Context:              reqContext,
+			Consistency:          consistency,
+		})
+		if err != nil {
+			if statusErr, ok := status.FromError(err); ok {
+				if statusErr.Code() == codes.Code(openfga.ErrorCode_type_not_found) {
+					continue
+				}
+			}
+			return nil, fmt.Errorf("listing %s subjects: %w", subjectType, err)
+		}
+		for _, u := range resp.GetUsers() {
+			subject, ok := subjectFromFGA(u)
+			if !ok {
+				continue
+			}
+			if _, dup := seen[subject]; dup {
+				continue
+			}
+			seen[subject] = struct{}{}
+			subjects = append(subjects, subject)
+		}
+	}
+
+	if useCache {
+		if data, err := encodeSubjects(subjects); err == nil {
+			if err := c.cache.Set(ctx, cacheEntries, []string{data}); err != nil {
+				log.Warn("ListSubjects failed to cache result", zap.Error(err))
+			}
+		}
+	}
+
+	return subjects, nil
+}
+
+// listSubjectsCacheKey generates a cache key for ListSubjects.
+func listSubjectsCacheKey(objectType, objectUID, relation string, subjectTypes []string) string {
+	return fmt.Sprintf("%s%s:%s:%s:%s", ListSubjectsCachePrefix, objectType, objectUID, relation, strings.Join(subjectTypes, ","))
+}
+
+// subjectsCacheEntry returns the cache entry of a ListSubjects result.
+// It depends on the object's tuples, and is tagged with them only:
+// membership and parent changes would have to drop the entries of every
+// object reachable from the changed tuple, which the cache can't
+// enumerate, so they expire instead.
+func subjectsCacheEntry(objectType, objectUID, relation string, subjectTypes []string) *CacheEntry {
+	return &CacheEntry{
+		Key:  listSubjectsCacheKey(objectType, objectUID, relation, subjectTypes),
+		Tags: []string{ObjectCacheTag(objectType, objectUID)},
+	}
+}
+
+func encodeSubjects(subjects []Subject) (string, error) {
+	strs := make([]string, len(subjects))
+	for i, s := range subjects {
+		strs[i] = s.String()
+	}
+	data, err := json.Marshal(strs)
+	return string(data), err
+}
+
+func decodeSubjects(data string) ([]Subject, error) {
+	var strs []string
+	if err := json.Unmarshal([]byte(data), &strs); err != nil {
+		return nil, err
+	}
+	subjects := make([]Subject, 0, len(strs))
+	for _, s := range strs {
+		subject, err := ParseSubject(s)
+		if err != nil {
+			return nil, err
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, nil
+}
@@ -0,0 +1,174 @@
+package acl
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// listUsersByType answers ListUsers with the users registered for the
+// request's single user filter, and counts calls in *calls.
+func listUsersByType(calls *int, users map[string][]*openfga.User) func(context.Context, *openfga.ListUsersRequest) (*openfga.ListUsersResponse, error) {
+	return func(_ context.Context, req *openfga.ListUsersRequest) (*openfga.ListUsersResponse, error) {
+		*calls++
+		f := req.UserFilters[0]
+		key := f.Type
+		if f.Relation != "" {
+			key += "#" + f.Relation
+		}
+		return &openfga.ListUsersResponse{Users: users[key]}, nil
+	}
+}
+
+// ============================================================
+// ListSubjects — ListUsers
+// ============================================================
+
+func TestListSubjects_ReturnsTypedSubjects(t *testing.T) {
+	var calls int
+	var requests []*openfga.ListUsersRequest
+	list := listUsersByType(&calls, map[string][]*openfga.User{
+		"user": {
+			{User: &openfga.User_Object{Object: &openfga.Object{Type: "user", Id: testUserUID}}},
+			{User: &openfga.User_Wildcard{Wildcard: &openfga.TypedWildcard{Type: "user"}}},
+		},
+		"group#member": {
+			{User: &openfga.User_Userset{Userset: &openfga.UsersetUser{Type: "group", Id: testOrgUID, Relation: "member"}}},
+		},
+	})
+	c := newTestClient(&mockFGA{
+		listUsersFn: func(ctx context.Context, req *openfga.ListUsersRequest) (*openfga.ListUsersResponse, error) {
+			requests = append(requests, req)
+			return list(ctx, req)
+		},
+	})
+
+	subjects, err := c.ListSubjects(userCtx(testUserUID), "pipeline", testObjectUID, "reader", []string{"user", "group#member"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Subject{
+		{Type: "user", ID: testUserUID},
+		{Type: "user", Wildcard: true},
+		{Type: "group", ID: testOrgUID, Relation: "member"},
+	}
+	if !slices.Equal(subjects, want) {
+		t.Errorf("expected %v, got %v", want, subjects)
+	}
+	if calls != 2 || requests[0].Object.Id != testObjectUID.String() || requests[0].Relation != "reader" {
+		t.Errorf("expected one ListUsers call per subject type, got %d: %v", calls, requests)
+	}
+	if requests[0].Consistency != openfga.ConsistencyPreference_UNSPECIFIED {
+		t.Errorf("unpinned caller should use the default consistency, got %s", requests[0].Consistency)
+	}
+}
+
+func TestListSubjects_SkipsTypesMissingFromModel(t *testing.T) {
+	c := newTestClient(&mockFGA{
+		listUsersFn: func(_ context.Context, req *openfga.ListUsersRequest) (*openfga.ListUsersResponse, error) {
+			if req.UserFilters[0].Type == "share_link" {
+				return nil, status.Error(codes.Code(openfga.ErrorCode_type_not_found), "type not found")
+			}
+			return &openfga.ListUsersResponse{}, nil
+		},
+	})
+
+	subjects, err := c.ListSubjects(userCtx(testUserUID), "pipeline", testObjectUID, "reader", []string{"user", "share_link"})
+	if err != nil || len(subjects) != 0 {
+		t.Errorf("unknown subject types should be skipped, got %v / %v", subjects, err)
+	}
+}
+
+func TestListSubjects_CachedUntilObjectWrite(t *testing.T) {
+	var calls int
+	c, mr := newTestClientWithCache(&mockFGA{
+		listUsersFn: listUsersByType(&calls, map[string][]*openfga.User{
+			"user": {{User: &openfga.User_Object{Object: &openfga.Object{Type: "user", Id: testUserUID}}}},
+		}),
+	})
+	defer mr.Close()
+	c.listSubjectsCacheOn = true
+	ctx := userCtx(testUserUID)
+
+	for range 2 {
+		subjects, err := c.ListSubjects(ctx, "pipeline", testObjectUID, "reader", nil)
+		if err != nil || len(subjects) != 1 || subjects[0].ID != testUserUID {
+			t.Fatalf("unexpected result: %v / %v", subjects, err)
+		}
+	}
+	if calls != len(DefaultSubjectTypes) {
+		t.Errorf("second call should be served from the cache, got %d calls", calls)
+	}
+
+	c.invalidateObjectCache(ctx, "pipeline", testObjectUID.String())
+	if _, err := c.ListSubjects(ctx, "pipeline", testObjectUID, "reader", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2*len(DefaultSubjectTypes) {
+		t.Errorf("a write to the object should invalidate the entry, got %d calls", calls)
+	}
+}
+
+func TestListSubjects_InvalidatedWithoutCheckCache(t *testing.T) {
+	var calls int
+	c, mr := newTestClientWithCache(&mockFGA{listUsersFn: listUsersByType(&calls, nil)})
+	defer mr.Close()
+	c.cacheEnabled, c.listPermissionsCacheOn, c.listSubjectsCacheOn = false, false, true
+	ctx := userCtx(testUserUID)
+
+	if _, err := c.ListSubjects(ctx, "pipeline", testObjectUID, "reader", []string{"user"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.invalidateObjectCache(ctx, "pipeline", testObjectUID.String())
+	if _, err := c.ListSubjects(ctx, "pipeline", testObjectUID, "reader", []string{"user"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("a write to the object should invalidate the entry, got %d calls", calls)
+	}
+}
+
+func TestListSubjects_PinnedCallerBypassesCache(t *testing.T) {
+	var consistencies []openfga.ConsistencyPreference
+	c, mr := newTestClientWithCache(&mockFGA{
+		listUsersFn: func(_ context.Context, req *openfga.ListUsersRequest) (*openfga.ListUsersResponse, error) {
+			consistencies = append(consistencies, req.Consistency)
+			return &openfga.ListUsersResponse{}, nil
+		},
+	})
+	defer mr.Close()
+	c.listSubjectsCacheOn = true
+	c.config.Replica.ReplicationTimeFrame = 30
+	ctx := userCtx(testUserUID)
+
+	c.PinUserForConsistency(ctx)
+	for range 2 {
+		if _, err := c.ListSubjects(ctx, "pipeline", testObjectUID, "reader", []string{"user"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(consistencies) != 2 || consistencies[1] != openfga.ConsistencyPreference_HIGHER_CONSISTENCY {
+		t.Errorf("pinned caller should bypass the cache with HIGHER_CONSISTENCY, got %v", consistencies)
+	}
+}
+
+func TestParseSubject_RoundTrips(t *testing.T) {
+	for _, s := range []string{"user:" + testUserUID, "user:*", "group:" + testOrgUID + "#member", "share_link:abc"} {
+		subject, err := ParseSubject(s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", s, err)
+		}
+		if subject.String() != s {
+			t.Errorf("expected %s, got %s", s, subject)
+		}
+	}
+	if _, err := ParseSubject("user"); err == nil {
+		t.Error("a subject without an ID should be rejected")
+	}
+}
@@ -0,0 +1,110 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// ErrPossiblyTruncated is returned by ListPermissions and
+// ListPublicPermissions, along with the objects found, and yielded by
+// ListPermissionsIter after them, when a StreamedListObjects result was
+// flagged as likely truncated and ListObjectsConfig.TruncationStrategy
+// could not recover it. Callers can check it with errors.Is and decide
+// whether to degrade to the partial result or fail. ListPermissionsPage
+// returns it without a page, whatever the strategy.
+var ErrPossiblyTruncated = errors.New("acl: list result possibly truncated")
+
+// recoverTruncatedList unions streamed with the direct grants of role
+// on objectType, and of the relations role is inherited from in the
+// model (e.g. owner for reader), held by subject, by the wildcard of
+// its type and by the usersets subject is a direct member of. The error
+// wraps ErrPossiblyTruncated when the fallback could not run to
+// completion or when role is also granted in ways direct grants can't
+// account for; without a schema (ModelConfig.SkipValidation) the model
+// is unknown, so the result is never vouched for.
+func (c *ACLClient) recoverTruncatedList(ctx context.Context, objectType, role, subject string, streamed []uuid.UUID) ([]uuid.UUID, error) {
+	relations, direct := []string{role}, false
+	if s := c.schema.Load(); s != nil {
+		relations, direct = s.grantingRelations(objectType, role)
+	}
+
+	subjects, complete, err := c.fallbackSubjects(ctx, subject)
+	if err != nil {
+		return streamed, fmt.Errorf("%w: expanding memberships: %v", ErrPossiblyTruncated, err)
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(streamed))
+	for _, uid := range streamed {
+		seen[uid] = struct{}{}
+	}
+	objectUIDs := streamed
+	for _, s := range subjects {
+		for _, relation := range relations {
+			tuples, err := c.ReadTuples(ctx, ReadTupleFilter{Object: objectType + ":", Relation: relation, User: s})
+			if err != nil {
+				return objectUIDs, fmt.Errorf("%w: reading direct grants: %v", ErrPossiblyTruncated, err)
+			}
+			for _, t := range tuples {
+				uid := objectUIDFromFGA(t.Object)
+				if _, ok := seen[uid]; ok {
+					continue
+				}
+				seen[uid] = struct{}{}
+				objectUIDs = append(objectUIDs, uid)
+			}
+		}
+	}
+
+	if !complete {
+		return objectUIDs, fmt.Errorf("%w: more than %d subjects to expand", ErrPossiblyTruncated, c.listObjectsCfg.MaxFallbackSubjects)
+	}
+	if !direct {
+		return objectUIDs, fmt.Errorf("%w: %s on %s is not only granted by direct tuples", ErrPossiblyTruncated, role, objectType)
+	}
+	return objectUIDs, nil
+}
+
+// fallbackSubjects returns subject, the wildcard of its type, e.g.
+// "user:*", and the usersets of ListObjectsConfig.MembershipTypes it is
+// a direct member of, e.g. "organization:<uid>#member". It reports false
+// when the list was cut at ListObjectsConfig.MaxFallbackSubjects.
+func (c *ACLClient) fallbackSubjects(ctx context.Context, subject string) ([]string, bool, error) {
+	subjects := []string{subject}
+	if isWildcardSubject(subject) {
+		return subjects, true, nil
+	}
+	subjectType, _, _ := strings.Cut(subject, ":")
+	subjects = append(subjects, subjectType+":*")
+
+	seen := map[string]struct{}{subject: {}, subjectType + ":*": {}}
+	add := func(s string) bool {
+		if _, ok := seen[s]; ok {
+			return true
+		}
+		if len(subjects) >= c.listObjectsCfg.MaxFallbackSubjects {
+			return false
+		}
+		seen[s] = struct{}{}
+		subjects = append(subjects, s)
+		return true
+	}
+
+	for _, membershipType := range c.listObjectsCfg.MembershipTypes {
+		tuples, err := c.ReadTuples(ctx, ReadTupleFilter{Object: membershipType + ":", User: subject})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, t := range tuples {
+			// Grants are usually keyed to the member userset; owners
+			// and admins are members through the model's rewrites.
+			if !add(t.Object+"#member") || !add(t.Object+"#"+t.Relation) {
+				return subjects, false, nil
+			}
+		}
+	}
+	return subjects, true, nil
+}
@@ -0,0 +1,265 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// truncationModel derives reader from admin and admin from owner, the
+// way the standard roles are modelled, and viewer from the parent
+// folder, which direct grants can't account for.
+const truncationModel = `model
+  schema 1.1
+
+type user
+
+type organization
+  relations
+    define owner: [user]
+    define member: [user] or owner
+
+type folder
+  relations
+    define viewer: [user]
+
+type file
+  relations
+    define parent: [folder]
+    define owner: [user, organization#member]
+    define admin: [user, organization#member] or owner
+    define reader: [user, user:*, organization#member] or admin
+    define viewer: [user] or viewer from parent
+`
+
+// truncatingClient returns a client whose StreamedListObjects always
+// returns streamed, which is flagged as truncated by the max-results
+// branch, and whose Read API answers with grants keyed by tuple user.
+// The client validates against truncationModel.
+func truncatingClient(streamed []uuid.UUID, grants map[string][]*openfga.TupleKey, cfg ListObjectsConfig) (*ACLClient, *[]string) {
+	var reads []string
+	fga := &mockFGA{
+		streamedListObjectsFn: objectStream("file", nil, streamed...),
+		readFn: func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			reads = append(reads, req.TupleKey.Object+"|"+req.TupleKey.Relation+"|"+req.TupleKey.User)
+			var tuples []*openfga.Tuple
+			for _, k := range grants[req.TupleKey.User] {
+				if strings.HasPrefix(k.Object, req.TupleKey.Object) &&
+					(req.TupleKey.Relation == "" || k.Relation == req.TupleKey.Relation) {
+					tuples = append(tuples, &openfga.Tuple{Key: k})
+				}
+			}
+			return &openfga.ReadResponse{Tuples: tuples}, nil
+		},
+	}
+	c := newTestClient(fga)
+	model, err := ParseModelDSL(truncationModel)
+	if err != nil {
+		panic(err)
+	}
+	c.setSchema(model)
+	cfg.Deadline = 3 * time.Second
+	cfg.MaxResults = len(streamed)
+	c.listObjectsCfg = cfg.resolved()
+	return c, &reads
+}
+
+// ============================================================
+// Truncation fallback — ReadTuples expansion
+// ============================================================
+
+func TestTruncationFallback_UnionsDirectAndMembershipGrants(t *testing.T) {
+	streamed := testUIDs(2)
+	direct, viaOrg := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	user := "user:" + testUserUID
+	org := "organization:" + testOrgUID
+
+	c, _ := truncatingClient(streamed, map[string][]*openfga.TupleKey{
+		user: {
+			{Object: "file:" + direct.String(), Relation: "reader", User: user},
+			{Object: "file:" + streamed[0].String(), Relation: "reader", User: user},
+			{Object: org, Relation: "member", User: user},
+		},
+		org + "#member": {
+			{Object: "file:" + viaOrg.String(), Relation: "reader", User: org + "#member"},
+		},
+	}, ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples})
+
+	uids, err := c.ListPermissions(userCtx(testUserUID), "file", "reader")
+	if err != nil {
+		t.Fatalf("a complete fallback should not report truncation: %v", err)
+	}
+	want := append(slices.Clone(streamed), direct, viaOrg)
+	if !slices.Equal(uids, want) {
+		t.Errorf("expected the union %v, got %v", want, uids)
+	}
+}
+
+func TestTruncationFallback_RecoversInheritedAndWildcardGrants(t *testing.T) {
+	streamed := testUIDs(1)
+	asAdmin, asOwner, viaOrgOwner, public := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	user := "user:" + testUserUID
+	org := "organization:" + testOrgUID
+
+	c, _ := truncatingClient(streamed, map[string][]*openfga.TupleKey{
+		user: {
+			{Object: "file:" + asAdmin.String(), Relation: "admin", User: user},
+			{Object: "file:" + asOwner.String(), Relation: "owner", User: user},
+			{Object: org, Relation: "owner", User: user},
+		},
+		org + "#member": {
+			{Object: "file:" + viaOrgOwner.String(), Relation: "owner", User: org + "#member"},
+		},
+		"user:*": {
+			{Object: "file:" + public.String(), Relation: "reader", User: "user:*"},
+		},
+	}, ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples})
+
+	uids, err := c.ListPermissions(userCtx(testUserUID), "file", "reader")
+	if err != nil {
+		t.Fatalf("a complete fallback should not report truncation: %v", err)
+	}
+	slices.SortFunc(uids, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	want := []uuid.UUID{streamed[0], asAdmin, asOwner, viaOrgOwner, public}
+	slices.SortFunc(want, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	if !slices.Equal(uids, want) {
+		t.Errorf("expected %v, got %v", want, uids)
+	}
+}
+
+func TestTruncationFallback_ReportsPossiblyTruncatedForIndirectRewrites(t *testing.T) {
+	inFolder := uuid.Must(uuid.NewV4())
+	user := "user:" + testUserUID
+	c, _ := truncatingClient(testUIDs(1), map[string][]*openfga.TupleKey{
+		user: {{Object: "file:" + inFolder.String(), Relation: "viewer", User: user}},
+	}, ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples})
+
+	uids, err := c.ListPermissions(userCtx(testUserUID), "file", "viewer")
+	if !errors.Is(err, ErrPossiblyTruncated) {
+		t.Fatalf("viewer from parent can't be recovered from direct grants, got %v", err)
+	}
+	if !slices.Contains(uids, inFolder) {
+		t.Errorf("the direct grants should still be recovered, got %v", uids)
+	}
+
+	// Without a schema the model is unknown, so nothing is vouched for.
+	c.setSchema(&openfga.AuthorizationModel{})
+	if _, err := c.ListPermissions(userCtx(testUserUID), "file", "reader"); !errors.Is(err, ErrPossiblyTruncated) {
+		t.Errorf("expected ErrPossiblyTruncated without a schema, got %v", err)
+	}
+}
+
+func TestTruncationFallback_ReportsPossiblyTruncatedOnReadError(t *testing.T) {
+	streamed := testUIDs(1)
+	c := newTestClient(&mockFGA{
+		streamedListObjectsFn: objectStream("file", nil, streamed...),
+		readFn: func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return nil, fmt.Errorf("unavailable")
+		},
+	})
+	c.listObjectsCfg = ListObjectsConfig{MaxResults: 1, TruncationStrategy: TruncationStrategyReadTuples}.resolved()
+
+	uids, err := c.ListPermissions(userCtx(testUserUID), "file", "reader")
+	if !errors.Is(err, ErrPossiblyTruncated) {
+		t.Fatalf("expected ErrPossiblyTruncated, got %v", err)
+	}
+	if !slices.Equal(uids, streamed) {
+		t.Errorf("the streamed result should still be returned, got %v", uids)
+	}
+}
+
+func TestTruncationFallback_CapsMembershipFanOut(t *testing.T) {
+	user := "user:" + testUserUID
+	var memberships []*openfga.TupleKey
+	for range 3 {
+		memberships = append(memberships, &openfga.TupleKey{Object: "group:" + uuid.Must(uuid.NewV4()).String(), Relation: "member", User: user})
+	}
+
+	c, _ := truncatingClient(testUIDs(1), map[string][]*openfga.TupleKey{user: memberships},
+		ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples, MembershipTypes: []string{"group"}, MaxFallbackSubjects: 2})
+
+	if _, err := c.ListPermissions(userCtx(testUserUID), "file", "reader"); !errors.Is(err, ErrPossiblyTruncated) {
+		t.Errorf("expected ErrPossiblyTruncated past the fan-out cap, got %v", err)
+	}
+}
+
+func TestTruncationFallback_PublicListSkipsMemberships(t *testing.T) {
+	c, reads := truncatingClient(testUIDs(1), nil, ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples})
+
+	if _, err := c.ListPublicPermissions(context.Background(), "file", "reader"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(*reads, []string{"file:|reader|user:*", "file:|admin|user:*", "file:|owner|user:*"}) {
+		t.Errorf("wildcard listing should only read direct grants, got %v", *reads)
+	}
+}
+
+func TestTruncationFallback_DefaultStrategyKeepsPartialResult(t *testing.T) {
+	c, reads := truncatingClient(testUIDs(2), nil, ListObjectsConfig{})
+
+	uids, err := c.ListPermissions(userCtx(testUserUID), "file", "reader")
+	if err != nil || len(uids) != 2 {
+		t.Fatalf("default strategy should return the streamed result, got %v / %v", uids, err)
+	}
+	if len(*reads) != 0 {
+		t.Errorf("default strategy should not read tuples, got %v", *reads)
+	}
+}
+
+// ============================================================
+// Truncation fallback — streaming and paginated listings
+// ============================================================
+
+func TestTruncationFallback_IterYieldsMissedGrants(t *testing.T) {
+	streamed := testUIDs(2)
+	direct := uuid.Must(uuid.NewV4())
+	user := "user:" + testUserUID
+
+	c, _ := truncatingClient(streamed, map[string][]*openfga.TupleKey{
+		user: {{Object: "file:" + direct.String(), Relation: "reader", User: user}},
+	}, ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples})
+
+	var got []uuid.UUID
+	for uid, err := range c.ListPermissionsIter(userCtx(testUserUID), "file", "reader") {
+		if err != nil {
+			t.Fatalf("a complete fallback should not report truncation: %v", err)
+		}
+		got = append(got, uid)
+	}
+	if want := append(slices.Clone(streamed), direct); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTruncationFallback_IterYieldsPossiblyTruncated(t *testing.T) {
+	c, _ := truncatingClient(testUIDs(1), nil, ListObjectsConfig{TruncationStrategy: TruncationStrategyReadTuples})
+
+	var lastErr error
+	for _, err := range c.ListPermissionsIter(userCtx(testUserUID), "file", "viewer") {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, ErrPossiblyTruncated) {
+		t.Errorf("expected ErrPossiblyTruncated, got %v", lastErr)
+	}
+}
+
+func TestTruncationFallback_PageFailsOnUnrecoveredTruncation(t *testing.T) {
+	reader := useTestMetrics(t)
+	c, _ := truncatingClient(testUIDs(3), nil, ListObjectsConfig{})
+
+	page, next, err := c.ListPermissionsPage(userCtx(testUserUID), "file", "reader", 2, "")
+	if !errors.Is(err, ErrPossiblyTruncated) || page != nil || next != "" {
+		t.Errorf("expected ErrPossiblyTruncated without a page, got %v / %q / %v", page, next, err)
+	}
+	if got := metricValue(t, reader, MetricListTruncations); got != 1 {
+		t.Errorf("expected 1 truncation, got %d", got)
+	}
+}
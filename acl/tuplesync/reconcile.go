@@ -0,0 +1,212 @@
+package tuplesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// ExpectedTuples enumerates the tuples the store should hold, e.g. by
+// walking the source-of-truth tables, calling emit for each of them.
+type ExpectedTuples func(ctx context.Context, emit func(Tuple)) error
+
+// ReconcileOptions configures Reconcile.
+type ReconcileOptions struct {
+	// ObjectTypes restricts the reconciliation to the tuples of these
+	// object types: tuples of other types are skipped and never deleted,
+	// and expected tuples of other types are an error. Defaults to the
+	// whole store.
+	ObjectTypes []string
+	// DryRun computes the diff without applying it.
+	DryRun bool
+}
+
+// Report is the outcome of Reconcile.
+type Report struct {
+	// Expected and Actual count the expected tuples and the tuples the
+	// store held.
+	Expected int
+	Actual   int
+	// Adds and Deletes are the tuples written and deleted, or that
+	// would be with DryRun. A tuple whose condition differs is deleted
+	// and written again.
+	Adds    []Tuple
+	Deletes []Tuple
+	// Applied reports whether the diff was applied.
+	Applied bool
+	// Missing lists, when applying the diff failed, the tuples of Adds
+	// that were not written. Those replacing a tuple whose condition
+	// differs may have been deleted all the same, leaving the store
+	// with neither version until Reconcile runs again.
+	Missing []Tuple
+}
+
+// InSync reports whether the store already held the expected tuples.
+func (r *Report) InSync() bool {
+	return len(r.Adds) == 0 && len(r.Deletes) == 0
+}
+
+// Write prints the diff to w, one "+ " or "- " line per tuple, followed
+// by a summary.
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+	for _, t := range r.Deletes {
+		fmt.Fprintf(&b, "- %s\n", t)
+	}
+	for _, t := range r.Adds {
+		fmt.Fprintf(&b, "+ %s\n", t)
+	}
+	state := "dry run"
+	switch {
+	case r.Applied:
+		state = "applied"
+	case len(r.Missing) > 0:
+		state = fmt.Sprintf("failed, %d not written", len(r.Missing))
+	}
+	fmt.Fprintf(&b, "%d expected, %d actual: %d to add, %d to delete (%s)\n",
+		r.Expected, r.Actual, len(r.Adds), len(r.Deletes), state)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Reconcile compares the tuples of s with the expected ones and, unless
+// opts.DryRun is set, writes the missing tuples and deletes the
+// unexpected ones. The expected tuples are held in memory.
+func Reconcile(ctx context.Context, s Store, expected ExpectedTuples, opts ReconcileOptions) (*Report, error) {
+	report := &Report{}
+	want := map[[3]string]Tuple{}
+	var order [][3]string
+	var emitErr error
+	err := expected(ctx, func(t Tuple) {
+		if emitErr != nil {
+			return
+		}
+		if err := t.validate(); err != nil {
+			emitErr = fmt.Errorf("expected tuple %s: %w", t, err)
+			return
+		}
+		if !inScope(opts.ObjectTypes, t.Object) {
+			emitErr = fmt.Errorf("%w: expected tuple %s is outside of the reconciled object types", errorsx.ErrInvalidArgument, t)
+			return
+		}
+		if _, dup := want[t.key()]; !dup {
+			order = append(order, t.key())
+		}
+		want[t.key()] = t
+	})
+	if err == nil {
+		err = emitErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Expected = len(want)
+
+	held := map[[3]string]bool{}
+	for actual, err := range scan(ctx, s, opts.ObjectTypes, 0) {
+		if err != nil {
+			return nil, err
+		}
+		report.Actual++
+
+		w, ok := want[actual.key()]
+		switch {
+		case !ok:
+			report.Deletes = append(report.Deletes, actual)
+		case !sameCondition(w.Condition, actual.Condition):
+			report.Deletes = append(report.Deletes, actual)
+		default:
+			held[actual.key()] = true
+		}
+	}
+	for _, key := range order {
+		if !held[key] {
+			report.Adds = append(report.Adds, want[key])
+		}
+	}
+
+	if opts.DryRun || report.InSync() {
+		return report, nil
+	}
+
+	writes := make([]*openfga.TupleKey, 0, len(report.Adds))
+	for _, t := range report.Adds {
+		k, err := t.tupleKey()
+		if err != nil {
+			return report, err
+		}
+		writes = append(writes, k)
+	}
+	deletes := make([]*openfga.TupleKeyWithoutCondition, 0, len(report.Deletes))
+	for _, t := range report.Deletes {
+		deletes = append(deletes, t.deleteKey())
+	}
+	// OpenFGA rejects a request deleting and writing the same tuple, so
+	// the tuples whose condition changed are deleted first.
+	if err := s.ApplyTuples(ctx, nil, deletes); err != nil {
+		report.Missing = report.Adds
+		return report, err
+	}
+	if err := s.ApplyTuples(ctx, writes, nil); err != nil {
+		report.Missing = missingAdds(report.Adds, err)
+		return report, err
+	}
+	report.Applied = true
+	return report, nil
+}
+
+// missingAdds returns the tuples of adds that a failed ApplyTuples
+// didn't write: the failures it reports, or all of them when the error
+// doesn't tell.
+func missingAdds(adds []Tuple, err error) []Tuple {
+	var writeErr *acl.TupleWriteError
+	if !errors.As(err, &writeErr) {
+		return adds
+	}
+	failed := map[[3]string]bool{}
+	for _, f := range writeErr.Failures {
+		if f.Operation == acl.TupleOperationWrite {
+			failed[[3]string{f.Tuple.User, f.Tuple.Relation, f.Tuple.Object}] = true
+		}
+	}
+	var missing []Tuple
+	for _, t := range adds {
+		if failed[t.key()] {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// sameCondition compares conditions after a round trip through
+// structpb, so that the numbers of an expected context compare equal
+// to the ones read from OpenFGA.
+func sameCondition(a, b *Condition) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Name != b.Name {
+		return false
+	}
+	return reflect.DeepEqual(normalizeContext(a.Context), normalizeContext(b.Context))
+}
+
+func normalizeContext(ctx map[string]any) map[string]any {
+	if len(ctx) == 0 {
+		return nil
+	}
+	k, err := (Tuple{Condition: &Condition{Context: ctx}}).tupleKey()
+	if err != nil {
+		return ctx
+	}
+	return k.GetCondition().GetContext().AsMap()
+}
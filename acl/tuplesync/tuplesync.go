@@ -0,0 +1,230 @@
+// Package tuplesync exports, imports and reconciles the tuples of an
+// OpenFGA store, e.g. to realign it with the source-of-truth tables
+// after a database restore.
+//
+// Tuples are exchanged as JSON Lines, one Tuple per line, with the
+// fields of the acltest fixtures plus the optional condition:
+//
+//	{"user":"user:<uid>","relation":"owner","object":"pipeline:<uid>"}
+//	{"user":"user:<uid>","relation":"reader","object":"pipeline:<uid>","condition":{"name":"not_expired","context":{"expires_at":"2026-01-01T00:00:00Z"}}}
+package tuplesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// DefaultImportBatchSize is the number of tuples Import applies at once
+// when ImportOptions.BatchSize is not set.
+const DefaultImportBatchSize = 1000
+
+// Store is the part of acl.ACLClient the package reads and writes
+// tuples through, so that writes are audited and invalidate the
+// caches.
+type Store interface {
+	ScanTuples(ctx context.Context, filter acl.ReadTupleFilter) iter.Seq2[*openfga.TupleKey, error]
+	ApplyTuples(ctx context.Context, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) error
+}
+
+var _ Store = (*acl.ACLClient)(nil)
+
+// Tuple is a relation tuple, as exported.
+type Tuple struct {
+	User      string     `json:"user"`
+	Relation  string     `json:"relation"`
+	Object    string     `json:"object"`
+	Condition *Condition `json:"condition,omitempty"`
+}
+
+// Condition is the condition of a conditional tuple.
+type Condition struct {
+	Name    string         `json:"name"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+func (t Tuple) String() string {
+	s := t.User + " " + t.Relation + " " + t.Object
+	if t.Condition != nil {
+		s += " [" + t.Condition.Name + "]"
+	}
+	return s
+}
+
+// key identifies the tuple regardless of its condition, like OpenFGA
+// does.
+func (t Tuple) key() [3]string {
+	return [3]string{t.User, t.Relation, t.Object}
+}
+
+func (t Tuple) validate() error {
+	if t.User == "" || t.Relation == "" || t.Object == "" {
+		return fmt.Errorf("%w: user, relation and object are required", errorsx.ErrInvalidArgument)
+	}
+	return nil
+}
+
+func fromTupleKey(k *openfga.TupleKey) Tuple {
+	t := Tuple{User: k.GetUser(), Relation: k.GetRelation(), Object: k.GetObject()}
+	if c := k.GetCondition(); c != nil {
+		t.Condition = &Condition{Name: c.GetName(), Context: c.GetContext().AsMap()}
+	}
+	return t
+}
+
+func (t Tuple) tupleKey() (*openfga.TupleKey, error) {
+	k := &openfga.TupleKey{User: t.User, Relation: t.Relation, Object: t.Object}
+	if t.Condition != nil {
+		k.Condition = &openfga.RelationshipCondition{Name: t.Condition.Name}
+		if len(t.Condition.Context) > 0 {
+			ctx, err := structpb.NewStruct(t.Condition.Context)
+			if err != nil {
+				return nil, fmt.Errorf("%w: condition context of %s: %v", errorsx.ErrInvalidArgument, t, err)
+			}
+			k.Condition.Context = ctx
+		}
+	}
+	return k, nil
+}
+
+func (t Tuple) deleteKey() *openfga.TupleKeyWithoutCondition {
+	return &openfga.TupleKeyWithoutCondition{User: t.User, Relation: t.Relation, Object: t.Object}
+}
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// ObjectTypes restricts the export to the tuples of these object
+	// types. Defaults to the whole store.
+	ObjectTypes []string
+	// PageSize is the number of tuples read at once. Defaults to
+	// acl.DefaultReadPageSize.
+	PageSize int32
+}
+
+// Export writes the tuples of s to w, one JSON line per tuple, and
+// returns how many it wrote.
+func Export(ctx context.Context, s Store, w io.Writer, opts ExportOptions) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	for t, err := range scan(ctx, s, opts.ObjectTypes, opts.PageSize) {
+		if err != nil {
+			return n, err
+		}
+		if err := enc.Encode(t); err != nil {
+			return n, fmt.Errorf("writing tuple: %w", err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// scan reads the tuples of the given object types, or of the whole
+// store when objectTypes is empty. OpenFGA rejects a Read filtering on
+// an object type alone, without an object ID or a user, so the whole
+// store is read and the other types are skipped here.
+func scan(ctx context.Context, s Store, objectTypes []string, pageSize int32) iter.Seq2[Tuple, error] {
+	return func(yield func(Tuple, error) bool) {
+		for k, err := range s.ScanTuples(ctx, acl.ReadTupleFilter{PageSize: pageSize}) {
+			if err != nil {
+				yield(Tuple{}, err)
+				return
+			}
+			if !inScope(objectTypes, k.GetObject()) {
+				continue
+			}
+			if !yield(fromTupleKey(k), nil) {
+				return
+			}
+		}
+	}
+}
+
+// inScope reports whether object is of one of objectTypes, or whether
+// objectTypes is empty.
+func inScope(objectTypes []string, object string) bool {
+	if len(objectTypes) == 0 {
+		return true
+	}
+	objectType, _, _ := strings.Cut(object, ":")
+	return slices.Contains(objectTypes, objectType)
+}
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	// BatchSize is the number of tuples applied at once. Defaults to
+	// DefaultImportBatchSize.
+	BatchSize int
+}
+
+// Import writes the tuples read from r, one JSON line per tuple, to s
+// and returns how many it read. Tuples that already exist are skipped,
+// so that an interrupted import can be replayed. A tuple the store
+// holds with another condition, or without one, counts as existing and
+// keeps its condition: use Reconcile to replace it. Batches applied
+// before an error are kept.
+func Import(ctx context.Context, s Store, r io.Reader, opts ImportOptions) (int, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultImportBatchSize
+	}
+
+	n := 0
+	batch := make([]*openfga.TupleKey, 0, batchSize)
+	for t, err := range ReadTuples(r) {
+		if err != nil {
+			return n, err
+		}
+		k, err := t.tupleKey()
+		if err != nil {
+			return n, err
+		}
+		batch = append(batch, k)
+		n++
+
+		if len(batch) == batchSize {
+			if err := s.ApplyTuples(ctx, batch, nil); err != nil {
+				return n, err
+			}
+			batch = batch[:0]
+		}
+	}
+	return n, s.ApplyTuples(ctx, batch, nil)
+}
+
+// ReadTuples decodes the JSON lines of r. Iteration stops at the first
+// error, which names the offending line.
+func ReadTuples(r io.Reader) iter.Seq2[Tuple, error] {
+	return func(yield func(Tuple, error) bool) {
+		dec := json.NewDecoder(r)
+		for line := 1; ; line++ {
+			var t Tuple
+			err := dec.Decode(&t)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err == nil {
+				err = t.validate()
+			}
+			if err != nil {
+				yield(Tuple{}, fmt.Errorf("tuple %d: %w", line, err))
+				return
+			}
+			if !yield(t, nil) {
+				return
+			}
+		}
+	}
+}
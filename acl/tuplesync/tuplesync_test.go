@@ -0,0 +1,293 @@
+package tuplesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/instill-ai/x/acl"
+	"github.com/instill-ai/x/acl/acltest"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+const testModel = `model
+  schema 1.1
+
+type user
+
+type organization
+  relations
+    define member: [user]
+
+type pipeline
+  relations
+    define owner: [user, organization]
+    define reader: [user, user:*] or owner
+`
+
+func newTestStore(t *testing.T, tuples ...*openfga.TupleKey) (*acltest.Server, *acl.ACLClient) {
+	t.Helper()
+	model, err := acl.ParseModelDSL(testModel)
+	if err != nil {
+		t.Fatalf("parsing model: %v", err)
+	}
+	fake := acltest.New()
+	fake.AddModel(model)
+	fake.AddTuples(tuples...)
+
+	client, err := acl.NewClientWithCache(context.Background(), fake, fake, nil, acl.Config{})
+	if err != nil {
+		t.Fatalf("NewClientWithCache: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return fake, client
+}
+
+func tk(user, relation, object string) *openfga.TupleKey {
+	return &openfga.TupleKey{User: user, Relation: relation, Object: object}
+}
+
+func tupleStrings(keys []*openfga.TupleKey) []string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = fromTupleKey(k).String()
+	}
+	slices.Sort(out)
+	return out
+}
+
+var testTuples = []*openfga.TupleKey{
+	tk("user:alice", "owner", "pipeline:p1"),
+	tk("user:*", "reader", "pipeline:p1"),
+	tk("user:bob", "member", "organization:o1"),
+	tk("organization:o1", "owner", "pipeline:p2"),
+}
+
+// ============================================================
+// Export / Import
+// ============================================================
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	_, source := newTestStore(t, testTuples...)
+	var buf bytes.Buffer
+	n, err := Export(context.Background(), source, &buf, ExportOptions{PageSize: 1})
+	if err != nil || n != len(testTuples) {
+		t.Fatalf("expected %d exported tuples, got %d, %v", len(testTuples), n, err)
+	}
+	exported := buf.String()
+
+	target, client := newTestStore(t, testTuples[0])
+	for range 2 {
+		// Replaying the import skips the tuples already written.
+		n, err = Import(context.Background(), client, strings.NewReader(exported), ImportOptions{BatchSize: 3})
+		if err != nil || n != len(testTuples) {
+			t.Fatalf("expected %d imported tuples, got %d, %v", len(testTuples), n, err)
+		}
+	}
+	if got, want := tupleStrings(target.Tuples()), tupleStrings(testTuples); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestExport_FiltersObjectTypes(t *testing.T) {
+	_, source := newTestStore(t, testTuples...)
+	var buf bytes.Buffer
+	n, err := Export(context.Background(), source, &buf, ExportOptions{ObjectTypes: []string{"organization"}})
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 exported tuple, got %d, %v", n, err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != `{"user":"user:bob","relation":"member","object":"organization:o1"}` {
+		t.Errorf("unexpected export %s", got)
+	}
+}
+
+func TestImport_RejectsInvalidTuples(t *testing.T) {
+	_, client := newTestStore(t)
+	input := `{"user":"user:alice","relation":"owner","object":"pipeline:p1"}
+{"user":"user:bob","object":"pipeline:p1"}
+`
+	n, err := Import(context.Background(), client, strings.NewReader(input), ImportOptions{})
+	if !errors.Is(err, errorsx.ErrInvalidArgument) || !strings.Contains(err.Error(), "tuple 2") {
+		t.Errorf("expected an invalid argument naming tuple 2, got %d, %v", n, err)
+	}
+}
+
+func TestTuple_ConditionRoundTrip(t *testing.T) {
+	in := Tuple{User: "user:alice", Relation: "reader", Object: "pipeline:p1", Condition: &Condition{
+		Name:    "not_expired",
+		Context: map[string]any{"expires_at": "2026-01-01T00:00:00Z", "max": 3},
+	}}
+	k, err := in.tupleKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := fromTupleKey(k); !sameCondition(in.Condition, out.Condition) || out.key() != in.key() {
+		t.Errorf("expected %v, got %v", in, out)
+	}
+}
+
+// ============================================================
+// Reconcile
+// ============================================================
+
+func expect(tuples ...Tuple) ExpectedTuples {
+	return func(_ context.Context, emit func(Tuple)) error {
+		for _, t := range tuples {
+			emit(t)
+		}
+		return nil
+	}
+}
+
+func TestReconcile_DryRunThenApply(t *testing.T) {
+	fake, client := newTestStore(t, testTuples...)
+	expected := expect(
+		Tuple{User: "user:alice", Relation: "owner", Object: "pipeline:p1"},
+		Tuple{User: "user:carol", Relation: "reader", Object: "pipeline:p2"},
+		Tuple{User: "organization:o1", Relation: "owner", Object: "pipeline:p2"},
+	)
+	opts := ReconcileOptions{ObjectTypes: []string{"pipeline"}, DryRun: true}
+
+	report, err := Reconcile(context.Background(), client, expected, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Applied || report.Expected != 3 || report.Actual != 3 ||
+		len(report.Adds) != 1 || report.Adds[0].User != "user:carol" ||
+		len(report.Deletes) != 1 || report.Deletes[0].User != "user:*" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(fake.Tuples()) != len(testTuples) {
+		t.Fatal("a dry run should not write")
+	}
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil || !strings.Contains(out.String(), "+ user:carol reader pipeline:p2") {
+		t.Errorf("unexpected report output %q, %v", out.String(), err)
+	}
+
+	opts.DryRun = false
+	if report, err = Reconcile(context.Background(), client, expected, opts); err != nil || !report.Applied {
+		t.Fatalf("expected the diff to be applied, got %+v, %v", report, err)
+	}
+	want := []*openfga.TupleKey{
+		tk("user:alice", "owner", "pipeline:p1"),
+		tk("user:carol", "reader", "pipeline:p2"),
+		tk("organization:o1", "owner", "pipeline:p2"),
+		// Outside of the reconciled types.
+		tk("user:bob", "member", "organization:o1"),
+	}
+	if got := tupleStrings(fake.Tuples()); !slices.Equal(got, tupleStrings(want)) {
+		t.Errorf("expected %v, got %v", tupleStrings(want), got)
+	}
+
+	if report, err = Reconcile(context.Background(), client, expected, opts); err != nil || !report.InSync() || report.Applied {
+		t.Errorf("expected the store to be in sync, got %+v, %v", report, err)
+	}
+}
+
+func TestReconcile_RejectsExpectedTuplesOutOfScope(t *testing.T) {
+	_, client := newTestStore(t)
+	_, err := Reconcile(context.Background(), client,
+		expect(Tuple{User: "user:bob", Relation: "member", Object: "organization:o1"}),
+		ReconcileOptions{ObjectTypes: []string{"pipeline"}})
+	if !errors.Is(err, errorsx.ErrInvalidArgument) {
+		t.Errorf("expected an invalid argument, got %v", err)
+	}
+}
+
+// recordingStore holds tuples in memory and records ApplyTuples calls,
+// failing those that write when writeErr is set.
+type recordingStore struct {
+	tuples   []*openfga.TupleKey
+	applied  [][2]int
+	writeErr error
+}
+
+func (s *recordingStore) ScanTuples(context.Context, acl.ReadTupleFilter) iter.Seq2[*openfga.TupleKey, error] {
+	return func(yield func(*openfga.TupleKey, error) bool) {
+		for _, k := range s.tuples {
+			if !yield(k, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *recordingStore) ApplyTuples(_ context.Context, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) error {
+	s.applied = append(s.applied, [2]int{len(writes), len(deletes)})
+	if len(writes) > 0 {
+		return s.writeErr
+	}
+	return nil
+}
+
+func TestReconcile_ReplacesChangedConditions(t *testing.T) {
+	conditional, err := (Tuple{User: "user:alice", Relation: "reader", Object: "pipeline:p1", Condition: &Condition{
+		Name:    "not_expired",
+		Context: map[string]any{"expires_at": "2026-01-01T00:00:00Z"},
+	}}).tupleKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &recordingStore{tuples: []*openfga.TupleKey{conditional}}
+
+	report, err := Reconcile(context.Background(), s, expect(Tuple{User: "user:alice", Relation: "reader", Object: "pipeline:p1"}), ReconcileOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Adds) != 1 || len(report.Deletes) != 1 {
+		t.Fatalf("expected the tuple to be replaced, got %+v", report)
+	}
+	if !slices.Equal(s.applied, [][2]int{{0, 1}, {1, 0}}) {
+		t.Errorf("expected the delete before the write, got %v", s.applied)
+	}
+}
+
+func TestReconcile_ReportsAddsMissingAfterFailedWrite(t *testing.T) {
+	conditional, err := (Tuple{User: "user:alice", Relation: "reader", Object: "pipeline:p1", Condition: &Condition{
+		Name:    "not_expired",
+		Context: map[string]any{"expires_at": "2026-01-01T00:00:00Z"},
+	}}).tupleKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeErr := errors.New("write failed")
+	s := &recordingStore{tuples: []*openfga.TupleKey{conditional}, writeErr: writeErr}
+	expected := expect(
+		Tuple{User: "user:alice", Relation: "reader", Object: "pipeline:p1"},
+		Tuple{User: "user:bob", Relation: "reader", Object: "pipeline:p1"},
+	)
+
+	report, err := Reconcile(context.Background(), s, expected, ReconcileOptions{})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+	if report.Applied || len(report.Missing) != 2 || report.Missing[0].User != "user:alice" {
+		t.Errorf("expected both adds to be reported missing, got %+v", report)
+	}
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil || !strings.Contains(out.String(), "failed, 2 not written") {
+		t.Errorf("unexpected report output %q, %v", out.String(), err)
+	}
+}
+
+func TestMissingAdds_NarrowsToFailedWrites(t *testing.T) {
+	adds := []Tuple{
+		{User: "user:alice", Relation: "reader", Object: "pipeline:p1"},
+		{User: "user:bob", Relation: "reader", Object: "pipeline:p1"},
+	}
+	err := &acl.TupleWriteError{Failures: []acl.TupleWriteFailure{{
+		Operation: acl.TupleOperationWrite,
+		Tuple:     acl.ReadTuple{User: "user:bob", Relation: "reader", Object: "pipeline:p1"},
+	}}}
+	if missing := missingAdds(adds, err); len(missing) != 1 || missing[0].User != "user:bob" {
+		t.Errorf("expected only bob to be missing, got %v", missing)
+	}
+}
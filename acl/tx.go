@@ -0,0 +1,174 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// errTxCommitted is returned when Commit is called more than once on
+// the same TupleTx.
+var errTxCommitted = errors.New("acl: transaction already committed")
+
+// TupleTx accumulates tuple grants and revocations and applies them as
+// one logical change. It is obtained from ACLClient.Begin:
+//
+//	err := c.Begin().
+//		Revoke("collection", colUID, "user:"+oldOwner, "owner").
+//		Grant("collection", colUID, "user:"+newOwner, "owner").
+//		Grant("file", fileUID, "collection:"+colUID.String(), "parent_collection").
+//		Commit(ctx)
+//
+// Commit packs the changes into as few OpenFGA Write requests as
+// MaxTuplesPerWrite allows, so a transaction within that limit is
+// atomic. Larger ones are applied chunk by chunk and report the tuples
+// of failed chunks through a *TupleWriteError.
+//
+// Operations are idempotent: granting a tuple that already exists or
+// revoking one that doesn't is not an error. When the same tuple is
+// both granted and revoked, the last call wins.
+//
+// A TupleTx is not safe for concurrent use.
+type TupleTx struct {
+	c         *ACLClient
+	ops       []txOp
+	index     map[ReadTuple]int
+	committed bool
+
+	// op, relation and before describe the transaction in its audit
+	// events, for client methods built on TupleTx.
+	op       AuditOperation
+	relation string
+	before   []ReadTuple
+}
+
+type txOp struct {
+	tuple      ReadTuple
+	objectType string
+	objectUID  string
+	revoke     bool
+}
+
+// Begin starts a new TupleTx on the client.
+func (c *ACLClient) Begin() *TupleTx {
+	return &TupleTx{c: c, index: map[ReadTuple]int{}, op: AuditTransaction}
+}
+
+// Grant adds the tuple (user, relation, objectType:objectUID) to the
+// transaction.
+func (tx *TupleTx) Grant(objectType string, objectUID uuid.UUID, user, relation string) *TupleTx {
+	tx.add(objectType, objectUID, user, relation, false)
+	return tx
+}
+
+// Revoke removes the tuple (user, relation, objectType:objectUID) in
+// the transaction.
+func (tx *TupleTx) Revoke(objectType string, objectUID uuid.UUID, user, relation string) *TupleTx {
+	tx.add(objectType, objectUID, user, relation, true)
+	return tx
+}
+
+// Len returns the number of distinct tuples the transaction changes.
+func (tx *TupleTx) Len() int {
+	return len(tx.ops)
+}
+
+func (tx *TupleTx) add(objectType string, objectUID uuid.UUID, user, relation string, revoke bool) {
+	op := txOp{
+		tuple: ReadTuple{
+			Object:   fmt.Sprintf("%s:%s", objectType, objectUID.String()),
+			Relation: relation,
+			User:     user,
+		},
+		objectType: objectType,
+		objectUID:  objectUID.String(),
+		revoke:     revoke,
+	}
+
+	if i, ok := tx.index[op.tuple]; ok {
+		tx.ops[i] = op
+		return
+	}
+	tx.index[op.tuple] = len(tx.ops)
+	tx.ops = append(tx.ops, op)
+}
+
+// Commit applies the transaction. Caches are invalidated and the
+// touched subjects pinned once per object and subject, even when some
+// chunks fail, since the others may have been applied.
+func (tx *TupleTx) Commit(ctx context.Context) error {
+	if tx.committed {
+		return errTxCommitted
+	}
+	tx.committed = true
+
+	if len(tx.ops) == 0 {
+		return nil
+	}
+	for _, op := range tx.ops {
+		if err := tx.c.validate(op.objectType, op.tuple.Relation); err != nil {
+			return err
+		}
+	}
+
+	var writes []*openfga.TupleKey
+	var deletes []*openfga.TupleKeyWithoutCondition
+	for _, op := range tx.ops {
+		if op.revoke {
+			deletes = append(deletes, &openfga.TupleKeyWithoutCondition{User: op.tuple.User, Relation: op.tuple.Relation, Object: op.tuple.Object})
+		} else {
+			writes = append(writes, &openfga.TupleKey{User: op.tuple.User, Relation: op.tuple.Relation, Object: op.tuple.Object})
+		}
+	}
+
+	err := tx.c.writeTuplesIdempotent(ctx, writes, deletes)
+	tx.c.audit(ctx, tx.op, tx.relation, tx.before, writes, deletes, err)
+
+	tx.invalidate(ctx)
+
+	return err
+}
+
+// invalidate clears the permission and list caches affected by the
+// transaction and pins its subjects, visiting each object, object type
+// and subject once.
+func (tx *TupleTx) invalidate(ctx context.Context) {
+	objects := map[[2]string]bool{}
+	objectTypes := map[string]bool{}
+	users := map[string]bool{}
+
+	for _, op := range tx.ops {
+		// The object pattern covers every user's entries, including the
+		// subject's own cached denials.
+		if key := [2]string{op.objectType, op.objectUID}; !objects[key] {
+			objects[key] = true
+			tx.c.invalidateObjectCache(ctx, op.objectType, op.objectUID)
+		}
+
+		// Wildcard subjects change every caller's list results.
+		if isWildcardSubject(op.tuple.User) {
+			if !objectTypes[op.objectType] {
+				objectTypes[op.objectType] = true
+				tx.c.invalidateListPermissionsCacheForObjectType(ctx, op.objectType)
+			}
+			continue
+		}
+
+		if !users[op.tuple.User] {
+			users[op.tuple.User] = true
+			tx.c.invalidateListPermissionsCacheForUser(ctx, op.tuple.User)
+			tx.c.pinSubject(ctx, op.tuple.User)
+		}
+	}
+}
+
+// isWildcardSubject reports whether user is a public wildcard such as
+// "user:*" or "visitor:*".
+func isWildcardSubject(user string) bool {
+	return strings.HasSuffix(user, ":*")
+}
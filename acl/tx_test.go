@@ -0,0 +1,194 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// ============================================================
+// TupleTx — batched, idempotent tuple writes
+// ============================================================
+
+func TestTupleTx_CommitsInSingleIdempotentWrite(t *testing.T) {
+	colUID := uuid.Must(uuid.NewV4())
+	var writes []*openfga.WriteRequest
+
+	fga := &mockFGA{
+		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			writes = append(writes, req)
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	err := c.Begin().
+		Revoke("collection", colUID, "user:old", "owner").
+		Grant("collection", colUID, "user:new", "owner").
+		Grant("file", testObjectUID, "collection:"+colUID.String(), "parent_collection").
+		Commit(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 {
+		t.Fatalf("expected a single Write request, got %d", len(writes))
+	}
+	req := writes[0]
+	if len(req.Deletes.GetTupleKeys()) != 1 || len(req.Writes.GetTupleKeys()) != 2 {
+		t.Errorf("expected 1 delete and 2 writes, got %v / %v", req.Deletes.GetTupleKeys(), req.Writes.GetTupleKeys())
+	}
+	if req.Writes.OnDuplicate != "ignore" || req.Deletes.OnMissing != "ignore" {
+		t.Errorf("conflicts should be ignored, got on_duplicate=%q on_missing=%q", req.Writes.OnDuplicate, req.Deletes.OnMissing)
+	}
+}
+
+func TestTupleTx_LastOperationOnTupleWins(t *testing.T) {
+	var req *openfga.WriteRequest
+	fga := &mockFGA{
+		writeFn: func(_ context.Context, r *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			req = r
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	tx := c.Begin().
+		Grant("file", testObjectUID, "user:a", "reader").
+		Revoke("file", testObjectUID, "user:a", "reader")
+	if tx.Len() != 1 {
+		t.Fatalf("expected 1 distinct tuple, got %d", tx.Len())
+	}
+	if err := tx.Commit(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Writes != nil || len(req.Deletes.GetTupleKeys()) != 1 {
+		t.Errorf("expected only the revoke, got %+v", req)
+	}
+}
+
+func TestTupleTx_EmptyCommitSkipsWrite(t *testing.T) {
+	c := newTestClient(&mockFGA{})
+
+	if err := c.Begin().Commit(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTupleTx_CommitTwiceFails(t *testing.T) {
+	fga := &mockFGA{
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	tx := c.Begin().Grant("file", testObjectUID, "user:a", "reader")
+	if err := tx.Commit(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tx.Commit(context.Background()); !errors.Is(err, errTxCommitted) {
+		t.Fatalf("expected errTxCommitted, got %v", err)
+	}
+}
+
+func TestTupleTx_ConflictFallsBackToPerTupleWrites(t *testing.T) {
+	conflict := status.Error(codes.Code(openfga.ErrorCode_write_failed_due_to_invalid_input),
+		"cannot write a tuple which already exists")
+	var calls int
+
+	fga := &mockFGA{
+		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			calls++
+			// An older server rejects the whole chunk on the duplicate
+			// and, replayed alone, the duplicate itself.
+			for _, tk := range req.Writes.GetTupleKeys() {
+				if tk.User == "user:dup" {
+					return nil, conflict
+				}
+			}
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	err := c.Begin().
+		Grant("file", testObjectUID, "user:dup", "reader").
+		Grant("file", testObjectUID, "user:new", "reader").
+		Commit(context.Background())
+	if err != nil {
+		t.Fatalf("conflicts should be tolerated, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected the chunk then one write per tuple, got %d calls", calls)
+	}
+}
+
+func TestTupleTx_NonConflictErrorReported(t *testing.T) {
+	errWrite := errors.New("write rejected")
+	fga := &mockFGA{
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return nil, errWrite
+		},
+	}
+	c := newTestClient(fga)
+
+	err := c.Begin().Grant("file", testObjectUID, "user:a", "reader").Commit(context.Background())
+
+	var writeErr *TupleWriteError
+	if !errors.As(err, &writeErr) || !errors.Is(err, errWrite) {
+		t.Fatalf("expected *TupleWriteError wrapping the Write error, got %v", err)
+	}
+	if len(writeErr.Failures) != 1 || writeErr.Failures[0].Operation != TupleOperationWrite {
+		t.Errorf("unexpected failures: %+v", writeErr.Failures)
+	}
+}
+
+func TestTupleTx_InvalidatesCachesAndPinsSubjectsOnce(t *testing.T) {
+	otherUID := uuid.Must(uuid.NewV4())
+	fga := &mockFGA{
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c, mr := newTestClientWithCache(fga)
+	defer mr.Close()
+	c.config.Replica.ReplicationTimeFrame = 30
+
+	ctx := context.Background()
+	perm := checkCacheEntry("user", "someone", "file", testObjectUID.String(), "reader")
+	otherPerm := checkCacheEntry("user", "someone", "file", otherUID.String(), "reader")
+	userList := listCacheEntry("user", testUserUID, "file", "reader")
+	typeList := listCacheEntry("user", "someone", "model", "reader")
+	seedCache(t, c, []*CacheEntry{perm, otherPerm, userList, typeList}, []string{"1", "1", "[]", "[]"})
+
+	err := c.Begin().
+		Grant("file", testObjectUID, "user:"+testUserUID, "reader").
+		Grant("file", testObjectUID, "user:"+testUserUID, "executor").
+		Grant("model", otherUID, "user:*", "reader").
+		Commit(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if isCached(t, c, perm) {
+		t.Error("object permission cache should be invalidated")
+	}
+	if !isCached(t, c, otherPerm) {
+		t.Error("untouched object should keep its cache")
+	}
+	if isCached(t, c, userList) {
+		t.Error("subject list cache should be invalidated")
+	}
+	if isCached(t, c, typeList) {
+		t.Error("wildcard grant should invalidate the object type's list cache")
+	}
+	if !c.IsUserPinned(userCtx(testUserUID)) {
+		t.Error("subject should be pinned")
+	}
+}
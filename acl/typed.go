@@ -0,0 +1,127 @@
+package acl
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// TypedClient exposes the methods of an ACLClient with ObjectType, Role
+// and OwnerType arguments instead of plain strings, so that the
+// constants of this package are used instead of string literals. The
+// arguments are validated against the model like those of ACLClient.
+type TypedClient struct {
+	c *ACLClient
+}
+
+// Typed returns the typed view of the client.
+func (c *ACLClient) Typed() *TypedClient {
+	return &TypedClient{c: c}
+}
+
+// CheckPermission verifies if the current user has role on the object.
+func (t *TypedClient) CheckPermission(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, role Role) (bool, error) {
+	return t.c.CheckPermission(ctx, string(objectType), objectUID, string(role))
+}
+
+// ListPermissions lists the objects of a type the current user has role
+// on.
+func (t *TypedClient) ListPermissions(ctx context.Context, objectType ObjectType, role Role) ([]uuid.UUID, error) {
+	return t.c.ListPermissions(ctx, string(objectType), string(role))
+}
+
+// ListPublicPermissions lists the objects of a type everyone has role
+// on.
+func (t *TypedClient) ListPublicPermissions(ctx context.Context, objectType ObjectType, role Role) ([]uuid.UUID, error) {
+	return t.c.ListPublicPermissions(ctx, string(objectType), string(role))
+}
+
+// SetOwner sets the owner of the object.
+func (t *TypedClient) SetOwner(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, ownerType OwnerType, ownerUID uuid.UUID) error {
+	return t.c.SetOwner(ctx, string(objectType), objectUID, string(ownerType), ownerUID)
+}
+
+// TransferOwnership replaces the owner of the object in a single write.
+func (t *TypedClient) TransferOwnership(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, newOwnerType OwnerType, newOwnerUID uuid.UUID) error {
+	return t.c.TransferOwnership(ctx, string(objectType), objectUID, string(newOwnerType), newOwnerUID)
+}
+
+// GetOwner retrieves the owner of the object.
+func (t *TypedClient) GetOwner(ctx context.Context, objectType ObjectType, objectUID uuid.UUID) (OwnerType, string, error) {
+	ownerType, ownerUID, err := t.c.GetOwner(ctx, string(objectType), objectUID)
+	return OwnerType(ownerType), ownerUID, err
+}
+
+// Purge deletes every permission on the object.
+func (t *TypedClient) Purge(ctx context.Context, objectType ObjectType, objectUID uuid.UUID) error {
+	return t.c.Purge(ctx, string(objectType), objectUID)
+}
+
+// SetResourcePermission grants role on the object to user, replacing
+// the roles user held, or revokes it when enable is false.
+func (t *TypedClient) SetResourcePermission(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, user string, role Role, enable bool) error {
+	return t.c.SetResourcePermission(ctx, string(objectType), objectUID, user, string(role), enable)
+}
+
+// GrantUntil grants role on the object to user until expiry.
+func (t *TypedClient) GrantUntil(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, user string, role Role, expiry time.Time) error {
+	return t.c.GrantUntil(ctx, string(objectType), objectUID, user, string(role), expiry)
+}
+
+// DeleteResourcePermission deletes every permission of user on the
+// object.
+func (t *TypedClient) DeleteResourcePermission(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, user string) error {
+	return t.c.DeleteResourcePermission(ctx, string(objectType), objectUID, user)
+}
+
+// SetPublicPermission makes the object readable by everyone.
+func (t *TypedClient) SetPublicPermission(ctx context.Context, objectType ObjectType, objectUID uuid.UUID) error {
+	return t.c.SetPublicPermission(ctx, string(objectType), objectUID)
+}
+
+// DeletePublicPermission deletes the public permissions of the object.
+func (t *TypedClient) DeletePublicPermission(ctx context.Context, objectType ObjectType, objectUID uuid.UUID) error {
+	return t.c.DeletePublicPermission(ctx, string(objectType), objectUID)
+}
+
+// ListSubjects lists the subjects of subjectTypes holding role on the
+// object.
+func (t *TypedClient) ListSubjects(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, role Role, subjectTypes []string) ([]Subject, error) {
+	return t.c.ListSubjects(ctx, string(objectType), objectUID, string(role), subjectTypes)
+}
+
+// ExplainPermission explains the answer of CheckPermission.
+func (t *TypedClient) ExplainPermission(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, role Role) (*Explanation, error) {
+	return t.c.ExplainPermission(ctx, string(objectType), objectUID, string(role))
+}
+
+// CreateShareLink mints a share link granting role on the object.
+func (t *TypedClient) CreateShareLink(ctx context.Context, objectType ObjectType, objectUID uuid.UUID, role Role, expiry time.Time) (*ShareLink, error) {
+	return t.c.CreateShareLink(ctx, string(objectType), objectUID, string(role), expiry)
+}
+
+// AddMember adds user to the group with role.
+func (t *TypedClient) AddMember(ctx context.Context, groupType ObjectType, groupUID uuid.UUID, user string, role Role) error {
+	return t.c.AddMember(ctx, string(groupType), groupUID, user, string(role))
+}
+
+// RemoveMember removes user from the group.
+func (t *TypedClient) RemoveMember(ctx context.Context, groupType ObjectType, groupUID uuid.UUID, user string) error {
+	return t.c.RemoveMember(ctx, string(groupType), groupUID, user)
+}
+
+// SetMemberRole replaces the role of a member of the group.
+func (t *TypedClient) SetMemberRole(ctx context.Context, groupType ObjectType, groupUID uuid.UUID, user string, role Role) error {
+	return t.c.SetMemberRole(ctx, string(groupType), groupUID, user, string(role))
+}
+
+// ListMembers lists the direct members of the group.
+func (t *TypedClient) ListMembers(ctx context.Context, groupType ObjectType, groupUID uuid.UUID) ([]Membership, error) {
+	return t.c.ListMembers(ctx, string(groupType), groupUID)
+}
+
+// ListMemberships lists the groups of groupType user is a member of.
+func (t *TypedClient) ListMemberships(ctx context.Context, user string, groupType ObjectType) ([]Membership, error) {
+	return t.c.ListMemberships(ctx, user, string(groupType))
+}
@@ -1,6 +1,8 @@
 package acl
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid"
 )
 
@@ -70,6 +72,20 @@ const PermissionCachePrefix = "acl:perm:"
 // per (user, objectType, role) and stores a list of object UIDs.
 const ListPermissionsCachePrefix = "acl:list:"
 
+// ListSubjectsCachePrefix is the Redis key prefix for ListSubjects
+// cache entries, keyed per (object, relation, subject types).
+const ListSubjectsCachePrefix = "acl:subj:"
+
+// CacheVersionPrefix is the Redis key prefix for the per-object,
+// per-subject and per-object-type versions that cache keys embed.
+// Bumping a version invalidates every dependent cache entry.
+const CacheVersionPrefix = "acl:ver:"
+
+// CacheInvalidationChannel is the Redis pub/sub channel on which cache
+// invalidations are broadcast to keep in-process caches coherent
+// across replicas.
+const CacheInvalidationChannel = "acl:invalidate"
+
 // ReadTupleFilter selects which tuples ReadTuples enumerates. The
 // fields map 1:1 onto OpenFGA's ReadRequestTupleKey: any combination
 // of object, relation, and user is valid, including a partial filter
@@ -95,8 +111,32 @@ type ReadTuple struct {
 	Object   string
 	Relation string
 	User     string
+	// Condition is the name of the tuple's condition, if any.
+	Condition string
+	// ExpiresAt is the expiry of a grant written by GrantUntil.
+	ExpiresAt time.Time
 }
 
+// CheckItem identifies one (object, role) pair evaluated by
+// BatchCheckPermission. The struct is comparable so it can key the
+// result map directly.
+type CheckItem struct {
+	ObjectType string
+	ObjectUID  uuid.UUID
+	Role       string
+}
+
+// MaxChecksPerBatchCheck mirrors OpenFGA's maxChecksPerBatchCheck
+// server flag (OPENFGA_MAX_CHECKS_PER_BATCH_CHECK). BatchCheckPermission
+// splits larger inputs into several BatchCheck calls of at most this
+// many items, because the server rejects oversized batches outright.
+const MaxChecksPerBatchCheck = 50
+
+// MaxTuplesPerWrite mirrors OpenFGA's maxTuplesPerWrite server flag
+// (OPENFGA_MAX_TUPLES_PER_WRITE). Multi-tuple writes are split into
+// chunks of at most this many tuples (writes and deletes combined).
+const MaxTuplesPerWrite = 100
+
 // DefaultReadPageSize is the per-RPC page size used by ReadTuples
 // when the caller leaves PageSize unset. Chosen to balance round-trip
 // count against per-response payload size; the OpenFGA Read API
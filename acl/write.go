@@ -0,0 +1,235 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+
+	logx "github.com/instill-ai/x/log"
+)
+
+// TupleOperation names the kind of change a Write request applies to a
+// tuple.
+type TupleOperation string
+
+const (
+	// TupleOperationWrite adds a tuple.
+	TupleOperationWrite TupleOperation = "write"
+	// TupleOperationDelete removes a tuple.
+	TupleOperationDelete TupleOperation = "delete"
+)
+
+// TupleWriteFailure describes a single tuple that could not be written
+// or deleted, together with the error returned for the Write request
+// that carried it.
+type TupleWriteFailure struct {
+	Operation TupleOperation
+	Tuple     ReadTuple
+	Err       error
+}
+
+// TupleWriteError aggregates the failures of a multi-request tuple
+// write. OpenFGA applies each Write request atomically, so every tuple
+// of a rejected chunk is reported even if only one of them caused the
+// rejection; tuples in other chunks are unaffected and were applied.
+type TupleWriteError struct {
+	Failures []TupleWriteFailure
+
+	// errs holds one error per rejected Write request.
+	errs []error
+}
+
+// Error implements the error interface. Share-link tokens and link
+// codes are redacted, including in the errors of the Write requests,
+// since error messages end up in logs. Failures keeps the raw tuples.
+func (e *TupleWriteError) Error() string {
+	tuples := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		tuples[i] = fmt.Sprintf("%s %s#%s@%s", f.Operation, redactSubject(f.Tuple.Object), f.Tuple.Relation, redactSubject(f.Tuple.User))
+	}
+	return fmt.Sprintf("%d tuple(s) failed: [%s]: %s", len(e.Failures), strings.Join(tuples, ", "), redactSubjects(errors.Join(e.errs...).Error()))
+}
+
+// Unwrap returns one error per rejected Write request so errors.Is and
+// errors.As can match the underlying gRPC / domain errors.
+func (e *TupleWriteError) Unwrap() []error {
+	return e.errs
+}
+
+// writeTuples applies the given deletes and writes through as few
+// OpenFGA Write requests as MaxTuplesPerWrite allows. Deletes are
+// packed before writes so that, when everything fits in one request,
+// the change is atomic.
+//
+// A failed request does not stop the remaining ones: the caller gets
+// a *TupleWriteError listing exactly the tuples that were not applied,
+// which is what callers cleaning up large objects need to retry or
+// alert on instead of leaving orphaned tuples behind silently.
+func (c *ACLClient) writeTuples(ctx context.Context, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) error {
+	return c.writeTupleChunks(ctx, writes, deletes, false)
+}
+
+// writeTuplesIdempotent behaves like writeTuples but treats writing a
+// tuple that already exists, or deleting one that doesn't, as success.
+//
+// The requests ask the server to ignore such conflicts (on_duplicate /
+// on_missing). Servers that predate those options reject the whole
+// chunk instead; the chunk is then replayed one tuple per request so
+// that the conflicting tuples can be skipped individually.
+func (c *ACLClient) writeTuplesIdempotent(ctx context.Context, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) error {
+	return c.writeTupleChunks(ctx, writes, deletes, true)
+}
+
+func (c *ACLClient) writeTupleChunks(ctx context.Context, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition, idempotent bool) error {
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	log, _ := logx.GetZapLogger(ctx)
+
+	modelID, err := c.getAuthorizationModelID(ctx)
+	if err != nil {
+		return fmt.Errorf("getting authorization model: %w", err)
+	}
+
+	newRequest := func(writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) *openfga.WriteRequest {
+		req := &openfga.WriteRequest{
+			StoreId:              c.storeID,
+			AuthorizationModelId: modelID,
+		}
+		if len(deletes) > 0 {
+			req.Deletes = &openfga.WriteRequestDeletes{TupleKeys: deletes}
+			if idempotent {
+				req.Deletes.OnMissing = "ignore"
+			}
+		}
+		if len(writes) > 0 {
+			req.Writes = &openfga.WriteRequestWrites{TupleKeys: writes}
+			if idempotent {
+				req.Writes.OnDuplicate = "ignore"
+			}
+		}
+		return req
+	}
+
+	writeErr := &TupleWriteError{}
+	fail := func(req *openfga.WriteRequest, err error) {
+		writeErr.errs = append(writeErr.errs, err)
+		for _, tk := range req.GetDeletes().GetTupleKeys() {
+			writeErr.Failures = append(writeErr.Failures, TupleWriteFailure{
+				Operation: TupleOperationDelete,
+				Tuple:     ReadTuple{Object: tk.GetObject(), Relation: tk.GetRelation(), User: tk.GetUser()},
+				Err:       err,
+			})
+		}
+		for _, tk := range req.GetWrites().GetTupleKeys() {
+			writeErr.Failures = append(writeErr.Failures, TupleWriteFailure{
+				Operation: TupleOperationWrite,
+				Tuple:     ReadTuple{Object: tk.GetObject(), Relation: tk.GetRelation(), User: tk.GetUser()},
+				Err:       err,
+			})
+		}
+	}
+
+	for len(writes) > 0 || len(deletes) > 0 {
+		budget := MaxTuplesPerWrite
+		nd := min(budget, len(deletes))
+		budget -= nd
+		nw := min(budget, len(writes))
+
+		req := newRequest(writes[:nw], deletes[:nd])
+		writes, deletes = writes[nw:], deletes[nd:]
+
+		_, err := c.getClient(ctx, WriteMode).Write(ctx, req)
+		if err == nil {
+			continue
+		}
+
+		log.Error("Failed to write tuples",
+			zap.Error(err),
+			zap.Int("writes", len(req.GetWrites().GetTupleKeys())),
+			zap.Int("deletes", len(req.GetDeletes().GetTupleKeys())),
+		)
+
+		if !idempotent || !isTupleConflictErr(err) {
+			fail(req, err)
+			continue
+		}
+
+		// Replay the rejected chunk tuple by tuple, skipping conflicts.
+		for _, tk := range req.GetDeletes().GetTupleKeys() {
+			single := newRequest(nil, []*openfga.TupleKeyWithoutCondition{tk})
+			if _, err := c.getClient(ctx, WriteMode).Write(ctx, single); err != nil && !isTupleConflictErr(err) {
+				fail(single, err)
+			}
+		}
+		for _, tk := range req.GetWrites().GetTupleKeys() {
+			single := newRequest([]*openfga.TupleKey{tk}, nil)
+			if _, err := c.getClient(ctx, WriteMode).Write(ctx, single); err != nil && !isTupleConflictErr(err) {
+				fail(single, err)
+			}
+		}
+	}
+
+	if len(writeErr.Failures) > 0 {
+		return writeErr
+	}
+	return nil
+}
+
+// isTupleConflictErr reports whether a Write was rejected because it
+// wrote a tuple that already exists or deleted one that doesn't.
+// OpenFGA reports both as write_failed_due_to_invalid_input and only
+// the message tells them apart from other invalid input.
+func isTupleConflictErr(err error) bool {
+	statusErr, ok := status.FromError(err)
+	if !ok || statusErr.Code() != codes.Code(openfga.ErrorCode_write_failed_due_to_invalid_input) {
+		return false
+	}
+	msg := statusErr.Message()
+	return strings.Contains(msg, "already exists") || strings.Contains(msg, "does not exist")
+}
+
+// ApplyTuples writes and deletes raw OpenFGA tuples, keeping their
+// conditions, for tools restoring or reconciling the state of a store.
+// It packs them like TupleTx.Commit, ignores tuples that already exist
+// or are already gone, audits the change and invalidates the caches of
+// every touched object and subject. Tuples are not validated against
+// the model: the server rejects invalid ones, and the failures are
+// reported through a *TupleWriteError.
+func (c *ACLClient) ApplyTuples(ctx context.Context, writes []*openfga.TupleKey, deletes []*openfga.TupleKeyWithoutCondition) error {
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	err := c.writeTuplesIdempotent(ctx, writes, deletes)
+	c.audit(ctx, AuditApplyTuples, "", nil, writes, deletes, err)
+
+	// Reuse the invalidation of TupleTx, which is keyed by the touched
+	// tuples.
+	tx := c.Begin()
+	touch := func(object, relation, user string) {
+		objectType, objectID, _ := strings.Cut(object, ":")
+		tx.ops = append(tx.ops, txOp{
+			tuple:      ReadTuple{Object: object, Relation: relation, User: user},
+			objectType: objectType,
+			objectUID:  objectID,
+		})
+	}
+	for _, t := range writes {
+		touch(t.GetObject(), t.GetRelation(), t.GetUser())
+	}
+	for _, t := range deletes {
+		touch(t.GetObject(), t.GetRelation(), t.GetUser())
+	}
+	tx.invalidate(ctx)
+
+	return err
+}
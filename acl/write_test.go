@@ -0,0 +1,224 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	openfga "github.com/openfga/api/proto/openfga/v1"
+)
+
+// pagedReadFn serves n tuples on object through pages of pageSize,
+// using the page offset as continuation token.
+func pagedReadFn(object string, n, pageSize int) func(context.Context, *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+	return func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+		offset := 0
+		if req.ContinuationToken != "" {
+			offset, _ = strconv.Atoi(req.ContinuationToken)
+		}
+		end := min(offset+pageSize, n)
+
+		resp := &openfga.ReadResponse{}
+		for i := offset; i < end; i++ {
+			resp.Tuples = append(resp.Tuples, &openfga.Tuple{Key: &openfga.TupleKey{
+				User:     fmt.Sprintf("user:%d", i),
+				Relation: "reader",
+				Object:   object,
+			}})
+		}
+		if end < n {
+			resp.ContinuationToken = strconv.Itoa(end)
+		}
+		return resp, nil
+	}
+}
+
+// ============================================================
+// Purge — paging and chunked deletes
+// ============================================================
+
+func TestPurge_WalksAllPagesAndChunksDeletes(t *testing.T) {
+	object := "knowledgebase:" + testObjectUID.String()
+	total := 2*MaxTuplesPerWrite + 50
+	var chunkSizes []int
+	deleted := map[string]bool{}
+
+	fga := &mockFGA{
+		readFn: pagedReadFn(object, total, int(DefaultReadPageSize)),
+		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			if req.Writes != nil {
+				t.Error("purge should only delete")
+			}
+			chunkSizes = append(chunkSizes, len(req.Deletes.TupleKeys))
+			for _, tk := range req.Deletes.TupleKeys {
+				deleted[tk.User] = true
+			}
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	if err := c.Purge(context.Background(), "knowledgebase", testObjectUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deleted) != total {
+		t.Errorf("every page should be purged: expected %d deletions, got %d", total, len(deleted))
+	}
+	if len(chunkSizes) != 3 || chunkSizes[0] != MaxTuplesPerWrite || chunkSizes[2] != 50 {
+		t.Errorf("expected chunks of [%d %d 50], got %v", MaxTuplesPerWrite, MaxTuplesPerWrite, chunkSizes)
+	}
+}
+
+func TestPurge_PartialFailureReportsFailedTuples(t *testing.T) {
+	object := "knowledgebase:" + testObjectUID.String()
+	total := MaxTuplesPerWrite + 2
+	errWrite := errors.New("write rejected")
+	var calls int
+
+	fga := &mockFGA{
+		readFn: pagedReadFn(object, total, total),
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			calls++
+			if calls == 2 {
+				return nil, errWrite
+			}
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	err := c.Purge(context.Background(), "knowledgebase", testObjectUID)
+
+	var writeErr *TupleWriteError
+	if !errors.As(err, &writeErr) {
+		t.Fatalf("expected *TupleWriteError, got %T (%v)", err, err)
+	}
+	if !errors.Is(err, errWrite) {
+		t.Error("aggregated error should wrap the Write error")
+	}
+	if len(writeErr.Failures) != 2 {
+		t.Fatalf("expected the 2 tuples of the failed chunk, got %d", len(writeErr.Failures))
+	}
+	for i, f := range writeErr.Failures {
+		want := fmt.Sprintf("user:%d", MaxTuplesPerWrite+i)
+		if f.Operation != TupleOperationDelete || f.Tuple.User != want || f.Tuple.Object != object {
+			t.Errorf("unexpected failure %d: %+v", i, f)
+		}
+	}
+}
+
+func TestTupleWriteError_RedactsShareLinkTokens(t *testing.T) {
+	const token = "s3cr3t-t0ken-value"
+	err := &TupleWriteError{
+		Failures: []TupleWriteFailure{{
+			Operation: TupleOperationWrite,
+			Tuple:     ReadTuple{Object: "pipeline:" + testObjectUID.String(), Relation: "reader", User: "share_link:" + token},
+		}},
+		errs: []error{fmt.Errorf("cannot write a tuple which already exists: user: 'share_link:%s'", token)},
+	}
+	if msg := err.Error(); strings.Contains(msg, token) || !strings.Contains(msg, "share_link:s3cr…") {
+		t.Errorf("the token should be redacted, got %q", msg)
+	}
+}
+
+// ============================================================
+// SetResourcePermission / DeleteResourcePermission — single write
+// ============================================================
+
+func TestSetResourcePermission_ReplacesRoleInSingleWrite(t *testing.T) {
+	var writes []*openfga.WriteRequest
+
+	fga := &mockFGA{
+		readFn: func(_ context.Context, req *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			if req.TupleKey.User != "user:"+testUserUID || req.TupleKey.Object != "file:"+testObjectUID.String() {
+				t.Errorf("should read the subject's tuples on the object, got %+v", req.TupleKey)
+			}
+			return existingRolesReadFn("reader", "owner")(context.Background(), req)
+		},
+		writeFn: func(_ context.Context, req *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			writes = append(writes, req)
+			return &openfga.WriteResponse{}, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	if err := c.SetResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID, "writer", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(writes) != 1 {
+		t.Fatalf("expected a single Write request, got %d", len(writes))
+	}
+	req := writes[0]
+	if len(req.Deletes.GetTupleKeys()) != 1 || req.Deletes.TupleKeys[0].Relation != "reader" {
+		t.Errorf("should delete only the previous standard role, got %v", req.Deletes.GetTupleKeys())
+	}
+	if len(req.Writes.GetTupleKeys()) != 1 || req.Writes.TupleKeys[0].Relation != "writer" {
+		t.Errorf("should write the new role, got %v", req.Writes.GetTupleKeys())
+	}
+}
+
+func TestSetResourcePermission_AlreadyGrantedSkipsWrite(t *testing.T) {
+	fga := &mockFGA{
+		readFn: existingRolesReadFn("reader"),
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			t.Fatal("granting a role the user already holds should not write")
+			return nil, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	if err := c.SetResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID, "reader", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetResourcePermission_WriteErrorPropagated(t *testing.T) {
+	fga := &mockFGA{
+		readFn: existingRolesReadFn("reader"),
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			return nil, errors.New("FGA write failed")
+		},
+	}
+	c := newTestClient(fga)
+
+	err := c.SetResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID, "writer", true)
+
+	var writeErr *TupleWriteError
+	if !errors.As(err, &writeErr) {
+		t.Fatalf("expected *TupleWriteError, got %v", err)
+	}
+	if len(writeErr.Failures) != 2 {
+		t.Errorf("both the delete and the write should be reported, got %+v", writeErr.Failures)
+	}
+}
+
+func TestDeleteResourcePermission_NoExistingRolesSkipsWrite(t *testing.T) {
+	fga := &mockFGA{
+		readFn: existingRolesReadFn("owner"),
+		writeFn: func(_ context.Context, _ *openfga.WriteRequest) (*openfga.WriteResponse, error) {
+			t.Fatal("nothing to delete, should not write")
+			return nil, nil
+		},
+	}
+	c := newTestClient(fga)
+
+	if err := c.DeleteResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDeleteResourcePermission_ReadErrorPropagated(t *testing.T) {
+	fga := &mockFGA{
+		readFn: func(_ context.Context, _ *openfga.ReadRequest) (*openfga.ReadResponse, error) {
+			return nil, errors.New("FGA read failed")
+		},
+	}
+	c := newTestClient(fga)
+
+	if err := c.DeleteResourcePermission(context.Background(), "file", testObjectUID, "user:"+testUserUID); err == nil {
+		t.Fatal("read error should propagate")
+	}
+}
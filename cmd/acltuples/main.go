@@ -0,0 +1,181 @@
+// Command acltuples exports, imports and reconciles the tuples of an
+// OpenFGA store, e.g. after a database restore left them out of sync
+// with the source-of-truth tables.
+//
+// Usage:
+//
+//	acltuples [flags] export [-out tuples.jsonl] [-type pipeline]...
+//	acltuples [flags] import -in tuples.jsonl
+//	acltuples [flags] reconcile -expected tuples.jsonl [-type pipeline]... [-apply]
+//
+// Tuples are JSON Lines (see package tuplesync). Reconcile prints the
+// diff between the store and the expected tuples and only applies it
+// with -apply. With -redis, writes invalidate the ACL caches of the
+// services sharing that Redis.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/instill-ai/x/acl"
+	"github.com/instill-ai/x/acl/tuplesync"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "acltuples:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	global := flag.NewFlagSet("acltuples", flag.ExitOnError)
+	host := global.String("host", "localhost", "OpenFGA host")
+	port := global.Int("port", 8081, "OpenFGA gRPC port")
+	storeID := global.String("store-id", "", "OpenFGA store ID")
+	storeName := global.String("store-name", "", "OpenFGA store name")
+	modelID := global.String("model-id", "", "authorization model ID (defaults to the latest)")
+	redisAddr := global.String("redis", "", "Redis address of the ACL caches to invalidate")
+	global.Usage = func() {
+		fmt.Fprintln(global.Output(), "usage: acltuples [flags] export|import|reconcile [command flags]")
+		global.PrintDefaults()
+	}
+	_ = global.Parse(args)
+	if global.NArg() == 0 {
+		global.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fga, conn := acl.InitOpenFGAClient(ctx, *host, *port, 32)
+	defer conn.Close()
+
+	cfg := acl.Config{
+		Store: acl.StoreConfig{ID: *storeID, Name: *storeName},
+		Model: acl.ModelConfig{ID: *modelID, RefreshInterval: -1, SkipValidation: true},
+	}
+	var redisClient *redis.Client
+	if *redisAddr != "" {
+		redisClient = redis.NewClient(&redis.Options{Addr: *redisAddr})
+		defer redisClient.Close()
+		cfg.Cache.Enabled, cfg.Cache.ListPermissionsEnabled = true, true
+	}
+	client, err := acl.NewClientWithContext(ctx, fga, nil, redisClient, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	command, commandArgs := global.Arg(0), global.Args()[1:]
+	switch command {
+	case "export":
+		return runExport(ctx, client, commandArgs)
+	case "import":
+		return runImport(ctx, client, commandArgs)
+	case "reconcile":
+		return runReconcile(ctx, client, commandArgs)
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func runExport(ctx context.Context, client *acl.ACLClient, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "", "output file (defaults to stdout)")
+	var types stringList
+	fs.Var(&types, "type", "object type to export (repeatable, defaults to every tuple)")
+	_ = fs.Parse(args)
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	n, err := tuplesync.Export(ctx, client, w, tuplesync.ExportOptions{ObjectTypes: types})
+	fmt.Fprintf(os.Stderr, "exported %d tuples\n", n)
+	return err
+}
+
+func runImport(ctx context.Context, client *acl.ACLClient, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	in := fs.String("in", "", "input file (defaults to stdin)")
+	batchSize := fs.Int("batch-size", tuplesync.DefaultImportBatchSize, "tuples applied at once")
+	_ = fs.Parse(args)
+
+	r := io.Reader(os.Stdin)
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	n, err := tuplesync.Import(ctx, client, r, tuplesync.ImportOptions{BatchSize: *batchSize})
+	fmt.Fprintf(os.Stderr, "imported %d tuples\n", n)
+	return err
+}
+
+func runReconcile(ctx context.Context, client *acl.ACLClient, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	expectedPath := fs.String("expected", "", "file holding the expected tuples")
+	apply := fs.Bool("apply", false, "apply the diff instead of only printing it")
+	var types stringList
+	fs.Var(&types, "type", "object type to reconcile (repeatable, defaults to the whole store)")
+	_ = fs.Parse(args)
+	if *expectedPath == "" {
+		return fmt.Errorf("reconcile: -expected is required")
+	}
+
+	expected := func(_ context.Context, emit func(tuplesync.Tuple)) error {
+		f, err := os.Open(*expectedPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		for t, err := range tuplesync.ReadTuples(f) {
+			if err != nil {
+				return err
+			}
+			emit(t)
+		}
+		return nil
+	}
+
+	report, err := tuplesync.Reconcile(ctx, client, expected, tuplesync.ReconcileOptions{
+		ObjectTypes: types,
+		DryRun:      !*apply,
+	})
+	if report != nil {
+		if err := report.Write(os.Stdout); err != nil {
+			return err
+		}
+	}
+	return err
+}
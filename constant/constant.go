@@ -7,16 +7,37 @@ const (
 	// authenticated user can use different namespaces (e.g. an organization
 	// they belong to) to make requests, as long as they have permissions.
 	HeaderRequesterUIDKey = "Instill-Requester-Uid"
+	// HeaderRequesterTypeKey is the context key for the namespace type of the
+	// requester, e.g. "organization". Requesters without a type are
+	// organizations.
+	HeaderRequesterTypeKey = "Instill-Requester-Type"
+	// HeaderRequesterChainKey is the context key for a multi-hop requester:
+	// a comma-separated list of "<type>:<uid>" namespaces, each acting on
+	// behalf of the previous one, starting from the authenticated subject.
+	// It takes precedence over HeaderRequesterUIDKey.
+	HeaderRequesterChainKey = "Instill-Requester-Chain"
+	// HeaderServiceAccountUIDKey is the context key for the authenticated
+	// service account of machine-to-machine requests.
+	HeaderServiceAccountUIDKey = "Instill-Service-Account-Uid"
+	// HeaderAPITokenUIDKey is the context key for the API token a request
+	// authenticated with, when the token is not bound to a user.
+	HeaderAPITokenUIDKey = "Instill-Api-Token-Uid"
+	// HeaderScopesKey is the context key for the comma-separated scopes of
+	// a service account or API token, e.g. "pipeline:reader,model:*".
+	HeaderScopesKey = "Instill-Scopes"
 	// HeaderVisitorUIDKey is the context key for the visitor UID when requests
 	// are made without authentication.
 	HeaderVisitorUIDKey = "Instill-Visitor-Uid"
-	// HeaderAuthTypeKey is the context key the authentication type (user or
-	// visitor).
+	// HeaderAuthTypeKey is the context key the authentication type (user,
+	// visitor, serviceaccount or apitoken).
 	HeaderAuthTypeKey = "Instill-Auth-Type"
 	// HeaderUserAgentKey identifies the agent that's making a request. Its
 	// accepted values are the string values of
 	// github.com/instill-ai/protogen-go/common/run/v1alpha.RunSource.
 	HeaderUserAgentKey = "Instill-User-Agent"
+	// HeaderRequestIDKey is the context key for the request ID forwarded by
+	// the API gateway.
+	HeaderRequestIDKey = "Request-Id"
 	// ContentTypeJSON is the value for the JSON content type.
 	ContentTypeJSON = "application/json"
 )
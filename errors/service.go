@@ -12,6 +12,9 @@ var (
 	// ErrRateLimiting is used when the rate limiting occurs
 	ErrRateLimiting = errors.New("rate limiting")
 
+	// ErrUnavailable is used when a dependency is temporarily unavailable
+	ErrUnavailable = errors.New("unavailable")
+
 	// ErrExceedMaxBatchSize is used when the batch size exceeds the maximum limit
 	ErrExceedMaxBatchSize = errors.New("the batch size can not exceed 32")
 
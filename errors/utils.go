@@ -37,6 +37,12 @@ func ConvertGRPCCode(err error) codes.Code {
 		return codes.OK
 	}
 
+	// An unavailable dependency may wrap the status of its last failed
+	// call (e.g. DeadlineExceeded), which must not leak to the client.
+	if errors.Is(err, ErrUnavailable) {
+		return codes.Unavailable
+	}
+
 	// If it's already a status, return its code
 	if st, ok := status.FromError(err); ok {
 		return st.Code()
@@ -77,7 +83,8 @@ func ConvertGRPCCode(err error) codes.Code {
 		errors.Is(err, ErrInvalidOwnerNamespace),
 		errors.Is(err, ErrCanNotUsePlaintextSecret):
 		return codes.InvalidArgument
-	case errors.Is(err, ErrUnauthorized):
+	case errors.Is(err, ErrUnauthorized),
+		errors.Is(err, ErrPermissionDenied):
 		return codes.PermissionDenied
 	case errors.Is(err, ErrUnauthenticated):
 		return codes.Unauthenticated
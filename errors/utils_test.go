@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -229,6 +230,11 @@ func TestConvertGRPCCode(t *testing.T) {
 			in:       ErrUnauthorized,
 			wantCode: codes.PermissionDenied,
 		},
+		{
+			name:     "ErrPermissionDenied",
+			in:       ErrPermissionDenied,
+			wantCode: codes.PermissionDenied,
+		},
 		{
 			name:     "ErrUnauthenticated",
 			in:       ErrUnauthenticated,
@@ -239,6 +245,16 @@ func TestConvertGRPCCode(t *testing.T) {
 			in:       ErrRateLimiting,
 			wantCode: codes.ResourceExhausted,
 		},
+		{
+			name:     "ErrUnavailable",
+			in:       ErrUnavailable,
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "ErrUnavailable wrapping a status",
+			in:       errors.Join(ErrUnavailable, status.Error(codes.DeadlineExceeded, "timeout")),
+			wantCode: codes.Unavailable,
+		},
 		{
 			name:     "unknown error",
 			in:       fmt.Errorf("some unknown error"),
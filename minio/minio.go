@@ -35,8 +35,19 @@ type Client interface {
 	UploadFile(context.Context, *UploadFileParam) (url string, objectInfo *miniogo.ObjectInfo, err error)
 	UploadFileBytes(context.Context, *UploadFileBytesParam) (url string, objectInfo *miniogo.ObjectInfo, err error)
 
+	// UploadPrivateFileStream and UploadFileStream behave like their
+	// [Bytes] counterparts but read the object content from a stream, so
+	// large objects aren't held in memory.
+	UploadPrivateFileStream(context.Context, UploadFileStreamParam) error
+	UploadFileStream(context.Context, *UploadFileStreamParam) (url string, objectInfo *miniogo.ObjectInfo, err error)
+
 	DeleteFile(ctx context.Context, userUID uuid.UUID, filePath string) (err error)
 	GetFile(ctx context.Context, userUID uuid.UUID, filePath string) ([]byte, error)
+
+	// GetFileStream returns a reader over the object content, or over the
+	// requested byte range of it, and the object information. The caller
+	// must close the reader.
+	GetFileStream(ctx context.Context, userUID uuid.UUID, filePath string, byteRange ByteRange) (io.ReadCloser, *miniogo.ObjectInfo, error)
 	GetFilesByPaths(ctx context.Context, userUID uuid.UUID, filePaths []string) ([]FileContent, error)
 
 	// Client returns MinIO's SDK client. This is used to migrate progressively
@@ -59,10 +70,39 @@ const (
 	// the user that triggered the action.
 	MinIOHeaderUserUID = "x-amz-meta-instill-user-uid"
 
+	// DefaultUnknownSizePartSize is the multipart part size used to upload
+	// a stream of unknown length when no part size is provided. Each part
+	// is buffered in memory and an upload holds at most 10,000 parts, so
+	// such uploads are limited to 640 GiB.
+	DefaultUnknownSizePartSize = 64 << 20
+
 	statusEnabled = "Enabled"
 	expiryTag     = "expiry-group"
 )
 
+// ByteRange selects a part of an object. The zero value selects the whole
+// object.
+type ByteRange struct {
+	// Offset is the position of the first byte to read.
+	Offset int64
+	// Length is the number of bytes to read. If zero, the object is read
+	// until its end.
+	Length int64
+}
+
+func (r ByteRange) apply(opts *miniogo.GetObjectOptions) error {
+	switch {
+	case r.Offset < 0 || r.Length < 0:
+		return fmt.Errorf("invalid byte range: offset %d, length %d", r.Offset, r.Length)
+	case r.Length > 0:
+		return opts.SetRange(r.Offset, r.Offset+r.Length-1)
+	case r.Offset > 0:
+		return opts.SetRange(r.Offset, 0)
+	default:
+		return nil
+	}
+}
+
 // GenerateInputRefID returns a prefixed object path or an input file.
 func GenerateInputRefID(prefix string) string {
 	referenceUID, _ := uuid.NewV4()
@@ -118,13 +158,15 @@ type GetFileParams struct {
 	UserUID    uuid.UUID
 	BucketName string
 	Path       string
+	// Range restricts the fetched content to a part of the object.
+	Range ByteRange
 }
 
 // GetFile fetches a file from MinIO.
 func (fg *FileGetter) GetFile(ctx context.Context, p GetFileParams) (data []byte, contentType string, err error) {
-	object, err := fg.client.GetObject(ctx, p.BucketName, p.Path, getObjectOptions(p.UserUID))
+	object, info, err := fg.GetFileStream(ctx, p)
 	if err != nil {
-		return nil, "", fmt.Errorf("fetching object: %w", err)
+		return nil, "", err
 	}
 	defer func() {
 		err := object.Close()
@@ -133,11 +175,6 @@ func (fg *FileGetter) GetFile(ctx context.Context, p GetFileParams) (data []byte
 		}
 	}()
 
-	info, err := object.Stat()
-	if err != nil {
-		return nil, "", fmt.Errorf("getting object info: %w", err)
-	}
-
 	data, err = io.ReadAll(object)
 	if err != nil {
 		return nil, "", fmt.Errorf("reading object: %w", err)
@@ -146,6 +183,12 @@ func (fg *FileGetter) GetFile(ctx context.Context, p GetFileParams) (data []byte
 	return data, info.ContentType, nil
 }
 
+// GetFileStream fetches a file from MinIO without reading it into memory.
+// The caller must close the returned reader.
+func (fg *FileGetter) GetFileStream(ctx context.Context, p GetFileParams) (io.ReadCloser, *miniogo.ObjectInfo, error) {
+	return getObjectStream(ctx, fg.client, p.BucketName, p.Path, p.UserUID, p.Range)
+}
+
 type minio struct {
 	client           *miniogo.Client
 	bucket           string
@@ -261,6 +304,25 @@ type UploadFileBytesParam struct {
 	ExpiryRuleTag string
 }
 
+// UploadFileStreamParam contains the information to upload a data stream to
+// MinIO.
+type UploadFileStreamParam struct {
+	UserUID  uuid.UUID
+	FilePath string
+	// Reader provides the object content.
+	Reader io.Reader
+	// Size is the length of the content. A negative size means the length
+	// is unknown and the content is uploaded in parts of PartSize bytes
+	// until the reader is exhausted.
+	Size int64
+	// PartSize is the size of the multipart upload parts. It defaults to
+	// the SDK choice for known sizes and to DefaultUnknownSizePartSize for
+	// unknown ones. MinIO requires at least 5 MiB.
+	PartSize      uint64
+	FileMimeType  string
+	ExpiryRuleTag string
+}
+
 func (m *minio) UploadFile(ctx context.Context, param *UploadFileParam) (url string, objectInfo *miniogo.ObjectInfo, err error) {
 	jsonData, _ := json.Marshal(param.FileContent)
 	return m.UploadFileBytes(ctx, &UploadFileBytesParam{
@@ -273,23 +335,38 @@ func (m *minio) UploadFile(ctx context.Context, param *UploadFileParam) (url str
 }
 
 func (m *minio) UploadPrivateFileBytes(ctx context.Context, param UploadFileBytesParam) error {
+	return m.UploadPrivateFileStream(ctx, streamParam(param))
+}
+
+func (m *minio) UploadFileBytes(ctx context.Context, param *UploadFileBytesParam) (url string, objectInfo *miniogo.ObjectInfo, err error) {
+	p := streamParam(*param)
+	return m.UploadFileStream(ctx, &p)
+}
+
+func (m *minio) UploadPrivateFileStream(ctx context.Context, param UploadFileStreamParam) error {
+	partSize := param.PartSize
+	if param.Size < 0 && partSize == 0 {
+		partSize = DefaultUnknownSizePartSize
+	}
+
 	_, err := m.client.PutObject(ctx,
 		m.bucket,
 		param.FilePath,
-		bytes.NewReader(param.FileBytes),
-		int64(len(param.FileBytes)),
+		param.Reader,
+		param.Size,
 		miniogo.PutObjectOptions{
 			ContentType:  param.FileMimeType,
 			UserTags:     map[string]string{expiryTag: param.ExpiryRuleTag},
 			UserMetadata: map[string]string{MinIOHeaderUserUID: param.UserUID.String()},
+			PartSize:     partSize,
 		},
 	)
 
 	return err
 }
 
-func (m *minio) UploadFileBytes(ctx context.Context, param *UploadFileBytesParam) (url string, objectInfo *miniogo.ObjectInfo, err error) {
-	if err := m.UploadPrivateFileBytes(ctx, *param); err != nil {
+func (m *minio) UploadFileStream(ctx context.Context, param *UploadFileStreamParam) (url string, objectInfo *miniogo.ObjectInfo, err error) {
+	if err := m.UploadPrivateFileStream(ctx, *param); err != nil {
 		return "", nil, fmt.Errorf("putting object in MinIO: %w", err)
 	}
 
@@ -347,9 +424,9 @@ func (m *minio) DeleteFile(ctx context.Context, userUID uuid.UUID, filePath stri
 
 // GetFile Get the object using the client
 func (m *minio) GetFile(ctx context.Context, userUID uuid.UUID, filePath string) ([]byte, error) {
-	object, err := m.client.GetObject(ctx, m.bucket, filePath, getObjectOptions(userUID))
+	object, _, err := m.GetFileStream(ctx, userUID, filePath, ByteRange{})
 	if err != nil {
-		return nil, fmt.Errorf("getting object from MinIO: %w", err)
+		return nil, err
 	}
 	defer func() {
 		err := object.Close()
@@ -368,6 +445,11 @@ func (m *minio) GetFile(ctx context.Context, userUID uuid.UUID, filePath string)
 	return buf.Bytes(), nil
 }
 
+// GetFileStream returns a reader over the object, or over a range of it.
+func (m *minio) GetFileStream(ctx context.Context, userUID uuid.UUID, filePath string, byteRange ByteRange) (io.ReadCloser, *miniogo.ObjectInfo, error) {
+	return getObjectStream(ctx, m.client, m.bucket, filePath, userUID, byteRange)
+}
+
 // FileContent represents a file and its content
 type FileContent struct {
 	Name    string
@@ -440,6 +522,40 @@ func newClient(params ClientParams) (*miniogo.Client, error) {
 	return client, nil
 }
 
+func streamParam(param UploadFileBytesParam) UploadFileStreamParam {
+	return UploadFileStreamParam{
+		UserUID:       param.UserUID,
+		FilePath:      param.FilePath,
+		Reader:        bytes.NewReader(param.FileBytes),
+		Size:          int64(len(param.FileBytes)),
+		FileMimeType:  param.FileMimeType,
+		ExpiryRuleTag: param.ExpiryRuleTag,
+	}
+}
+
+// getObjectStream opens an object and stats it, so that errors such as a
+// missing object are returned here rather than on the first read. For a
+// range, the object information size is the size of the range.
+func getObjectStream(ctx context.Context, client *miniogo.Client, bucket, path string, userUID uuid.UUID, byteRange ByteRange) (io.ReadCloser, *miniogo.ObjectInfo, error) {
+	opts := getObjectOptions(userUID)
+	if err := byteRange.apply(&opts); err != nil {
+		return nil, nil, err
+	}
+
+	object, err := client.GetObject(ctx, bucket, path, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting object from MinIO: %w", err)
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		_ = object.Close()
+		return nil, nil, fmt.Errorf("getting object info: %w", err)
+	}
+
+	return object, &info, nil
+}
+
 func getObjectOptions(userUID uuid.UUID) miniogo.GetObjectOptions {
 	opts := miniogo.GetObjectOptions{}
 	opts.Set(MinIOHeaderUserUID, userUID.String())
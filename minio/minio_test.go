@@ -1,9 +1,12 @@
 package minio_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/frankban/quicktest"
@@ -310,6 +313,65 @@ func TestMinioClient_GetFile_Error(t *testing.T) {
 	qt.Check(content, quicktest.IsNil)
 }
 
+func TestMinioClient_UploadPrivateFileStream(t *testing.T) {
+	qt := quicktest.New(t)
+	mc := minimock.NewController(t)
+
+	// Create mock client
+	mockClient := mockminio.NewClientMock(mc)
+
+	ctx := context.Background()
+	userUID := uuid.Must(uuid.NewV4())
+
+	// Create upload parameter with an unknown size
+	uploadParam := miniox.UploadFileStreamParam{
+		UserUID:      userUID,
+		FilePath:     "test/private/video.mp4",
+		Reader:       strings.NewReader("video data"),
+		Size:         -1,
+		PartSize:     32 << 20,
+		FileMimeType: "video/mp4",
+	}
+
+	// Set up mock expectations
+	mockClient.UploadPrivateFileStreamMock.Expect(ctx, uploadParam).Return(nil)
+
+	// Test the method
+	err := mockClient.UploadPrivateFileStream(ctx, uploadParam)
+
+	// Verify results
+	qt.Check(err, quicktest.IsNil)
+}
+
+func TestMinioClient_GetFileStream(t *testing.T) {
+	qt := quicktest.New(t)
+	mc := minimock.NewController(t)
+
+	// Create mock client
+	mockClient := mockminio.NewClientMock(mc)
+
+	ctx := context.Background()
+	userUID := uuid.Must(uuid.NewV4())
+	filePath := "test/video.mp4"
+	byteRange := miniox.ByteRange{Offset: 4, Length: 4}
+	expectedObjectInfo := &miniogo.ObjectInfo{Size: 4}
+
+	// Set up mock expectations
+	mockClient.GetFileStreamMock.Expect(ctx, userUID, filePath, byteRange).
+		Return(io.NopCloser(strings.NewReader("data")), expectedObjectInfo, nil)
+
+	// Test the method
+	object, objectInfo, err := mockClient.GetFileStream(ctx, userUID, filePath, byteRange)
+	qt.Assert(err, quicktest.IsNil)
+	defer object.Close()
+
+	// Verify results
+	content, err := io.ReadAll(object)
+	qt.Check(err, quicktest.IsNil)
+	qt.Check(string(content), quicktest.Equals, "data")
+	qt.Check(objectInfo, quicktest.Equals, expectedObjectInfo)
+}
+
 func TestMinioClient_GetFilesByPaths(t *testing.T) {
 	qt := quicktest.New(t)
 	mc := minimock.NewController(t)
@@ -463,6 +525,28 @@ func TestMinioIntegration(t *testing.T) {
 	qt.Check(err, quicktest.IsNil)
 	qt.Check(fileBytes, quicktest.DeepEquals, jsonBytes)
 
+	t.Log("test stream upload and ranged download")
+	streamName, _ := uuid.NewV4()
+	err = mc.UploadPrivateFileStream(ctx, miniox.UploadFileStreamParam{
+		UserUID:      userUID,
+		FilePath:     streamName.String(),
+		Reader:       bytes.NewReader(jsonBytes),
+		Size:         -1,
+		FileMimeType: "application/json",
+	})
+	qt.Check(err, quicktest.IsNil)
+
+	object, objectInfo, err := mc.GetFileStream(ctx, userUID, streamName.String(), miniox.ByteRange{Offset: 2, Length: 3})
+	qt.Assert(err, quicktest.IsNil)
+	rangeBytes, err := io.ReadAll(object)
+	qt.Check(object.Close(), quicktest.IsNil)
+	qt.Check(err, quicktest.IsNil)
+	qt.Check(rangeBytes, quicktest.DeepEquals, jsonBytes[2:5])
+	qt.Check(objectInfo.Size, quicktest.Equals, int64(3))
+
+	err = mc.DeleteFile(ctx, userUID, streamName.String())
+	qt.Check(err, quicktest.IsNil)
+
 	err = mc.DeleteFile(ctx, userUID, fileName.String())
 	qt.Check(err, quicktest.IsNil)
 
@@ -4,6 +4,7 @@ package minio
 
 import (
 	"context"
+	"io"
 	"sync"
 	mm_atomic "sync/atomic"
 	mm_time "time"
@@ -41,6 +42,13 @@ type ClientMock struct {
 	beforeGetFileCounter uint64
 	GetFileMock          mClientMockGetFile
 
+	funcGetFileStream          func(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange) (r1 io.ReadCloser, op1 *miniogo.ObjectInfo, err error)
+	funcGetFileStreamOrigin    string
+	inspectFuncGetFileStream   func(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange)
+	afterGetFileStreamCounter  uint64
+	beforeGetFileStreamCounter uint64
+	GetFileStreamMock          mClientMockGetFileStream
+
 	funcGetFilesByPaths          func(ctx context.Context, userUID uuid.UUID, filePaths []string) (fa1 []mm_minio.FileContent, err error)
 	funcGetFilesByPathsOrigin    string
 	inspectFuncGetFilesByPaths   func(ctx context.Context, userUID uuid.UUID, filePaths []string)
@@ -62,6 +70,13 @@ type ClientMock struct {
 	beforeUploadFileBytesCounter uint64
 	UploadFileBytesMock          mClientMockUploadFileBytes
 
+	funcUploadFileStream          func(ctx context.Context, up1 *mm_minio.UploadFileStreamParam) (url string, objectInfo *miniogo.ObjectInfo, err error)
+	funcUploadFileStreamOrigin    string
+	inspectFuncUploadFileStream   func(ctx context.Context, up1 *mm_minio.UploadFileStreamParam)
+	afterUploadFileStreamCounter  uint64
+	beforeUploadFileStreamCounter uint64
+	UploadFileStreamMock          mClientMockUploadFileStream
+
 	funcUploadPrivateFileBytes          func(ctx context.Context, u1 mm_minio.UploadFileBytesParam) (err error)
 	funcUploadPrivateFileBytesOrigin    string
 	inspectFuncUploadPrivateFileBytes   func(ctx context.Context, u1 mm_minio.UploadFileBytesParam)
@@ -69,6 +84,13 @@ type ClientMock struct {
 	beforeUploadPrivateFileBytesCounter uint64
 	UploadPrivateFileBytesMock          mClientMockUploadPrivateFileBytes
 
+	funcUploadPrivateFileStream          func(ctx context.Context, u1 mm_minio.UploadFileStreamParam) (err error)
+	funcUploadPrivateFileStreamOrigin    string
+	inspectFuncUploadPrivateFileStream   func(ctx context.Context, u1 mm_minio.UploadFileStreamParam)
+	afterUploadPrivateFileStreamCounter  uint64
+	beforeUploadPrivateFileStreamCounter uint64
+	UploadPrivateFileStreamMock          mClientMockUploadPrivateFileStream
+
 	funcWithLogger          func(lp1 *zap.Logger) (c1 mm_minio.Client)
 	funcWithLoggerOrigin    string
 	inspectFuncWithLogger   func(lp1 *zap.Logger)
@@ -93,6 +115,9 @@ func NewClientMock(t minimock.Tester) *ClientMock {
 	m.GetFileMock = mClientMockGetFile{mock: m}
 	m.GetFileMock.callArgs = []*ClientMockGetFileParams{}
 
+	m.GetFileStreamMock = mClientMockGetFileStream{mock: m}
+	m.GetFileStreamMock.callArgs = []*ClientMockGetFileStreamParams{}
+
 	m.GetFilesByPathsMock = mClientMockGetFilesByPaths{mock: m}
 	m.GetFilesByPathsMock.callArgs = []*ClientMockGetFilesByPathsParams{}
 
@@ -102,9 +127,15 @@ func NewClientMock(t minimock.Tester) *ClientMock {
 	m.UploadFileBytesMock = mClientMockUploadFileBytes{mock: m}
 	m.UploadFileBytesMock.callArgs = []*ClientMockUploadFileBytesParams{}
 
+	m.UploadFileStreamMock = mClientMockUploadFileStream{mock: m}
+	m.UploadFileStreamMock.callArgs = []*ClientMockUploadFileStreamParams{}
+
 	m.UploadPrivateFileBytesMock = mClientMockUploadPrivateFileBytes{mock: m}
 	m.UploadPrivateFileBytesMock.callArgs = []*ClientMockUploadPrivateFileBytesParams{}
 
+	m.UploadPrivateFileStreamMock = mClientMockUploadPrivateFileStream{mock: m}
+	m.UploadPrivateFileStreamMock.callArgs = []*ClientMockUploadPrivateFileStreamParams{}
+
 	m.WithLoggerMock = mClientMockWithLogger{mock: m}
 	m.WithLoggerMock.callArgs = []*ClientMockWithLoggerParams{}
 
@@ -1046,6 +1077,412 @@ func (m *ClientMock) MinimockGetFileInspect() {
 	}
 }
 
+type mClientMockGetFileStream struct {
+	optional           bool
+	mock               *ClientMock
+	defaultExpectation *ClientMockGetFileStreamExpectation
+	expectations       []*ClientMockGetFileStreamExpectation
+
+	callArgs []*ClientMockGetFileStreamParams
+	mutex    sync.RWMutex
+
+	expectedInvocations       uint64
+	expectedInvocationsOrigin string
+}
+
+// ClientMockGetFileStreamExpectation specifies expectation struct of the Client.GetFileStream
+type ClientMockGetFileStreamExpectation struct {
+	mock               *ClientMock
+	params             *ClientMockGetFileStreamParams
+	paramPtrs          *ClientMockGetFileStreamParamPtrs
+	expectationOrigins ClientMockGetFileStreamExpectationOrigins
+	results            *ClientMockGetFileStreamResults
+	returnOrigin       string
+	Counter            uint64
+}
+
+// ClientMockGetFileStreamParams contains parameters of the Client.GetFileStream
+type ClientMockGetFileStreamParams struct {
+	ctx       context.Context
+	userUID   uuid.UUID
+	filePath  string
+	byteRange mm_minio.ByteRange
+}
+
+// ClientMockGetFileStreamParamPtrs contains pointers to parameters of the Client.GetFileStream
+type ClientMockGetFileStreamParamPtrs struct {
+	ctx       *context.Context
+	userUID   *uuid.UUID
+	filePath  *string
+	byteRange *mm_minio.ByteRange
+}
+
+// ClientMockGetFileStreamResults contains results of the Client.GetFileStream
+type ClientMockGetFileStreamResults struct {
+	r1  io.ReadCloser
+	op1 *miniogo.ObjectInfo
+	err error
+}
+
+// ClientMockGetFileStreamOrigins contains origins of expectations of the Client.GetFileStream
+type ClientMockGetFileStreamExpectationOrigins struct {
+	origin          string
+	originCtx       string
+	originUserUID   string
+	originFilePath  string
+	originByteRange string
+}
+
+// Marks this method to be optional. The default behavior of any method with Return() is '1 or more', meaning
+// the test will fail minimock's automatic final call check if the mocked method was not called at least once.
+// Optional() makes method check to work in '0 or more' mode.
+// It is NOT RECOMMENDED to use this option unless you really need it, as default behaviour helps to
+// catch the problems when the expected method call is totally skipped during test run.
+func (mmGetFileStream *mClientMockGetFileStream) Optional() *mClientMockGetFileStream {
+	mmGetFileStream.optional = true
+	return mmGetFileStream
+}
+
+// Expect sets up expected params for Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) Expect(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange) *mClientMockGetFileStream {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	if mmGetFileStream.defaultExpectation == nil {
+		mmGetFileStream.defaultExpectation = &ClientMockGetFileStreamExpectation{}
+	}
+
+	if mmGetFileStream.defaultExpectation.paramPtrs != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by ExpectParams functions")
+	}
+
+	mmGetFileStream.defaultExpectation.params = &ClientMockGetFileStreamParams{ctx, userUID, filePath, byteRange}
+	mmGetFileStream.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
+	for _, e := range mmGetFileStream.expectations {
+		if minimock.Equal(e.params, mmGetFileStream.defaultExpectation.params) {
+			mmGetFileStream.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetFileStream.defaultExpectation.params)
+		}
+	}
+
+	return mmGetFileStream
+}
+
+// ExpectCtxParam1 sets up expected param ctx for Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) ExpectCtxParam1(ctx context.Context) *mClientMockGetFileStream {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	if mmGetFileStream.defaultExpectation == nil {
+		mmGetFileStream.defaultExpectation = &ClientMockGetFileStreamExpectation{}
+	}
+
+	if mmGetFileStream.defaultExpectation.params != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Expect")
+	}
+
+	if mmGetFileStream.defaultExpectation.paramPtrs == nil {
+		mmGetFileStream.defaultExpectation.paramPtrs = &ClientMockGetFileStreamParamPtrs{}
+	}
+	mmGetFileStream.defaultExpectation.paramPtrs.ctx = &ctx
+	mmGetFileStream.defaultExpectation.expectationOrigins.originCtx = minimock.CallerInfo(1)
+
+	return mmGetFileStream
+}
+
+// ExpectUserUIDParam2 sets up expected param userUID for Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) ExpectUserUIDParam2(userUID uuid.UUID) *mClientMockGetFileStream {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	if mmGetFileStream.defaultExpectation == nil {
+		mmGetFileStream.defaultExpectation = &ClientMockGetFileStreamExpectation{}
+	}
+
+	if mmGetFileStream.defaultExpectation.params != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Expect")
+	}
+
+	if mmGetFileStream.defaultExpectation.paramPtrs == nil {
+		mmGetFileStream.defaultExpectation.paramPtrs = &ClientMockGetFileStreamParamPtrs{}
+	}
+	mmGetFileStream.defaultExpectation.paramPtrs.userUID = &userUID
+	mmGetFileStream.defaultExpectation.expectationOrigins.originUserUID = minimock.CallerInfo(1)
+
+	return mmGetFileStream
+}
+
+// ExpectFilePathParam3 sets up expected param filePath for Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) ExpectFilePathParam3(filePath string) *mClientMockGetFileStream {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	if mmGetFileStream.defaultExpectation == nil {
+		mmGetFileStream.defaultExpectation = &ClientMockGetFileStreamExpectation{}
+	}
+
+	if mmGetFileStream.defaultExpectation.params != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Expect")
+	}
+
+	if mmGetFileStream.defaultExpectation.paramPtrs == nil {
+		mmGetFileStream.defaultExpectation.paramPtrs = &ClientMockGetFileStreamParamPtrs{}
+	}
+	mmGetFileStream.defaultExpectation.paramPtrs.filePath = &filePath
+	mmGetFileStream.defaultExpectation.expectationOrigins.originFilePath = minimock.CallerInfo(1)
+
+	return mmGetFileStream
+}
+
+// ExpectByteRangeParam4 sets up expected param byteRange for Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) ExpectByteRangeParam4(byteRange mm_minio.ByteRange) *mClientMockGetFileStream {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	if mmGetFileStream.defaultExpectation == nil {
+		mmGetFileStream.defaultExpectation = &ClientMockGetFileStreamExpectation{}
+	}
+
+	if mmGetFileStream.defaultExpectation.params != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Expect")
+	}
+
+	if mmGetFileStream.defaultExpectation.paramPtrs == nil {
+		mmGetFileStream.defaultExpectation.paramPtrs = &ClientMockGetFileStreamParamPtrs{}
+	}
+	mmGetFileStream.defaultExpectation.paramPtrs.byteRange = &byteRange
+	mmGetFileStream.defaultExpectation.expectationOrigins.originByteRange = minimock.CallerInfo(1)
+
+	return mmGetFileStream
+}
+
+// Inspect accepts an inspector function that has same arguments as the Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) Inspect(f func(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange)) *mClientMockGetFileStream {
+	if mmGetFileStream.mock.inspectFuncGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("Inspect function is already set for ClientMock.GetFileStream")
+	}
+
+	mmGetFileStream.mock.inspectFuncGetFileStream = f
+
+	return mmGetFileStream
+}
+
+// Return sets up results that will be returned by Client.GetFileStream
+func (mmGetFileStream *mClientMockGetFileStream) Return(r1 io.ReadCloser, op1 *miniogo.ObjectInfo, err error) *ClientMock {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	if mmGetFileStream.defaultExpectation == nil {
+		mmGetFileStream.defaultExpectation = &ClientMockGetFileStreamExpectation{mock: mmGetFileStream.mock}
+	}
+	mmGetFileStream.defaultExpectation.results = &ClientMockGetFileStreamResults{r1, op1, err}
+	mmGetFileStream.defaultExpectation.returnOrigin = minimock.CallerInfo(1)
+	return mmGetFileStream.mock
+}
+
+// Set uses given function f to mock the Client.GetFileStream method
+func (mmGetFileStream *mClientMockGetFileStream) Set(f func(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange) (r1 io.ReadCloser, op1 *miniogo.ObjectInfo, err error)) *ClientMock {
+	if mmGetFileStream.defaultExpectation != nil {
+		mmGetFileStream.mock.t.Fatalf("Default expectation is already set for the Client.GetFileStream method")
+	}
+
+	if len(mmGetFileStream.expectations) > 0 {
+		mmGetFileStream.mock.t.Fatalf("Some expectations are already set for the Client.GetFileStream method")
+	}
+
+	mmGetFileStream.mock.funcGetFileStream = f
+	mmGetFileStream.mock.funcGetFileStreamOrigin = minimock.CallerInfo(1)
+	return mmGetFileStream.mock
+}
+
+// When sets expectation for the Client.GetFileStream which will trigger the result defined by the following
+// Then helper
+func (mmGetFileStream *mClientMockGetFileStream) When(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange) *ClientMockGetFileStreamExpectation {
+	if mmGetFileStream.mock.funcGetFileStream != nil {
+		mmGetFileStream.mock.t.Fatalf("ClientMock.GetFileStream mock is already set by Set")
+	}
+
+	expectation := &ClientMockGetFileStreamExpectation{
+		mock:               mmGetFileStream.mock,
+		params:             &ClientMockGetFileStreamParams{ctx, userUID, filePath, byteRange},
+		expectationOrigins: ClientMockGetFileStreamExpectationOrigins{origin: minimock.CallerInfo(1)},
+	}
+	mmGetFileStream.expectations = append(mmGetFileStream.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Client.GetFileStream return parameters for the expectation previously defined by the When method
+func (e *ClientMockGetFileStreamExpectation) Then(r1 io.ReadCloser, op1 *miniogo.ObjectInfo, err error) *ClientMock {
+	e.results = &ClientMockGetFileStreamResults{r1, op1, err}
+	return e.mock
+}
+
+// Times sets number of times Client.GetFileStream should be invoked
+func (mmGetFileStream *mClientMockGetFileStream) Times(n uint64) *mClientMockGetFileStream {
+	if n == 0 {
+		mmGetFileStream.mock.t.Fatalf("Times of ClientMock.GetFileStream mock can not be zero")
+	}
+	mm_atomic.StoreUint64(&mmGetFileStream.expectedInvocations, n)
+	mmGetFileStream.expectedInvocationsOrigin = minimock.CallerInfo(1)
+	return mmGetFileStream
+}
+
+func (mmGetFileStream *mClientMockGetFileStream) invocationsDone() bool {
+	if len(mmGetFileStream.expectations) == 0 && mmGetFileStream.defaultExpectation == nil && mmGetFileStream.mock.funcGetFileStream == nil {
+		return true
+	}
+
+	totalInvocations := mm_atomic.LoadUint64(&mmGetFileStream.mock.afterGetFileStreamCounter)
+	expectedInvocations := mm_atomic.LoadUint64(&mmGetFileStream.expectedInvocations)
+
+	return totalInvocations > 0 && (expectedInvocations == 0 || expectedInvocations == totalInvocations)
+}
+
+// GetFileStream implements mm_minio.Client
+func (mmGetFileStream *ClientMock) GetFileStream(ctx context.Context, userUID uuid.UUID, filePath string, byteRange mm_minio.ByteRange) (r1 io.ReadCloser, op1 *miniogo.ObjectInfo, err error) {
+	mm_atomic.AddUint64(&mmGetFileStream.beforeGetFileStreamCounter, 1)
+	defer mm_atomic.AddUint64(&mmGetFileStream.afterGetFileStreamCounter, 1)
+
+	mmGetFileStream.t.Helper()
+
+	if mmGetFileStream.inspectFuncGetFileStream != nil {
+		mmGetFileStream.inspectFuncGetFileStream(ctx, userUID, filePath, byteRange)
+	}
+
+	mm_params := ClientMockGetFileStreamParams{ctx, userUID, filePath, byteRange}
+
+	// Record call args
+	mmGetFileStream.GetFileStreamMock.mutex.Lock()
+	mmGetFileStream.GetFileStreamMock.callArgs = append(mmGetFileStream.GetFileStreamMock.callArgs, &mm_params)
+	mmGetFileStream.GetFileStreamMock.mutex.Unlock()
+
+	for _, e := range mmGetFileStream.GetFileStreamMock.expectations {
+		if minimock.Equal(*e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.r1, e.results.op1, e.results.err
+		}
+	}
+
+	if mmGetFileStream.GetFileStreamMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmGetFileStream.GetFileStreamMock.defaultExpectation.Counter, 1)
+		mm_want := mmGetFileStream.GetFileStreamMock.defaultExpectation.params
+		mm_want_ptrs := mmGetFileStream.GetFileStreamMock.defaultExpectation.paramPtrs
+
+		mm_got := ClientMockGetFileStreamParams{ctx, userUID, filePath, byteRange}
+
+		if mm_want_ptrs != nil {
+
+			if mm_want_ptrs.ctx != nil && !minimock.Equal(*mm_want_ptrs.ctx, mm_got.ctx) {
+				mmGetFileStream.t.Errorf("ClientMock.GetFileStream got unexpected parameter ctx, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmGetFileStream.GetFileStreamMock.defaultExpectation.expectationOrigins.originCtx, *mm_want_ptrs.ctx, mm_got.ctx, minimock.Diff(*mm_want_ptrs.ctx, mm_got.ctx))
+			}
+
+			if mm_want_ptrs.userUID != nil && !minimock.Equal(*mm_want_ptrs.userUID, mm_got.userUID) {
+				mmGetFileStream.t.Errorf("ClientMock.GetFileStream got unexpected parameter userUID, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmGetFileStream.GetFileStreamMock.defaultExpectation.expectationOrigins.originUserUID, *mm_want_ptrs.userUID, mm_got.userUID, minimock.Diff(*mm_want_ptrs.userUID, mm_got.userUID))
+			}
+
+			if mm_want_ptrs.filePath != nil && !minimock.Equal(*mm_want_ptrs.filePath, mm_got.filePath) {
+				mmGetFileStream.t.Errorf("ClientMock.GetFileStream got unexpected parameter filePath, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmGetFileStream.GetFileStreamMock.defaultExpectation.expectationOrigins.originFilePath, *mm_want_ptrs.filePath, mm_got.filePath, minimock.Diff(*mm_want_ptrs.filePath, mm_got.filePath))
+			}
+
+			if mm_want_ptrs.byteRange != nil && !minimock.Equal(*mm_want_ptrs.byteRange, mm_got.byteRange) {
+				mmGetFileStream.t.Errorf("ClientMock.GetFileStream got unexpected parameter byteRange, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmGetFileStream.GetFileStreamMock.defaultExpectation.expectationOrigins.originByteRange, *mm_want_ptrs.byteRange, mm_got.byteRange, minimock.Diff(*mm_want_ptrs.byteRange, mm_got.byteRange))
+			}
+
+		} else if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmGetFileStream.t.Errorf("ClientMock.GetFileStream got unexpected parameters, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+				mmGetFileStream.GetFileStreamMock.defaultExpectation.expectationOrigins.origin, *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmGetFileStream.GetFileStreamMock.defaultExpectation.results
+		if mm_results == nil {
+			mmGetFileStream.t.Fatal("No results are set for the ClientMock.GetFileStream")
+		}
+		return (*mm_results).r1, (*mm_results).op1, (*mm_results).err
+	}
+	if mmGetFileStream.funcGetFileStream != nil {
+		return mmGetFileStream.funcGetFileStream(ctx, userUID, filePath, byteRange)
+	}
+	mmGetFileStream.t.Fatalf("Unexpected call to ClientMock.GetFileStream. %v %v %v %v", ctx, userUID, filePath, byteRange)
+	return
+}
+
+// GetFileStreamAfterCounter returns a count of finished ClientMock.GetFileStream invocations
+func (mmGetFileStream *ClientMock) GetFileStreamAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetFileStream.afterGetFileStreamCounter)
+}
+
+// GetFileStreamBeforeCounter returns a count of ClientMock.GetFileStream invocations
+func (mmGetFileStream *ClientMock) GetFileStreamBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmGetFileStream.beforeGetFileStreamCounter)
+}
+
+// Calls returns a list of arguments used in each call to ClientMock.GetFileStream.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmGetFileStream *mClientMockGetFileStream) Calls() []*ClientMockGetFileStreamParams {
+	mmGetFileStream.mutex.RLock()
+
+	argCopy := make([]*ClientMockGetFileStreamParams, len(mmGetFileStream.callArgs))
+	copy(argCopy, mmGetFileStream.callArgs)
+
+	mmGetFileStream.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockGetFileStreamDone returns true if the count of the GetFileStream invocations corresponds
+// the number of defined expectations
+func (m *ClientMock) MinimockGetFileStreamDone() bool {
+	if m.GetFileStreamMock.optional {
+		// Optional methods provide '0 or more' call count restriction.
+		return true
+	}
+
+	for _, e := range m.GetFileStreamMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	return m.GetFileStreamMock.invocationsDone()
+}
+
+// MinimockGetFileStreamInspect logs each unmet expectation
+func (m *ClientMock) MinimockGetFileStreamInspect() {
+	for _, e := range m.GetFileStreamMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to ClientMock.GetFileStream at\n%s with params: %#v", e.expectationOrigins.origin, *e.params)
+		}
+	}
+
+	afterGetFileStreamCounter := mm_atomic.LoadUint64(&m.afterGetFileStreamCounter)
+	// if default expectation was set then invocations count should be greater than zero
+	if m.GetFileStreamMock.defaultExpectation != nil && afterGetFileStreamCounter < 1 {
+		if m.GetFileStreamMock.defaultExpectation.params == nil {
+			m.t.Errorf("Expected call to ClientMock.GetFileStream at\n%s", m.GetFileStreamMock.defaultExpectation.returnOrigin)
+		} else {
+			m.t.Errorf("Expected call to ClientMock.GetFileStream at\n%s with params: %#v", m.GetFileStreamMock.defaultExpectation.expectationOrigins.origin, *m.GetFileStreamMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcGetFileStream != nil && afterGetFileStreamCounter < 1 {
+		m.t.Errorf("Expected call to ClientMock.GetFileStream at\n%s", m.funcGetFileStreamOrigin)
+	}
+
+	if !m.GetFileStreamMock.invocationsDone() && afterGetFileStreamCounter > 0 {
+		m.t.Errorf("Expected %d calls to ClientMock.GetFileStream at\n%s but found %d calls",
+			mm_atomic.LoadUint64(&m.GetFileStreamMock.expectedInvocations), m.GetFileStreamMock.expectedInvocationsOrigin, afterGetFileStreamCounter)
+	}
+}
+
 type mClientMockGetFilesByPaths struct {
 	optional           bool
 	mock               *ClientMock
@@ -2108,52 +2545,54 @@ func (m *ClientMock) MinimockUploadFileBytesInspect() {
 	}
 }
 
-type mClientMockUploadPrivateFileBytes struct {
+type mClientMockUploadFileStream struct {
 	optional           bool
 	mock               *ClientMock
-	defaultExpectation *ClientMockUploadPrivateFileBytesExpectation
-	expectations       []*ClientMockUploadPrivateFileBytesExpectation
+	defaultExpectation *ClientMockUploadFileStreamExpectation
+	expectations       []*ClientMockUploadFileStreamExpectation
 
-	callArgs []*ClientMockUploadPrivateFileBytesParams
+	callArgs []*ClientMockUploadFileStreamParams
 	mutex    sync.RWMutex
 
 	expectedInvocations       uint64
 	expectedInvocationsOrigin string
 }
 
-// ClientMockUploadPrivateFileBytesExpectation specifies expectation struct of the Client.UploadPrivateFileBytes
-type ClientMockUploadPrivateFileBytesExpectation struct {
+// ClientMockUploadFileStreamExpectation specifies expectation struct of the Client.UploadFileStream
+type ClientMockUploadFileStreamExpectation struct {
 	mock               *ClientMock
-	params             *ClientMockUploadPrivateFileBytesParams
-	paramPtrs          *ClientMockUploadPrivateFileBytesParamPtrs
-	expectationOrigins ClientMockUploadPrivateFileBytesExpectationOrigins
-	results            *ClientMockUploadPrivateFileBytesResults
+	params             *ClientMockUploadFileStreamParams
+	paramPtrs          *ClientMockUploadFileStreamParamPtrs
+	expectationOrigins ClientMockUploadFileStreamExpectationOrigins
+	results            *ClientMockUploadFileStreamResults
 	returnOrigin       string
 	Counter            uint64
 }
 
-// ClientMockUploadPrivateFileBytesParams contains parameters of the Client.UploadPrivateFileBytes
-type ClientMockUploadPrivateFileBytesParams struct {
+// ClientMockUploadFileStreamParams contains parameters of the Client.UploadFileStream
+type ClientMockUploadFileStreamParams struct {
 	ctx context.Context
-	u1  mm_minio.UploadFileBytesParam
+	up1 *mm_minio.UploadFileStreamParam
 }
 
-// ClientMockUploadPrivateFileBytesParamPtrs contains pointers to parameters of the Client.UploadPrivateFileBytes
-type ClientMockUploadPrivateFileBytesParamPtrs struct {
+// ClientMockUploadFileStreamParamPtrs contains pointers to parameters of the Client.UploadFileStream
+type ClientMockUploadFileStreamParamPtrs struct {
 	ctx *context.Context
-	u1  *mm_minio.UploadFileBytesParam
+	up1 **mm_minio.UploadFileStreamParam
 }
 
-// ClientMockUploadPrivateFileBytesResults contains results of the Client.UploadPrivateFileBytes
-type ClientMockUploadPrivateFileBytesResults struct {
-	err error
+// ClientMockUploadFileStreamResults contains results of the Client.UploadFileStream
+type ClientMockUploadFileStreamResults struct {
+	url        string
+	objectInfo *miniogo.ObjectInfo
+	err        error
 }
 
-// ClientMockUploadPrivateFileBytesOrigins contains origins of expectations of the Client.UploadPrivateFileBytes
-type ClientMockUploadPrivateFileBytesExpectationOrigins struct {
+// ClientMockUploadFileStreamOrigins contains origins of expectations of the Client.UploadFileStream
+type ClientMockUploadFileStreamExpectationOrigins struct {
 	origin    string
 	originCtx string
-	originU1  string
+	originUp1 string
 }
 
 // Marks this method to be optional. The default behavior of any method with Return() is '1 or more', meaning
@@ -2161,48 +2600,390 @@ type ClientMockUploadPrivateFileBytesExpectationOrigins struct {
 // Optional() makes method check to work in '0 or more' mode.
 // It is NOT RECOMMENDED to use this option unless you really need it, as default behaviour helps to
 // catch the problems when the expected method call is totally skipped during test run.
-func (mmUploadPrivateFileBytes *mClientMockUploadPrivateFileBytes) Optional() *mClientMockUploadPrivateFileBytes {
-	mmUploadPrivateFileBytes.optional = true
-	return mmUploadPrivateFileBytes
+func (mmUploadFileStream *mClientMockUploadFileStream) Optional() *mClientMockUploadFileStream {
+	mmUploadFileStream.optional = true
+	return mmUploadFileStream
 }
 
-// Expect sets up expected params for Client.UploadPrivateFileBytes
-func (mmUploadPrivateFileBytes *mClientMockUploadPrivateFileBytes) Expect(ctx context.Context, u1 mm_minio.UploadFileBytesParam) *mClientMockUploadPrivateFileBytes {
-	if mmUploadPrivateFileBytes.mock.funcUploadPrivateFileBytes != nil {
-		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by Set")
+// Expect sets up expected params for Client.UploadFileStream
+func (mmUploadFileStream *mClientMockUploadFileStream) Expect(ctx context.Context, up1 *mm_minio.UploadFileStreamParam) *mClientMockUploadFileStream {
+	if mmUploadFileStream.mock.funcUploadFileStream != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Set")
 	}
 
-	if mmUploadPrivateFileBytes.defaultExpectation == nil {
-		mmUploadPrivateFileBytes.defaultExpectation = &ClientMockUploadPrivateFileBytesExpectation{}
+	if mmUploadFileStream.defaultExpectation == nil {
+		mmUploadFileStream.defaultExpectation = &ClientMockUploadFileStreamExpectation{}
 	}
 
-	if mmUploadPrivateFileBytes.defaultExpectation.paramPtrs != nil {
-		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by ExpectParams functions")
+	if mmUploadFileStream.defaultExpectation.paramPtrs != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by ExpectParams functions")
 	}
 
-	mmUploadPrivateFileBytes.defaultExpectation.params = &ClientMockUploadPrivateFileBytesParams{ctx, u1}
-	mmUploadPrivateFileBytes.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
-	for _, e := range mmUploadPrivateFileBytes.expectations {
-		if minimock.Equal(e.params, mmUploadPrivateFileBytes.defaultExpectation.params) {
-			mmUploadPrivateFileBytes.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUploadPrivateFileBytes.defaultExpectation.params)
+	mmUploadFileStream.defaultExpectation.params = &ClientMockUploadFileStreamParams{ctx, up1}
+	mmUploadFileStream.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
+	for _, e := range mmUploadFileStream.expectations {
+		if minimock.Equal(e.params, mmUploadFileStream.defaultExpectation.params) {
+			mmUploadFileStream.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUploadFileStream.defaultExpectation.params)
 		}
 	}
 
-	return mmUploadPrivateFileBytes
+	return mmUploadFileStream
 }
 
-// ExpectCtxParam1 sets up expected param ctx for Client.UploadPrivateFileBytes
-func (mmUploadPrivateFileBytes *mClientMockUploadPrivateFileBytes) ExpectCtxParam1(ctx context.Context) *mClientMockUploadPrivateFileBytes {
-	if mmUploadPrivateFileBytes.mock.funcUploadPrivateFileBytes != nil {
-		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by Set")
+// ExpectCtxParam1 sets up expected param ctx for Client.UploadFileStream
+func (mmUploadFileStream *mClientMockUploadFileStream) ExpectCtxParam1(ctx context.Context) *mClientMockUploadFileStream {
+	if mmUploadFileStream.mock.funcUploadFileStream != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Set")
 	}
 
-	if mmUploadPrivateFileBytes.defaultExpectation == nil {
-		mmUploadPrivateFileBytes.defaultExpectation = &ClientMockUploadPrivateFileBytesExpectation{}
+	if mmUploadFileStream.defaultExpectation == nil {
+		mmUploadFileStream.defaultExpectation = &ClientMockUploadFileStreamExpectation{}
 	}
 
-	if mmUploadPrivateFileBytes.defaultExpectation.params != nil {
-		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by Expect")
+	if mmUploadFileStream.defaultExpectation.params != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Expect")
+	}
+
+	if mmUploadFileStream.defaultExpectation.paramPtrs == nil {
+		mmUploadFileStream.defaultExpectation.paramPtrs = &ClientMockUploadFileStreamParamPtrs{}
+	}
+	mmUploadFileStream.defaultExpectation.paramPtrs.ctx = &ctx
+	mmUploadFileStream.defaultExpectation.expectationOrigins.originCtx = minimock.CallerInfo(1)
+
+	return mmUploadFileStream
+}
+
+// ExpectUp1Param2 sets up expected param up1 for Client.UploadFileStream
+func (mmUploadFileStream *mClientMockUploadFileStream) ExpectUp1Param2(up1 *mm_minio.UploadFileStreamParam) *mClientMockUploadFileStream {
+	if mmUploadFileStream.mock.funcUploadFileStream != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Set")
+	}
+
+	if mmUploadFileStream.defaultExpectation == nil {
+		mmUploadFileStream.defaultExpectation = &ClientMockUploadFileStreamExpectation{}
+	}
+
+	if mmUploadFileStream.defaultExpectation.params != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Expect")
+	}
+
+	if mmUploadFileStream.defaultExpectation.paramPtrs == nil {
+		mmUploadFileStream.defaultExpectation.paramPtrs = &ClientMockUploadFileStreamParamPtrs{}
+	}
+	mmUploadFileStream.defaultExpectation.paramPtrs.up1 = &up1
+	mmUploadFileStream.defaultExpectation.expectationOrigins.originUp1 = minimock.CallerInfo(1)
+
+	return mmUploadFileStream
+}
+
+// Inspect accepts an inspector function that has same arguments as the Client.UploadFileStream
+func (mmUploadFileStream *mClientMockUploadFileStream) Inspect(f func(ctx context.Context, up1 *mm_minio.UploadFileStreamParam)) *mClientMockUploadFileStream {
+	if mmUploadFileStream.mock.inspectFuncUploadFileStream != nil {
+		mmUploadFileStream.mock.t.Fatalf("Inspect function is already set for ClientMock.UploadFileStream")
+	}
+
+	mmUploadFileStream.mock.inspectFuncUploadFileStream = f
+
+	return mmUploadFileStream
+}
+
+// Return sets up results that will be returned by Client.UploadFileStream
+func (mmUploadFileStream *mClientMockUploadFileStream) Return(url string, objectInfo *miniogo.ObjectInfo, err error) *ClientMock {
+	if mmUploadFileStream.mock.funcUploadFileStream != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Set")
+	}
+
+	if mmUploadFileStream.defaultExpectation == nil {
+		mmUploadFileStream.defaultExpectation = &ClientMockUploadFileStreamExpectation{mock: mmUploadFileStream.mock}
+	}
+	mmUploadFileStream.defaultExpectation.results = &ClientMockUploadFileStreamResults{url, objectInfo, err}
+	mmUploadFileStream.defaultExpectation.returnOrigin = minimock.CallerInfo(1)
+	return mmUploadFileStream.mock
+}
+
+// Set uses given function f to mock the Client.UploadFileStream method
+func (mmUploadFileStream *mClientMockUploadFileStream) Set(f func(ctx context.Context, up1 *mm_minio.UploadFileStreamParam) (url string, objectInfo *miniogo.ObjectInfo, err error)) *ClientMock {
+	if mmUploadFileStream.defaultExpectation != nil {
+		mmUploadFileStream.mock.t.Fatalf("Default expectation is already set for the Client.UploadFileStream method")
+	}
+
+	if len(mmUploadFileStream.expectations) > 0 {
+		mmUploadFileStream.mock.t.Fatalf("Some expectations are already set for the Client.UploadFileStream method")
+	}
+
+	mmUploadFileStream.mock.funcUploadFileStream = f
+	mmUploadFileStream.mock.funcUploadFileStreamOrigin = minimock.CallerInfo(1)
+	return mmUploadFileStream.mock
+}
+
+// When sets expectation for the Client.UploadFileStream which will trigger the result defined by the following
+// Then helper
+func (mmUploadFileStream *mClientMockUploadFileStream) When(ctx context.Context, up1 *mm_minio.UploadFileStreamParam) *ClientMockUploadFileStreamExpectation {
+	if mmUploadFileStream.mock.funcUploadFileStream != nil {
+		mmUploadFileStream.mock.t.Fatalf("ClientMock.UploadFileStream mock is already set by Set")
+	}
+
+	expectation := &ClientMockUploadFileStreamExpectation{
+		mock:               mmUploadFileStream.mock,
+		params:             &ClientMockUploadFileStreamParams{ctx, up1},
+		expectationOrigins: ClientMockUploadFileStreamExpectationOrigins{origin: minimock.CallerInfo(1)},
+	}
+	mmUploadFileStream.expectations = append(mmUploadFileStream.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Client.UploadFileStream return parameters for the expectation previously defined by the When method
+func (e *ClientMockUploadFileStreamExpectation) Then(url string, objectInfo *miniogo.ObjectInfo, err error) *ClientMock {
+	e.results = &ClientMockUploadFileStreamResults{url, objectInfo, err}
+	return e.mock
+}
+
+// Times sets number of times Client.UploadFileStream should be invoked
+func (mmUploadFileStream *mClientMockUploadFileStream) Times(n uint64) *mClientMockUploadFileStream {
+	if n == 0 {
+		mmUploadFileStream.mock.t.Fatalf("Times of ClientMock.UploadFileStream mock can not be zero")
+	}
+	mm_atomic.StoreUint64(&mmUploadFileStream.expectedInvocations, n)
+	mmUploadFileStream.expectedInvocationsOrigin = minimock.CallerInfo(1)
+	return mmUploadFileStream
+}
+
+func (mmUploadFileStream *mClientMockUploadFileStream) invocationsDone() bool {
+	if len(mmUploadFileStream.expectations) == 0 && mmUploadFileStream.defaultExpectation == nil && mmUploadFileStream.mock.funcUploadFileStream == nil {
+		return true
+	}
+
+	totalInvocations := mm_atomic.LoadUint64(&mmUploadFileStream.mock.afterUploadFileStreamCounter)
+	expectedInvocations := mm_atomic.LoadUint64(&mmUploadFileStream.expectedInvocations)
+
+	return totalInvocations > 0 && (expectedInvocations == 0 || expectedInvocations == totalInvocations)
+}
+
+// UploadFileStream implements mm_minio.Client
+func (mmUploadFileStream *ClientMock) UploadFileStream(ctx context.Context, up1 *mm_minio.UploadFileStreamParam) (url string, objectInfo *miniogo.ObjectInfo, err error) {
+	mm_atomic.AddUint64(&mmUploadFileStream.beforeUploadFileStreamCounter, 1)
+	defer mm_atomic.AddUint64(&mmUploadFileStream.afterUploadFileStreamCounter, 1)
+
+	mmUploadFileStream.t.Helper()
+
+	if mmUploadFileStream.inspectFuncUploadFileStream != nil {
+		mmUploadFileStream.inspectFuncUploadFileStream(ctx, up1)
+	}
+
+	mm_params := ClientMockUploadFileStreamParams{ctx, up1}
+
+	// Record call args
+	mmUploadFileStream.UploadFileStreamMock.mutex.Lock()
+	mmUploadFileStream.UploadFileStreamMock.callArgs = append(mmUploadFileStream.UploadFileStreamMock.callArgs, &mm_params)
+	mmUploadFileStream.UploadFileStreamMock.mutex.Unlock()
+
+	for _, e := range mmUploadFileStream.UploadFileStreamMock.expectations {
+		if minimock.Equal(*e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.url, e.results.objectInfo, e.results.err
+		}
+	}
+
+	if mmUploadFileStream.UploadFileStreamMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmUploadFileStream.UploadFileStreamMock.defaultExpectation.Counter, 1)
+		mm_want := mmUploadFileStream.UploadFileStreamMock.defaultExpectation.params
+		mm_want_ptrs := mmUploadFileStream.UploadFileStreamMock.defaultExpectation.paramPtrs
+
+		mm_got := ClientMockUploadFileStreamParams{ctx, up1}
+
+		if mm_want_ptrs != nil {
+
+			if mm_want_ptrs.ctx != nil && !minimock.Equal(*mm_want_ptrs.ctx, mm_got.ctx) {
+				mmUploadFileStream.t.Errorf("ClientMock.UploadFileStream got unexpected parameter ctx, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmUploadFileStream.UploadFileStreamMock.defaultExpectation.expectationOrigins.originCtx, *mm_want_ptrs.ctx, mm_got.ctx, minimock.Diff(*mm_want_ptrs.ctx, mm_got.ctx))
+			}
+
+			if mm_want_ptrs.up1 != nil && !minimock.Equal(*mm_want_ptrs.up1, mm_got.up1) {
+				mmUploadFileStream.t.Errorf("ClientMock.UploadFileStream got unexpected parameter up1, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmUploadFileStream.UploadFileStreamMock.defaultExpectation.expectationOrigins.originUp1, *mm_want_ptrs.up1, mm_got.up1, minimock.Diff(*mm_want_ptrs.up1, mm_got.up1))
+			}
+
+		} else if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmUploadFileStream.t.Errorf("ClientMock.UploadFileStream got unexpected parameters, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+				mmUploadFileStream.UploadFileStreamMock.defaultExpectation.expectationOrigins.origin, *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmUploadFileStream.UploadFileStreamMock.defaultExpectation.results
+		if mm_results == nil {
+			mmUploadFileStream.t.Fatal("No results are set for the ClientMock.UploadFileStream")
+		}
+		return (*mm_results).url, (*mm_results).objectInfo, (*mm_results).err
+	}
+	if mmUploadFileStream.funcUploadFileStream != nil {
+		return mmUploadFileStream.funcUploadFileStream(ctx, up1)
+	}
+	mmUploadFileStream.t.Fatalf("Unexpected call to ClientMock.UploadFileStream. %v %v", ctx, up1)
+	return
+}
+
+// UploadFileStreamAfterCounter returns a count of finished ClientMock.UploadFileStream invocations
+func (mmUploadFileStream *ClientMock) UploadFileStreamAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUploadFileStream.afterUploadFileStreamCounter)
+}
+
+// UploadFileStreamBeforeCounter returns a count of ClientMock.UploadFileStream invocations
+func (mmUploadFileStream *ClientMock) UploadFileStreamBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUploadFileStream.beforeUploadFileStreamCounter)
+}
+
+// Calls returns a list of arguments used in each call to ClientMock.UploadFileStream.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmUploadFileStream *mClientMockUploadFileStream) Calls() []*ClientMockUploadFileStreamParams {
+	mmUploadFileStream.mutex.RLock()
+
+	argCopy := make([]*ClientMockUploadFileStreamParams, len(mmUploadFileStream.callArgs))
+	copy(argCopy, mmUploadFileStream.callArgs)
+
+	mmUploadFileStream.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockUploadFileStreamDone returns true if the count of the UploadFileStream invocations corresponds
+// the number of defined expectations
+func (m *ClientMock) MinimockUploadFileStreamDone() bool {
+	if m.UploadFileStreamMock.optional {
+		// Optional methods provide '0 or more' call count restriction.
+		return true
+	}
+
+	for _, e := range m.UploadFileStreamMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	return m.UploadFileStreamMock.invocationsDone()
+}
+
+// MinimockUploadFileStreamInspect logs each unmet expectation
+func (m *ClientMock) MinimockUploadFileStreamInspect() {
+	for _, e := range m.UploadFileStreamMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to ClientMock.UploadFileStream at\n%s with params: %#v", e.expectationOrigins.origin, *e.params)
+		}
+	}
+
+	afterUploadFileStreamCounter := mm_atomic.LoadUint64(&m.afterUploadFileStreamCounter)
+	// if default expectation was set then invocations count should be greater than zero
+	if m.UploadFileStreamMock.defaultExpectation != nil && afterUploadFileStreamCounter < 1 {
+		if m.UploadFileStreamMock.defaultExpectation.params == nil {
+			m.t.Errorf("Expected call to ClientMock.UploadFileStream at\n%s", m.UploadFileStreamMock.defaultExpectation.returnOrigin)
+		} else {
+			m.t.Errorf("Expected call to ClientMock.UploadFileStream at\n%s with params: %#v", m.UploadFileStreamMock.defaultExpectation.expectationOrigins.origin, *m.UploadFileStreamMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcUploadFileStream != nil && afterUploadFileStreamCounter < 1 {
+		m.t.Errorf("Expected call to ClientMock.UploadFileStream at\n%s", m.funcUploadFileStreamOrigin)
+	}
+
+	if !m.UploadFileStreamMock.invocationsDone() && afterUploadFileStreamCounter > 0 {
+		m.t.Errorf("Expected %d calls to ClientMock.UploadFileStream at\n%s but found %d calls",
+			mm_atomic.LoadUint64(&m.UploadFileStreamMock.expectedInvocations), m.UploadFileStreamMock.expectedInvocationsOrigin, afterUploadFileStreamCounter)
+	}
+}
+
+type mClientMockUploadPrivateFileBytes struct {
+	optional           bool
+	mock               *ClientMock
+	defaultExpectation *ClientMockUploadPrivateFileBytesExpectation
+	expectations       []*ClientMockUploadPrivateFileBytesExpectation
+
+	callArgs []*ClientMockUploadPrivateFileBytesParams
+	mutex    sync.RWMutex
+
+	expectedInvocations       uint64
+	expectedInvocationsOrigin string
+}
+
+// ClientMockUploadPrivateFileBytesExpectation specifies expectation struct of the Client.UploadPrivateFileBytes
+type ClientMockUploadPrivateFileBytesExpectation struct {
+	mock               *ClientMock
+	params             *ClientMockUploadPrivateFileBytesParams
+	paramPtrs          *ClientMockUploadPrivateFileBytesParamPtrs
+	expectationOrigins ClientMockUploadPrivateFileBytesExpectationOrigins
+	results            *ClientMockUploadPrivateFileBytesResults
+	returnOrigin       string
+	Counter            uint64
+}
+
+// ClientMockUploadPrivateFileBytesParams contains parameters of the Client.UploadPrivateFileBytes
+type ClientMockUploadPrivateFileBytesParams struct {
+	ctx context.Context
+	u1  mm_minio.UploadFileBytesParam
+}
+
+// ClientMockUploadPrivateFileBytesParamPtrs contains pointers to parameters of the Client.UploadPrivateFileBytes
+type ClientMockUploadPrivateFileBytesParamPtrs struct {
+	ctx *context.Context
+	u1  *mm_minio.UploadFileBytesParam
+}
+
+// ClientMockUploadPrivateFileBytesResults contains results of the Client.UploadPrivateFileBytes
+type ClientMockUploadPrivateFileBytesResults struct {
+	err error
+}
+
+// ClientMockUploadPrivateFileBytesOrigins contains origins of expectations of the Client.UploadPrivateFileBytes
+type ClientMockUploadPrivateFileBytesExpectationOrigins struct {
+	origin    string
+	originCtx string
+	originU1  string
+}
+
+// Marks this method to be optional. The default behavior of any method with Return() is '1 or more', meaning
+// the test will fail minimock's automatic final call check if the mocked method was not called at least once.
+// Optional() makes method check to work in '0 or more' mode.
+// It is NOT RECOMMENDED to use this option unless you really need it, as default behaviour helps to
+// catch the problems when the expected method call is totally skipped during test run.
+func (mmUploadPrivateFileBytes *mClientMockUploadPrivateFileBytes) Optional() *mClientMockUploadPrivateFileBytes {
+	mmUploadPrivateFileBytes.optional = true
+	return mmUploadPrivateFileBytes
+}
+
+// Expect sets up expected params for Client.UploadPrivateFileBytes
+func (mmUploadPrivateFileBytes *mClientMockUploadPrivateFileBytes) Expect(ctx context.Context, u1 mm_minio.UploadFileBytesParam) *mClientMockUploadPrivateFileBytes {
+	if mmUploadPrivateFileBytes.mock.funcUploadPrivateFileBytes != nil {
+		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by Set")
+	}
+
+	if mmUploadPrivateFileBytes.defaultExpectation == nil {
+		mmUploadPrivateFileBytes.defaultExpectation = &ClientMockUploadPrivateFileBytesExpectation{}
+	}
+
+	if mmUploadPrivateFileBytes.defaultExpectation.paramPtrs != nil {
+		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by ExpectParams functions")
+	}
+
+	mmUploadPrivateFileBytes.defaultExpectation.params = &ClientMockUploadPrivateFileBytesParams{ctx, u1}
+	mmUploadPrivateFileBytes.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
+	for _, e := range mmUploadPrivateFileBytes.expectations {
+		if minimock.Equal(e.params, mmUploadPrivateFileBytes.defaultExpectation.params) {
+			mmUploadPrivateFileBytes.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUploadPrivateFileBytes.defaultExpectation.params)
+		}
+	}
+
+	return mmUploadPrivateFileBytes
+}
+
+// ExpectCtxParam1 sets up expected param ctx for Client.UploadPrivateFileBytes
+func (mmUploadPrivateFileBytes *mClientMockUploadPrivateFileBytes) ExpectCtxParam1(ctx context.Context) *mClientMockUploadPrivateFileBytes {
+	if mmUploadPrivateFileBytes.mock.funcUploadPrivateFileBytes != nil {
+		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by Set")
+	}
+
+	if mmUploadPrivateFileBytes.defaultExpectation == nil {
+		mmUploadPrivateFileBytes.defaultExpectation = &ClientMockUploadPrivateFileBytesExpectation{}
+	}
+
+	if mmUploadPrivateFileBytes.defaultExpectation.params != nil {
+		mmUploadPrivateFileBytes.mock.t.Fatalf("ClientMock.UploadPrivateFileBytes mock is already set by Expect")
 	}
 
 	if mmUploadPrivateFileBytes.defaultExpectation.paramPtrs == nil {
@@ -2450,6 +3231,348 @@ func (m *ClientMock) MinimockUploadPrivateFileBytesInspect() {
 	}
 }
 
+type mClientMockUploadPrivateFileStream struct {
+	optional           bool
+	mock               *ClientMock
+	defaultExpectation *ClientMockUploadPrivateFileStreamExpectation
+	expectations       []*ClientMockUploadPrivateFileStreamExpectation
+
+	callArgs []*ClientMockUploadPrivateFileStreamParams
+	mutex    sync.RWMutex
+
+	expectedInvocations       uint64
+	expectedInvocationsOrigin string
+}
+
+// ClientMockUploadPrivateFileStreamExpectation specifies expectation struct of the Client.UploadPrivateFileStream
+type ClientMockUploadPrivateFileStreamExpectation struct {
+	mock               *ClientMock
+	params             *ClientMockUploadPrivateFileStreamParams
+	paramPtrs          *ClientMockUploadPrivateFileStreamParamPtrs
+	expectationOrigins ClientMockUploadPrivateFileStreamExpectationOrigins
+	results            *ClientMockUploadPrivateFileStreamResults
+	returnOrigin       string
+	Counter            uint64
+}
+
+// ClientMockUploadPrivateFileStreamParams contains parameters of the Client.UploadPrivateFileStream
+type ClientMockUploadPrivateFileStreamParams struct {
+	ctx context.Context
+	u1  mm_minio.UploadFileStreamParam
+}
+
+// ClientMockUploadPrivateFileStreamParamPtrs contains pointers to parameters of the Client.UploadPrivateFileStream
+type ClientMockUploadPrivateFileStreamParamPtrs struct {
+	ctx *context.Context
+	u1  *mm_minio.UploadFileStreamParam
+}
+
+// ClientMockUploadPrivateFileStreamResults contains results of the Client.UploadPrivateFileStream
+type ClientMockUploadPrivateFileStreamResults struct {
+	err error
+}
+
+// ClientMockUploadPrivateFileStreamOrigins contains origins of expectations of the Client.UploadPrivateFileStream
+type ClientMockUploadPrivateFileStreamExpectationOrigins struct {
+	origin    string
+	originCtx string
+	originU1  string
+}
+
+// Marks this method to be optional. The default behavior of any method with Return() is '1 or more', meaning
+// the test will fail minimock's automatic final call check if the mocked method was not called at least once.
+// Optional() makes method check to work in '0 or more' mode.
+// It is NOT RECOMMENDED to use this option unless you really need it, as default behaviour helps to
+// catch the problems when the expected method call is totally skipped during test run.
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Optional() *mClientMockUploadPrivateFileStream {
+	mmUploadPrivateFileStream.optional = true
+	return mmUploadPrivateFileStream
+}
+
+// Expect sets up expected params for Client.UploadPrivateFileStream
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Expect(ctx context.Context, u1 mm_minio.UploadFileStreamParam) *mClientMockUploadPrivateFileStream {
+	if mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Set")
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation == nil {
+		mmUploadPrivateFileStream.defaultExpectation = &ClientMockUploadPrivateFileStreamExpectation{}
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation.paramPtrs != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by ExpectParams functions")
+	}
+
+	mmUploadPrivateFileStream.defaultExpectation.params = &ClientMockUploadPrivateFileStreamParams{ctx, u1}
+	mmUploadPrivateFileStream.defaultExpectation.expectationOrigins.origin = minimock.CallerInfo(1)
+	for _, e := range mmUploadPrivateFileStream.expectations {
+		if minimock.Equal(e.params, mmUploadPrivateFileStream.defaultExpectation.params) {
+			mmUploadPrivateFileStream.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUploadPrivateFileStream.defaultExpectation.params)
+		}
+	}
+
+	return mmUploadPrivateFileStream
+}
+
+// ExpectCtxParam1 sets up expected param ctx for Client.UploadPrivateFileStream
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) ExpectCtxParam1(ctx context.Context) *mClientMockUploadPrivateFileStream {
+	if mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Set")
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation == nil {
+		mmUploadPrivateFileStream.defaultExpectation = &ClientMockUploadPrivateFileStreamExpectation{}
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation.params != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Expect")
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation.paramPtrs == nil {
+		mmUploadPrivateFileStream.defaultExpectation.paramPtrs = &ClientMockUploadPrivateFileStreamParamPtrs{}
+	}
+	mmUploadPrivateFileStream.defaultExpectation.paramPtrs.ctx = &ctx
+	mmUploadPrivateFileStream.defaultExpectation.expectationOrigins.originCtx = minimock.CallerInfo(1)
+
+	return mmUploadPrivateFileStream
+}
+
+// ExpectU1Param2 sets up expected param u1 for Client.UploadPrivateFileStream
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) ExpectU1Param2(u1 mm_minio.UploadFileStreamParam) *mClientMockUploadPrivateFileStream {
+	if mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Set")
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation == nil {
+		mmUploadPrivateFileStream.defaultExpectation = &ClientMockUploadPrivateFileStreamExpectation{}
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation.params != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Expect")
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation.paramPtrs == nil {
+		mmUploadPrivateFileStream.defaultExpectation.paramPtrs = &ClientMockUploadPrivateFileStreamParamPtrs{}
+	}
+	mmUploadPrivateFileStream.defaultExpectation.paramPtrs.u1 = &u1
+	mmUploadPrivateFileStream.defaultExpectation.expectationOrigins.originU1 = minimock.CallerInfo(1)
+
+	return mmUploadPrivateFileStream
+}
+
+// Inspect accepts an inspector function that has same arguments as the Client.UploadPrivateFileStream
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Inspect(f func(ctx context.Context, u1 mm_minio.UploadFileStreamParam)) *mClientMockUploadPrivateFileStream {
+	if mmUploadPrivateFileStream.mock.inspectFuncUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("Inspect function is already set for ClientMock.UploadPrivateFileStream")
+	}
+
+	mmUploadPrivateFileStream.mock.inspectFuncUploadPrivateFileStream = f
+
+	return mmUploadPrivateFileStream
+}
+
+// Return sets up results that will be returned by Client.UploadPrivateFileStream
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Return(err error) *ClientMock {
+	if mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Set")
+	}
+
+	if mmUploadPrivateFileStream.defaultExpectation == nil {
+		mmUploadPrivateFileStream.defaultExpectation = &ClientMockUploadPrivateFileStreamExpectation{mock: mmUploadPrivateFileStream.mock}
+	}
+	mmUploadPrivateFileStream.defaultExpectation.results = &ClientMockUploadPrivateFileStreamResults{err}
+	mmUploadPrivateFileStream.defaultExpectation.returnOrigin = minimock.CallerInfo(1)
+	return mmUploadPrivateFileStream.mock
+}
+
+// Set uses given function f to mock the Client.UploadPrivateFileStream method
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Set(f func(ctx context.Context, u1 mm_minio.UploadFileStreamParam) (err error)) *ClientMock {
+	if mmUploadPrivateFileStream.defaultExpectation != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("Default expectation is already set for the Client.UploadPrivateFileStream method")
+	}
+
+	if len(mmUploadPrivateFileStream.expectations) > 0 {
+		mmUploadPrivateFileStream.mock.t.Fatalf("Some expectations are already set for the Client.UploadPrivateFileStream method")
+	}
+
+	mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream = f
+	mmUploadPrivateFileStream.mock.funcUploadPrivateFileStreamOrigin = minimock.CallerInfo(1)
+	return mmUploadPrivateFileStream.mock
+}
+
+// When sets expectation for the Client.UploadPrivateFileStream which will trigger the result defined by the following
+// Then helper
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) When(ctx context.Context, u1 mm_minio.UploadFileStreamParam) *ClientMockUploadPrivateFileStreamExpectation {
+	if mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.mock.t.Fatalf("ClientMock.UploadPrivateFileStream mock is already set by Set")
+	}
+
+	expectation := &ClientMockUploadPrivateFileStreamExpectation{
+		mock:               mmUploadPrivateFileStream.mock,
+		params:             &ClientMockUploadPrivateFileStreamParams{ctx, u1},
+		expectationOrigins: ClientMockUploadPrivateFileStreamExpectationOrigins{origin: minimock.CallerInfo(1)},
+	}
+	mmUploadPrivateFileStream.expectations = append(mmUploadPrivateFileStream.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Client.UploadPrivateFileStream return parameters for the expectation previously defined by the When method
+func (e *ClientMockUploadPrivateFileStreamExpectation) Then(err error) *ClientMock {
+	e.results = &ClientMockUploadPrivateFileStreamResults{err}
+	return e.mock
+}
+
+// Times sets number of times Client.UploadPrivateFileStream should be invoked
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Times(n uint64) *mClientMockUploadPrivateFileStream {
+	if n == 0 {
+		mmUploadPrivateFileStream.mock.t.Fatalf("Times of ClientMock.UploadPrivateFileStream mock can not be zero")
+	}
+	mm_atomic.StoreUint64(&mmUploadPrivateFileStream.expectedInvocations, n)
+	mmUploadPrivateFileStream.expectedInvocationsOrigin = minimock.CallerInfo(1)
+	return mmUploadPrivateFileStream
+}
+
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) invocationsDone() bool {
+	if len(mmUploadPrivateFileStream.expectations) == 0 && mmUploadPrivateFileStream.defaultExpectation == nil && mmUploadPrivateFileStream.mock.funcUploadPrivateFileStream == nil {
+		return true
+	}
+
+	totalInvocations := mm_atomic.LoadUint64(&mmUploadPrivateFileStream.mock.afterUploadPrivateFileStreamCounter)
+	expectedInvocations := mm_atomic.LoadUint64(&mmUploadPrivateFileStream.expectedInvocations)
+
+	return totalInvocations > 0 && (expectedInvocations == 0 || expectedInvocations == totalInvocations)
+}
+
+// UploadPrivateFileStream implements mm_minio.Client
+func (mmUploadPrivateFileStream *ClientMock) UploadPrivateFileStream(ctx context.Context, u1 mm_minio.UploadFileStreamParam) (err error) {
+	mm_atomic.AddUint64(&mmUploadPrivateFileStream.beforeUploadPrivateFileStreamCounter, 1)
+	defer mm_atomic.AddUint64(&mmUploadPrivateFileStream.afterUploadPrivateFileStreamCounter, 1)
+
+	mmUploadPrivateFileStream.t.Helper()
+
+	if mmUploadPrivateFileStream.inspectFuncUploadPrivateFileStream != nil {
+		mmUploadPrivateFileStream.inspectFuncUploadPrivateFileStream(ctx, u1)
+	}
+
+	mm_params := ClientMockUploadPrivateFileStreamParams{ctx, u1}
+
+	// Record call args
+	mmUploadPrivateFileStream.UploadPrivateFileStreamMock.mutex.Lock()
+	mmUploadPrivateFileStream.UploadPrivateFileStreamMock.callArgs = append(mmUploadPrivateFileStream.UploadPrivateFileStreamMock.callArgs, &mm_params)
+	mmUploadPrivateFileStream.UploadPrivateFileStreamMock.mutex.Unlock()
+
+	for _, e := range mmUploadPrivateFileStream.UploadPrivateFileStreamMock.expectations {
+		if minimock.Equal(*e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.err
+		}
+	}
+
+	if mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.Counter, 1)
+		mm_want := mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.params
+		mm_want_ptrs := mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.paramPtrs
+
+		mm_got := ClientMockUploadPrivateFileStreamParams{ctx, u1}
+
+		if mm_want_ptrs != nil {
+
+			if mm_want_ptrs.ctx != nil && !minimock.Equal(*mm_want_ptrs.ctx, mm_got.ctx) {
+				mmUploadPrivateFileStream.t.Errorf("ClientMock.UploadPrivateFileStream got unexpected parameter ctx, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.expectationOrigins.originCtx, *mm_want_ptrs.ctx, mm_got.ctx, minimock.Diff(*mm_want_ptrs.ctx, mm_got.ctx))
+			}
+
+			if mm_want_ptrs.u1 != nil && !minimock.Equal(*mm_want_ptrs.u1, mm_got.u1) {
+				mmUploadPrivateFileStream.t.Errorf("ClientMock.UploadPrivateFileStream got unexpected parameter u1, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+					mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.expectationOrigins.originU1, *mm_want_ptrs.u1, mm_got.u1, minimock.Diff(*mm_want_ptrs.u1, mm_got.u1))
+			}
+
+		} else if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmUploadPrivateFileStream.t.Errorf("ClientMock.UploadPrivateFileStream got unexpected parameters, expected at\n%s:\nwant: %#v\n got: %#v%s\n",
+				mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.expectationOrigins.origin, *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmUploadPrivateFileStream.UploadPrivateFileStreamMock.defaultExpectation.results
+		if mm_results == nil {
+			mmUploadPrivateFileStream.t.Fatal("No results are set for the ClientMock.UploadPrivateFileStream")
+		}
+		return (*mm_results).err
+	}
+	if mmUploadPrivateFileStream.funcUploadPrivateFileStream != nil {
+		return mmUploadPrivateFileStream.funcUploadPrivateFileStream(ctx, u1)
+	}
+	mmUploadPrivateFileStream.t.Fatalf("Unexpected call to ClientMock.UploadPrivateFileStream. %v %v", ctx, u1)
+	return
+}
+
+// UploadPrivateFileStreamAfterCounter returns a count of finished ClientMock.UploadPrivateFileStream invocations
+func (mmUploadPrivateFileStream *ClientMock) UploadPrivateFileStreamAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUploadPrivateFileStream.afterUploadPrivateFileStreamCounter)
+}
+
+// UploadPrivateFileStreamBeforeCounter returns a count of ClientMock.UploadPrivateFileStream invocations
+func (mmUploadPrivateFileStream *ClientMock) UploadPrivateFileStreamBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUploadPrivateFileStream.beforeUploadPrivateFileStreamCounter)
+}
+
+// Calls returns a list of arguments used in each call to ClientMock.UploadPrivateFileStream.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmUploadPrivateFileStream *mClientMockUploadPrivateFileStream) Calls() []*ClientMockUploadPrivateFileStreamParams {
+	mmUploadPrivateFileStream.mutex.RLock()
+
+	argCopy := make([]*ClientMockUploadPrivateFileStreamParams, len(mmUploadPrivateFileStream.callArgs))
+	copy(argCopy, mmUploadPrivateFileStream.callArgs)
+
+	mmUploadPrivateFileStream.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockUploadPrivateFileStreamDone returns true if the count of the UploadPrivateFileStream invocations corresponds
+// the number of defined expectations
+func (m *ClientMock) MinimockUploadPrivateFileStreamDone() bool {
+	if m.UploadPrivateFileStreamMock.optional {
+		// Optional methods provide '0 or more' call count restriction.
+		return true
+	}
+
+	for _, e := range m.UploadPrivateFileStreamMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	return m.UploadPrivateFileStreamMock.invocationsDone()
+}
+
+// MinimockUploadPrivateFileStreamInspect logs each unmet expectation
+func (m *ClientMock) MinimockUploadPrivateFileStreamInspect() {
+	for _, e := range m.UploadPrivateFileStreamMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to ClientMock.UploadPrivateFileStream at\n%s with params: %#v", e.expectationOrigins.origin, *e.params)
+		}
+	}
+
+	afterUploadPrivateFileStreamCounter := mm_atomic.LoadUint64(&m.afterUploadPrivateFileStreamCounter)
+	// if default expectation was set then invocations count should be greater than zero
+	if m.UploadPrivateFileStreamMock.defaultExpectation != nil && afterUploadPrivateFileStreamCounter < 1 {
+		if m.UploadPrivateFileStreamMock.defaultExpectation.params == nil {
+			m.t.Errorf("Expected call to ClientMock.UploadPrivateFileStream at\n%s", m.UploadPrivateFileStreamMock.defaultExpectation.returnOrigin)
+		} else {
+			m.t.Errorf("Expected call to ClientMock.UploadPrivateFileStream at\n%s with params: %#v", m.UploadPrivateFileStreamMock.defaultExpectation.expectationOrigins.origin, *m.UploadPrivateFileStreamMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcUploadPrivateFileStream != nil && afterUploadPrivateFileStreamCounter < 1 {
+		m.t.Errorf("Expected call to ClientMock.UploadPrivateFileStream at\n%s", m.funcUploadPrivateFileStreamOrigin)
+	}
+
+	if !m.UploadPrivateFileStreamMock.invocationsDone() && afterUploadPrivateFileStreamCounter > 0 {
+		m.t.Errorf("Expected %d calls to ClientMock.UploadPrivateFileStream at\n%s but found %d calls",
+			mm_atomic.LoadUint64(&m.UploadPrivateFileStreamMock.expectedInvocations), m.UploadPrivateFileStreamMock.expectedInvocationsOrigin, afterUploadPrivateFileStreamCounter)
+	}
+}
+
 type mClientMockWithLogger struct {
 	optional           bool
 	mock               *ClientMock
@@ -2771,14 +3894,20 @@ func (m *ClientMock) MinimockFinish() {
 
 			m.MinimockGetFileInspect()
 
+			m.MinimockGetFileStreamInspect()
+
 			m.MinimockGetFilesByPathsInspect()
 
 			m.MinimockUploadFileInspect()
 
 			m.MinimockUploadFileBytesInspect()
 
+			m.MinimockUploadFileStreamInspect()
+
 			m.MinimockUploadPrivateFileBytesInspect()
 
+			m.MinimockUploadPrivateFileStreamInspect()
+
 			m.MinimockWithLoggerInspect()
 		}
 	})
@@ -2806,9 +3935,12 @@ func (m *ClientMock) minimockDone() bool {
 		m.MinimockClientDone() &&
 		m.MinimockDeleteFileDone() &&
 		m.MinimockGetFileDone() &&
+		m.MinimockGetFileStreamDone() &&
 		m.MinimockGetFilesByPathsDone() &&
 		m.MinimockUploadFileDone() &&
 		m.MinimockUploadFileBytesDone() &&
+		m.MinimockUploadFileStreamDone() &&
 		m.MinimockUploadPrivateFileBytesDone() &&
+		m.MinimockUploadPrivateFileStreamDone() &&
 		m.MinimockWithLoggerDone()
 }
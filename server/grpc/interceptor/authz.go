@@ -0,0 +1,366 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+
+	"github.com/instill-ai/x/constant"
+	"github.com/instill-ai/x/resource"
+
+	errorsx "github.com/instill-ai/x/errors"
+)
+
+// PermissionChecker is the part of acl.Client used by the authorization
+// interceptors.
+type PermissionChecker interface {
+	CheckRequesterPermission(ctx context.Context) error
+	CheckPermissionWithShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, shareToken string) (bool, error)
+}
+
+// AuthzDefaultPolicy decides what happens to methods without a rule.
+type AuthzDefaultPolicy int
+
+const (
+	// AuthzDeny rejects methods without a rule with PermissionDenied.
+	AuthzDeny AuthzDefaultPolicy = iota
+	// AuthzAllow lets methods without a rule through.
+	AuthzAllow
+)
+
+// AuthzRule describes the permission a method requires.
+type AuthzRule struct {
+	// Public lets every caller through without any check.
+	Public bool
+	// ObjectType is the FGA type of the object the method acts on. When
+	// empty, the caller only needs to be authenticated, and the requester
+	// delegation is checked.
+	ObjectType string
+	// UID extracts the object UID from the request message.
+	UID func(req any) (uuid.UUID, error)
+	// Relation is the relation the caller must hold on the object.
+	Relation string
+}
+
+// AuthzConfig configures an AuthzRegistry.
+type AuthzConfig struct {
+	// DefaultPolicy applies to methods without a rule. It denies by
+	// default.
+	DefaultPolicy AuthzDefaultPolicy
+	// ShareTokenHeader is the header carrying a share-link token. Share
+	// links are ignored when it is empty.
+	ShareTokenHeader string
+}
+
+// AuthzRegistry maps full gRPC method names, e.g.
+// "/pipeline.v1beta.PipelinePublicService/GetPipeline", to the
+// permission they require. Rules are registered at startup; the
+// registry must not be modified once the server runs.
+type AuthzRegistry struct {
+	cfg   AuthzConfig
+	rules map[string]AuthzRule
+}
+
+// NewAuthzRegistry returns an empty registry.
+func NewAuthzRegistry(cfg AuthzConfig) *AuthzRegistry {
+	return &AuthzRegistry{cfg: cfg, rules: map[string]AuthzRule{}}
+}
+
+// Register sets the rule of a method.
+func (r *AuthzRegistry) Register(fullMethod string, rule AuthzRule) *AuthzRegistry {
+	r.rules[fullMethod] = rule
+	return r
+}
+
+// RegisterFromOptions registers the rules declared as method options of
+// the services. ext is the extension of google.protobuf.MethodOptions
+// holding the rule, a message with the fields:
+//
+//	string object_type = 1; // FGA object type
+//	string uid_field   = 2; // request field holding the object UID, see UIDFromField
+//	string relation    = 3; // required relation
+//	bool   public      = 4; // no check at all
+//
+// Methods without the option are left untouched.
+func (r *AuthzRegistry) RegisterFromOptions(ext protoreflect.ExtensionType, services ...protoreflect.ServiceDescriptor) error {
+	for _, svc := range services {
+		methods := svc.Methods()
+		for i := range methods.Len() {
+			method := methods.Get(i)
+			opts := method.Options()
+			if opts == nil || !proto.HasExtension(opts, ext) {
+				continue
+			}
+			msg, ok := proto.GetExtension(opts, ext).(proto.Message)
+			if !ok {
+				return fmt.Errorf("authz option of %s is not a message", method.FullName())
+			}
+
+			rule, uidField, err := ruleFromOption(msg.ProtoReflect())
+			if err != nil {
+				return fmt.Errorf("authz option of %s: %w", method.FullName(), err)
+			}
+			if uidField != "" {
+				if err := checkFieldPath(method.Input(), uidField); err != nil {
+					return fmt.Errorf("authz option of %s: %w", method.FullName(), err)
+				}
+			}
+			r.Register(fmt.Sprintf("/%s/%s", svc.FullName(), method.Name()), rule)
+		}
+	}
+	return nil
+}
+
+func ruleFromOption(m protoreflect.Message) (rule AuthzRule, uidField string, err error) {
+	str := func(name protoreflect.Name) string {
+		fd := m.Descriptor().Fields().ByName(name)
+		if fd == nil || fd.Kind() != protoreflect.StringKind {
+			return ""
+		}
+		return m.Get(fd).String()
+	}
+	if fd := m.Descriptor().Fields().ByName("public"); fd != nil && fd.Kind() == protoreflect.BoolKind {
+		rule.Public = m.Get(fd).Bool()
+	}
+	if rule.Public {
+		return rule, "", nil
+	}
+
+	rule.ObjectType = str("object_type")
+	rule.Relation = str("relation")
+	uidField = str("uid_field")
+	if rule.ObjectType != "" {
+		if rule.Relation == "" || uidField == "" {
+			return rule, "", fmt.Errorf("object type %s needs a relation and a uid_field", rule.ObjectType)
+		}
+		rule.UID = UIDFromField(uidField)
+	}
+	return rule, uidField, nil
+}
+
+// checkFieldPath validates a dotted field path against a message
+// descriptor.
+func checkFieldPath(md protoreflect.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("%s has no field %s", md.FullName(), name)
+		}
+		if i == len(names)-1 {
+			if fd.Kind() != protoreflect.StringKind {
+				return fmt.Errorf("field %s of %s is not a string", name, md.FullName())
+			}
+			return nil
+		}
+		if fd.Message() == nil {
+			return fmt.Errorf("field %s of %s is not a message", name, md.FullName())
+		}
+		md = fd.Message()
+	}
+	return nil
+}
+
+// UIDFromField returns a UID extractor reading the string field at the
+// dotted path (e.g. "pipeline.uid") of the request. The value may be a
+// UUID or a permalink ending with one, e.g. "pipelines/<uid>".
+func UIDFromField(path string) func(req any) (uuid.UUID, error) {
+	names := strings.Split(path, ".")
+	return func(req any) (uuid.UUID, error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return uuid.Nil, fmt.Errorf("%w: request is not a proto message", errorsx.ErrInvalidArgument)
+		}
+		m := msg.ProtoReflect()
+		for i, name := range names {
+			fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+			if fd == nil {
+				return uuid.Nil, fmt.Errorf("%w: request has no field %s", errorsx.ErrInvalidArgument, path)
+			}
+			if i < len(names)-1 {
+				if fd.Message() == nil {
+					return uuid.Nil, fmt.Errorf("%w: request has no field %s", errorsx.ErrInvalidArgument, path)
+				}
+				m = m.Get(fd).Message()
+				continue
+			}
+
+			value := m.Get(fd).String()
+			uid, err := uuid.FromString(value[strings.LastIndex(value, "/")+1:])
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("%w: %s is not a valid UID", errorsx.ErrInvalidArgument, path)
+			}
+			return uid, nil
+		}
+		return uuid.Nil, fmt.Errorf("%w: empty field path", errorsx.ErrInvalidArgument)
+	}
+}
+
+// authorize enforces the rule of the method. req is nil for streaming
+// methods whose rule doesn't need the request.
+func (r *AuthzRegistry) authorize(ctx context.Context, checker PermissionChecker, fullMethod string, req any) error {
+	rule, ok := r.rules[fullMethod]
+	if !ok {
+		if r.cfg.DefaultPolicy == AuthzAllow {
+			return nil
+		}
+		return fmt.Errorf("%w: no authorization rule for %s", errorsx.ErrPermissionDenied, fullMethod)
+	}
+	if rule.Public {
+		return nil
+	}
+
+	// Namespace delegation only applies to authenticated users and
+	// machine subjects: visitors and share-link holders have no namespace
+	// to switch to, even when a share-link request carries a user UID.
+	authType := resource.GetRequestSingleHeader(ctx, constant.HeaderAuthTypeKey)
+	if authType == authTypeUser || machineUIDHeaders[authType] != "" {
+		if err := checker.CheckRequesterPermission(ctx); err != nil {
+			return err
+		}
+	}
+	if rule.ObjectType == "" {
+		if !authenticated(ctx, authType) {
+			return fmt.Errorf("%w: %s needs an authenticated caller", errorsx.ErrUnauthenticated, fullMethod)
+		}
+		return nil
+	}
+
+	objectUID, err := rule.UID(req)
+	if err != nil {
+		return err
+	}
+
+	var shareToken string
+	if r.cfg.ShareTokenHeader != "" {
+		shareToken = resource.GetRequestSingleHeader(ctx, r.cfg.ShareTokenHeader)
+	}
+	granted, err := checker.CheckPermissionWithShareLink(ctx, rule.ObjectType, objectUID, rule.Relation, shareToken)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		return fmt.Errorf("%w: %s on %s", errorsx.ErrPermissionDenied, rule.Relation, rule.ObjectType)
+	}
+	return nil
+}
+
+// Instill-Auth-Type values of the subjects the interceptors tell apart,
+// as resolved by acl.Client.
+const (
+	authTypeUser    = "user"
+	authTypeVisitor = "visitor"
+)
+
+// machineUIDHeaders maps the Instill-Auth-Type of service accounts and
+// API tokens (acl.SubjectTypeServiceAccount and acl.SubjectTypeAPIToken)
+// to the header carrying their UID.
+var machineUIDHeaders = map[string]string{
+	"serviceaccount": constant.HeaderServiceAccountUIDKey,
+	"apitoken":       constant.HeaderAPITokenUIDKey,
+}
+
+// authenticated reports whether the request carries a subject acl.Client
+// can check permissions for: a user, a visitor or a machine subject.
+// Share-link holders without a user UID are not, since the token only
+// grants access to the object it was issued for.
+func authenticated(ctx context.Context, authType string) bool {
+	switch {
+	case resource.GetRequestSingleHeader(ctx, constant.HeaderUserUIDKey) != "":
+		return true
+	case authType == authTypeVisitor:
+		return resource.GetRequestSingleHeader(ctx, constant.HeaderVisitorUIDKey) != ""
+	case machineUIDHeaders[authType] != "":
+		return resource.GetRequestSingleHeader(ctx, machineUIDHeaders[authType]) != ""
+	}
+	return false
+}
+
+// needsRequest reports whether the rule of the method reads the request.
+func (r *AuthzRegistry) needsRequest(fullMethod string) bool {
+	rule, ok := r.rules[fullMethod]
+	return ok && !rule.Public && rule.ObjectType != ""
+}
+
+// UnaryAuthzInterceptor enforces the rules of the registry before the
+// handler runs. Denials are returned as PermissionDenied or
+// Unauthenticated through errorsx.ConvertToGRPCError.
+func UnaryAuthzInterceptor(checker PermissionChecker, registry *AuthzRegistry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := registry.authorize(ctx, checker, info.FullMethod, req); err != nil {
+			return nil, errorsx.ConvertToGRPCError(err)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthzInterceptor is the streaming counterpart of
+// UnaryAuthzInterceptor. Rules that read the request are enforced on
+// the first message received, before it reaches the handler; the
+// others before the handler runs. Until the first message is
+// authorized, the handler can't send messages, and a handler returning
+// without having received one gets PermissionDenied.
+func StreamAuthzInterceptor(checker PermissionChecker, registry *AuthzRegistry) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !registry.needsRequest(info.FullMethod) {
+			if err := registry.authorize(stream.Context(), checker, info.FullMethod, nil); err != nil {
+				return errorsx.ConvertToGRPCError(err)
+			}
+			return handler(srv, stream)
+		}
+
+		authzStream := &authzServerStream{
+			WrappedServerStream: grpc_middleware.WrapServerStream(stream),
+			authorize: func(req any) error {
+				return registry.authorize(stream.Context(), checker, info.FullMethod, req)
+			},
+		}
+		if err := handler(srv, authzStream); err != nil {
+			return err
+		}
+		if !authzStream.authorized {
+			return errorsx.ConvertToGRPCError(errUnauthorizedStream)
+		}
+		return nil
+	}
+}
+
+// errUnauthorizedStream denies a stream whose handler didn't receive
+// the message its rule authorizes.
+var errUnauthorizedStream = fmt.Errorf("%w: the request was not authorized", errorsx.ErrPermissionDenied)
+
+// authzServerStream authorizes the first message received on a stream
+// and rejects the messages sent before it.
+type authzServerStream struct {
+	*grpc_middleware.WrappedServerStream
+	authorize  func(req any) error
+	authorized bool
+}
+
+func (s *authzServerStream) SendMsg(m any) error {
+	if !s.authorized {
+		return errorsx.ConvertToGRPCError(errUnauthorizedStream)
+	}
+	return s.WrappedServerStream.SendMsg(m)
+}
+
+func (s *authzServerStream) RecvMsg(m any) error {
+	if err := s.WrappedServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authorized {
+		return nil
+	}
+	if err := s.authorize(m); err != nil {
+		return errorsx.ConvertToGRPCError(err)
+	}
+	s.authorized = true
+	return nil
+}
@@ -0,0 +1,418 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frankban/quicktest"
+	"github.com/gofrs/uuid"
+	"github.com/gojuno/minimock/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/instill-ai/x/acl"
+	"github.com/instill-ai/x/constant"
+	"github.com/instill-ai/x/resource"
+
+	errorsx "github.com/instill-ai/x/errors"
+	mockserver "github.com/instill-ai/x/mock/server"
+)
+
+const (
+	testGetMethod  = "/test.v1.TestService/Get"
+	testListMethod = "/test.v1.TestService/List"
+)
+
+var _ PermissionChecker = acl.Client(nil)
+
+// fakeChecker records the permission checks and grants the relations
+// listed in granted.
+type fakeChecker struct {
+	granted      map[string]bool
+	requesterErr error
+	checks       []string
+}
+
+// CheckRequesterPermission mirrors acl.ACLClient: visitors skip the
+// check and other subjects than users and machines are rejected, before
+// requesterErr is returned.
+func (f *fakeChecker) CheckRequesterPermission(ctx context.Context) error {
+	switch resource.GetRequestSingleHeader(ctx, constant.HeaderAuthTypeKey) {
+	case "visitor":
+		return nil
+	case "user", "serviceaccount", "apitoken":
+		return f.requesterErr
+	}
+	return errorsx.ErrUnauthenticated
+}
+
+func (f *fakeChecker) CheckPermissionWithShareLink(ctx context.Context, objectType string, objectUID uuid.UUID, relation string, shareToken string) (bool, error) {
+	f.checks = append(f.checks, objectType+":"+objectUID.String()+"#"+relation+"|"+shareToken)
+	if shareToken == "" && len(metadata.ValueFromIncomingContext(ctx, constant.HeaderUserUIDKey)) == 0 {
+		return false, errorsx.ErrUnauthenticated
+	}
+	return f.granted[relation], nil
+}
+
+func authzCtx(headers map[string]string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.New(headers))
+}
+
+func TestUnaryAuthzInterceptor(t *testing.T) {
+	qt := quicktest.New(t)
+	objectUID := uuid.Must(uuid.NewV4())
+	userCtx := authzCtx(map[string]string{
+		constant.HeaderAuthTypeKey: "user",
+		constant.HeaderUserUIDKey:  uuid.Must(uuid.NewV4()).String(),
+	})
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		method      string
+		req         any
+		cfg         AuthzConfig
+		checker     *fakeChecker
+		wantCode    codes.Code
+		description string
+	}{
+		{
+			name:        "granted",
+			ctx:         userCtx,
+			method:      testGetMethod,
+			req:         wrapperspb.String("pipelines/" + objectUID.String()),
+			checker:     &fakeChecker{granted: map[string]bool{"reader": true}},
+			wantCode:    codes.OK,
+			description: "should call the handler when the relation is held",
+		},
+		{
+			name:        "denied",
+			ctx:         userCtx,
+			method:      testGetMethod,
+			req:         wrapperspb.String(objectUID.String()),
+			checker:     &fakeChecker{},
+			wantCode:    codes.PermissionDenied,
+			description: "should return PermissionDenied when the relation isn't held",
+		},
+		{
+			name:        "unauthenticated",
+			ctx:         authzCtx(nil),
+			method:      testGetMethod,
+			req:         wrapperspb.String(objectUID.String()),
+			checker:     &fakeChecker{},
+			wantCode:    codes.Unauthenticated,
+			description: "should return Unauthenticated when there is no subject",
+		},
+		{
+			name:        "share link",
+			ctx:         authzCtx(map[string]string{"Instill-Share-Token": "tok"}),
+			method:      testGetMethod,
+			req:         wrapperspb.String(objectUID.String()),
+			cfg:         AuthzConfig{ShareTokenHeader: "Instill-Share-Token"},
+			checker:     &fakeChecker{granted: map[string]bool{"reader": true}},
+			wantCode:    codes.OK,
+			description: "should pass the share-link token to the check",
+		},
+		{
+			name: "share link with a user UID",
+			ctx: authzCtx(map[string]string{
+				constant.HeaderAuthTypeKey: "capability",
+				constant.HeaderUserUIDKey:  uuid.Must(uuid.NewV4()).String(),
+				"Instill-Share-Token":      "tok",
+			}),
+			method:      testGetMethod,
+			req:         wrapperspb.String(objectUID.String()),
+			cfg:         AuthzConfig{ShareTokenHeader: "Instill-Share-Token"},
+			checker:     &fakeChecker{granted: map[string]bool{"reader": true}},
+			wantCode:    codes.OK,
+			description: "should not check the requester delegation of a share-link request",
+		},
+		{
+			name:        "requester delegation",
+			ctx:         userCtx,
+			method:      testGetMethod,
+			req:         wrapperspb.String(objectUID.String()),
+			checker:     &fakeChecker{granted: map[string]bool{"reader": true}, requesterErr: errorsx.ErrPermissionDenied},
+			wantCode:    codes.PermissionDenied,
+			description: "should reject a user impersonating a namespace they don't belong to",
+		},
+		{
+			name:        "invalid UID",
+			ctx:         userCtx,
+			method:      testGetMethod,
+			req:         wrapperspb.String("not-a-uid"),
+			checker:     &fakeChecker{},
+			wantCode:    codes.InvalidArgument,
+			description: "should reject a request without a valid object UID",
+		},
+		{
+			name:        "unmatched default deny",
+			ctx:         userCtx,
+			method:      "/test.v1.TestService/Other",
+			checker:     &fakeChecker{},
+			wantCode:    codes.PermissionDenied,
+			description: "should deny methods without a rule by default",
+		},
+		{
+			name:        "unmatched default allow",
+			ctx:         userCtx,
+			method:      "/test.v1.TestService/Other",
+			cfg:         AuthzConfig{DefaultPolicy: AuthzAllow},
+			checker:     &fakeChecker{},
+			wantCode:    codes.OK,
+			description: "should allow methods without a rule when configured",
+		},
+		{
+			name:        "no object type",
+			ctx:         userCtx,
+			method:      testListMethod,
+			checker:     &fakeChecker{},
+			wantCode:    codes.OK,
+			description: "should let an authenticated caller through a rule without object type",
+		},
+		{
+			name:        "no object type visitor",
+			ctx:         authzCtx(map[string]string{constant.HeaderAuthTypeKey: "visitor", constant.HeaderVisitorUIDKey: uuid.Must(uuid.NewV4()).String()}),
+			method:      testListMethod,
+			checker:     &fakeChecker{},
+			wantCode:    codes.OK,
+			description: "should let a visitor through a rule without object type",
+		},
+		{
+			name:        "no object type anonymous",
+			ctx:         authzCtx(nil),
+			method:      testListMethod,
+			checker:     &fakeChecker{},
+			wantCode:    codes.Unauthenticated,
+			description: "should reject an anonymous caller on a rule without object type",
+		},
+		{
+			name:        "no object type share link",
+			ctx:         authzCtx(map[string]string{constant.HeaderAuthTypeKey: "capability", "Instill-Share-Token": "tok"}),
+			method:      testListMethod,
+			cfg:         AuthzConfig{ShareTokenHeader: "Instill-Share-Token"},
+			checker:     &fakeChecker{},
+			wantCode:    codes.Unauthenticated,
+			description: "should reject a share-link holder on a rule without object type",
+		},
+		{
+			name:        "public",
+			ctx:         authzCtx(nil),
+			method:      "/test.v1.TestService/Liveness",
+			checker:     &fakeChecker{},
+			wantCode:    codes.OK,
+			description: "should not check public methods",
+		},
+	}
+
+	for _, tt := range tests {
+		qt.Run(tt.name, func(c *quicktest.C) {
+			registry := NewAuthzRegistry(tt.cfg).
+				Register(testGetMethod, AuthzRule{ObjectType: "pipeline", UID: UIDFromField("value"), Relation: "reader"}).
+				Register(testListMethod, AuthzRule{}).
+				Register("/test.v1.TestService/Liveness", AuthzRule{Public: true})
+
+			called := false
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				return "ok", nil
+			}
+
+			_, err := UnaryAuthzInterceptor(tt.checker, registry)(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			c.Check(status.Code(err), quicktest.Equals, tt.wantCode, quicktest.Commentf(tt.description))
+			c.Check(called, quicktest.Equals, tt.wantCode == codes.OK, quicktest.Commentf("handler should only run when authorized"))
+		})
+	}
+}
+
+func TestStreamAuthzInterceptor_ChecksFirstMessage(t *testing.T) {
+	qt := quicktest.New(t)
+
+	ctx := authzCtx(map[string]string{
+		constant.HeaderAuthTypeKey: "user",
+		constant.HeaderUserUIDKey:  uuid.Must(uuid.NewV4()).String(),
+	})
+	mc := minimock.NewController(t)
+	stream := mockserver.NewServerStreamMock(mc)
+	stream.ContextMock.Return(ctx)
+	stream.RecvMsgMock.Set(func(m any) error {
+		m.(*wrapperspb.StringValue).Value = uuid.Must(uuid.NewV4()).String()
+		return nil
+	})
+
+	checker := &fakeChecker{}
+	registry := NewAuthzRegistry(AuthzConfig{}).
+		Register(testGetMethod, AuthzRule{ObjectType: "pipeline", UID: UIDFromField("value"), Relation: "reader"})
+
+	err := StreamAuthzInterceptor(checker, registry)(nil, stream, &grpc.StreamServerInfo{FullMethod: testGetMethod}, func(srv any, s grpc.ServerStream) error {
+		return s.RecvMsg(&wrapperspb.StringValue{})
+	})
+
+	qt.Check(status.Code(err), quicktest.Equals, codes.PermissionDenied)
+	qt.Check(checker.checks, quicktest.HasLen, 1)
+}
+
+func TestStreamAuthzInterceptor_RejectsSendBeforeFirstMessage(t *testing.T) {
+	qt := quicktest.New(t)
+
+	ctx := authzCtx(map[string]string{
+		constant.HeaderAuthTypeKey: "user",
+		constant.HeaderUserUIDKey:  uuid.Must(uuid.NewV4()).String(),
+	})
+	objectUID := uuid.Must(uuid.NewV4()).String()
+	newStream := func(mc *minimock.Controller) *mockserver.ServerStreamMock {
+		stream := mockserver.NewServerStreamMock(mc)
+		stream.ContextMock.Return(ctx)
+		stream.RecvMsgMock.Optional().Set(func(m any) error {
+			m.(*wrapperspb.StringValue).Value = objectUID
+			return nil
+		})
+		return stream
+	}
+
+	checker := &fakeChecker{granted: map[string]bool{"reader": true}}
+	registry := NewAuthzRegistry(AuthzConfig{}).
+		Register(testGetMethod, AuthzRule{ObjectType: "pipeline", UID: UIDFromField("value"), Relation: "reader"})
+	interceptor := StreamAuthzInterceptor(checker, registry)
+	info := &grpc.StreamServerInfo{FullMethod: testGetMethod}
+
+	qt.Run("send before receive", func(c *quicktest.C) {
+		// SendMsg isn't mocked: the message must not reach the stream.
+		var sendErr error
+		err := interceptor(nil, newStream(minimock.NewController(c)), info, func(srv any, s grpc.ServerStream) error {
+			sendErr = s.SendMsg(&wrapperspb.StringValue{Value: "leaked"})
+			return nil
+		})
+		c.Check(status.Code(sendErr), quicktest.Equals, codes.PermissionDenied)
+		c.Check(status.Code(err), quicktest.Equals, codes.PermissionDenied)
+	})
+
+	qt.Run("return before receive", func(c *quicktest.C) {
+		err := interceptor(nil, newStream(minimock.NewController(c)), info, func(srv any, s grpc.ServerStream) error {
+			return nil
+		})
+		c.Check(status.Code(err), quicktest.Equals, codes.PermissionDenied)
+	})
+
+	qt.Run("send after receive", func(c *quicktest.C) {
+		stream := newStream(minimock.NewController(c))
+		stream.SendMsgMock.Return(nil)
+		err := interceptor(nil, stream, info, func(srv any, s grpc.ServerStream) error {
+			if err := s.RecvMsg(&wrapperspb.StringValue{}); err != nil {
+				return err
+			}
+			return s.SendMsg(&wrapperspb.StringValue{Value: "ok"})
+		})
+		c.Check(err, quicktest.IsNil)
+	})
+}
+
+// authzOptionFiles builds a file declaring the authz method option and a
+// service using it on one of its two methods.
+func authzOptionFiles(c *quicktest.C, rule map[string]any) (protoreflect.ExtensionType, protoreflect.ServiceDescriptor) {
+	files := new(protoregistry.Files)
+	c.Assert(files.RegisterFile(descriptorpb.File_google_protobuf_descriptor_proto), quicktest.IsNil)
+	c.Assert(files.RegisterFile(wrapperspb.File_google_protobuf_wrappers_proto), quicktest.IsNil)
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: typ.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+	}
+	optionFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/v1/authz.proto"),
+		Package:    proto.String("test.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("AuthzRule"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("object_type", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("uid_field", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("relation", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("public", 4, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			},
+		}},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("authz"),
+			Number:   proto.Int32(50000),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			TypeName: proto.String(".test.v1.AuthzRule"),
+			Extendee: proto.String(".google.protobuf.MethodOptions"),
+		}},
+	}, files)
+	c.Assert(err, quicktest.IsNil)
+	c.Assert(files.RegisterFile(optionFile), quicktest.IsNil)
+
+	ext := dynamicpb.NewExtensionType(optionFile.Extensions().Get(0))
+	ruleMsg := dynamicpb.NewMessage(optionFile.Messages().Get(0))
+	for name, value := range rule {
+		ruleMsg.Set(ruleMsg.Descriptor().Fields().ByName(protoreflect.Name(name)), protoreflect.ValueOf(value))
+	}
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, ext, ruleMsg)
+
+	method := func(name string, opts *descriptorpb.MethodOptions) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(".google.protobuf.StringValue"),
+			OutputType: proto.String(".google.protobuf.StringValue"),
+			Options:    opts,
+		}
+	}
+	serviceFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/v1/service.proto"),
+		Package:    proto.String("test.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/wrappers.proto", "test/v1/authz.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("TestService"),
+			Method: []*descriptorpb.MethodDescriptorProto{method("Get", opts), method("Other", nil)},
+		}},
+	}, files)
+	c.Assert(err, quicktest.IsNil)
+
+	return ext, serviceFile.Services().Get(0)
+}
+
+func TestAuthzRegistry_RegisterFromOptions(t *testing.T) {
+	qt := quicktest.New(t)
+
+	qt.Run("registers annotated methods", func(c *quicktest.C) {
+		ext, svc := authzOptionFiles(c, map[string]any{"object_type": "pipeline", "uid_field": "value", "relation": "writer"})
+		registry := NewAuthzRegistry(AuthzConfig{})
+		c.Assert(registry.RegisterFromOptions(ext, svc), quicktest.IsNil)
+
+		c.Assert(registry.rules, quicktest.HasLen, 1)
+		rule := registry.rules[testGetMethod]
+		c.Check(rule.ObjectType, quicktest.Equals, "pipeline")
+		c.Check(rule.Relation, quicktest.Equals, "writer")
+
+		objectUID := uuid.Must(uuid.NewV4())
+		uid, err := rule.UID(wrapperspb.String(objectUID.String()))
+		c.Check(err, quicktest.IsNil)
+		c.Check(uid, quicktest.Equals, objectUID)
+	})
+
+	qt.Run("rejects unknown request fields", func(c *quicktest.C) {
+		ext, svc := authzOptionFiles(c, map[string]any{"object_type": "pipeline", "uid_field": "pipeline_uid", "relation": "writer"})
+		err := NewAuthzRegistry(AuthzConfig{}).RegisterFromOptions(ext, svc)
+		c.Check(err, quicktest.ErrorMatches, ".*has no field pipeline_uid")
+	})
+
+	qt.Run("registers public methods", func(c *quicktest.C) {
+		ext, svc := authzOptionFiles(c, map[string]any{"public": true})
+		registry := NewAuthzRegistry(AuthzConfig{})
+		c.Assert(registry.RegisterFromOptions(ext, svc), quicktest.IsNil)
+		c.Check(registry.rules[testGetMethod].Public, quicktest.IsTrue)
+	})
+}
@@ -172,6 +172,8 @@ type Options struct {
 	MethodLogExcludePatterns   []string
 	MethodTraceExcludePatterns []string // New field
 	SetOTELServerHandler       bool
+	AuthzChecker               interceptor.PermissionChecker
+	AuthzRegistry              *interceptor.AuthzRegistry
 }
 
 // Option is a function that modifies Options
@@ -219,6 +221,15 @@ func WithMethodTraceExcludePatterns(patterns []string) Option {
 	}
 }
 
+// WithAuthz enforces the rules of the registry with the authorization
+// interceptors, after the metadata and recovery interceptors
+func WithAuthz(checker interceptor.PermissionChecker, registry *interceptor.AuthzRegistry) Option {
+	return func(o *Options) {
+		o.AuthzChecker = checker
+		o.AuthzRegistry = registry
+	}
+}
+
 // newOptions creates a new Options with default values and applies the given options
 func newOptions(options ...Option) *Options {
 	opts := &Options{
@@ -264,17 +275,24 @@ func NewServerOptionsAndCreds(options ...Option) ([]grpc.ServerOption, error) {
 		unaryInterceptorOpts = append(unaryInterceptorOpts, grpczap.WithMessageProducer(messageProducer))
 	}
 
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		grpczap.StreamServerInterceptor(logger, streamInterceptorOpts...),
+		interceptor.StreamAppendMetadataInterceptor,
+		grpcrecovery.StreamServerInterceptor(interceptor.RecoveryInterceptorOpt()),
+	}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		grpczap.UnaryServerInterceptor(logger, unaryInterceptorOpts...),
+		interceptor.UnaryAppendMetadataInterceptor,
+		grpcrecovery.UnaryServerInterceptor(interceptor.RecoveryInterceptorOpt()),
+	}
+	if opts.AuthzChecker != nil && opts.AuthzRegistry != nil {
+		streamInterceptors = append(streamInterceptors, interceptor.StreamAuthzInterceptor(opts.AuthzChecker, opts.AuthzRegistry))
+		unaryInterceptors = append(unaryInterceptors, interceptor.UnaryAuthzInterceptor(opts.AuthzChecker, opts.AuthzRegistry))
+	}
+
 	grpcServerOpts = append(grpcServerOpts, []grpc.ServerOption{
-		grpc.StreamInterceptor(grpcmiddleware.ChainStreamServer(
-			grpczap.StreamServerInterceptor(logger, streamInterceptorOpts...),
-			interceptor.StreamAppendMetadataInterceptor,
-			grpcrecovery.StreamServerInterceptor(interceptor.RecoveryInterceptorOpt()),
-		)),
-		grpc.UnaryInterceptor(grpcmiddleware.ChainUnaryServer(
-			grpczap.UnaryServerInterceptor(logger, unaryInterceptorOpts...),
-			interceptor.UnaryAppendMetadataInterceptor,
-			grpcrecovery.UnaryServerInterceptor(interceptor.RecoveryInterceptorOpt()),
-		)),
+		grpc.StreamInterceptor(grpcmiddleware.ChainStreamServer(streamInterceptors...)),
+		grpc.UnaryInterceptor(grpcmiddleware.ChainUnaryServer(unaryInterceptors...)),
 		grpc.MaxRecvMsgSize(client.MaxPayloadSize),
 		grpc.MaxSendMsgSize(client.MaxPayloadSize),
 	}...)
@@ -1,12 +1,15 @@
 package grpc
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/frankban/quicktest"
+	"github.com/gofrs/uuid"
 	"github.com/instill-ai/x/client"
+	"github.com/instill-ai/x/server/grpc/interceptor"
 	"google.golang.org/grpc/stats"
 )
 
@@ -312,6 +315,26 @@ func TestNewGRPCOptionsAndCreds_WithCustomOptions(t *testing.T) {
 	qt.Check(len(serverOpts) > 0, quicktest.IsTrue)
 }
 
+type allowAllChecker struct{}
+
+func (allowAllChecker) CheckRequesterPermission(context.Context) error { return nil }
+
+func (allowAllChecker) CheckPermissionWithShareLink(context.Context, string, uuid.UUID, string, string) (bool, error) {
+	return true, nil
+}
+
+func TestNewGRPCOptionsAndCreds_WithAuthz(t *testing.T) {
+	qt := quicktest.New(t)
+	registry := interceptor.NewAuthzRegistry(interceptor.AuthzConfig{DefaultPolicy: interceptor.AuthzAllow})
+
+	opts := newOptions(WithAuthz(allowAllChecker{}, registry))
+	qt.Check(opts.AuthzRegistry, quicktest.Equals, registry)
+
+	serverOpts, err := NewServerOptionsAndCreds(WithAuthz(allowAllChecker{}, registry))
+	qt.Check(err, quicktest.IsNil)
+	qt.Check(len(serverOpts) > 0, quicktest.IsTrue)
+}
+
 func TestNewGRPCOptionsAndCreds_WithMethodTraceExcludePatterns(t *testing.T) {
 	qt := quicktest.New(t)
 	serverOpts, err := NewServerOptionsAndCreds(